@@ -60,6 +60,16 @@ const (
 	serviceAccountNameFlag       = "service-account-name"
 	existingServiceAccountFlag   = "existing-service-account"
 	namespacePSAEnforceLevelFlag = "namespace-psa-enforce-level"
+	namespaceScopedFlag          = "namespace-scoped"
+	imageClientFlag              = "image-client"
+	archiveFormatFlag            = "archive-format"
+	allowHostExecFlag            = "allow-host-exec"
+	platformFlag                 = "platform"
+	strictPlatformsFlag          = "strict-platforms"
+	preflightImagePullFlag       = "preflight-image-pull"
+	pinDigestsFlag               = "pin-digests"
+	inventoryFormatFlag          = "format"
+	requireSignedPluginsFlag     = "require-signed-plugins"
 )
 
 // AddNamespaceFlag initialises a namespace flag.
@@ -175,6 +185,31 @@ func AddAggregatorPermissionsFlag(mode *string, flags *pflag.FlagSet) {
 	)
 }
 
+// AddNamespaceScopedFlag adds a boolean flag to run entirely within an existing namespace.
+func AddNamespaceScopedFlag(flag *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(
+		flag, namespaceScopedFlag, false,
+		"If true, run within an existing namespace without any cluster-scoped permissions. Implies --aggregator-permissions=namespaceAdmin. DaemonSet plugins are not supported.",
+	)
+}
+
+// AddPinDigestsFlag adds a boolean flag to pin images to their digests.
+func AddPinDigestsFlag(flag *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(
+		flag, pinDigestsFlag, false,
+		"If true, resolve the images of the run to their current digests and reference them by digest. The digests, including those of the e2e test images, are recorded in the config.",
+	)
+}
+
+// AddRequireSignedPluginsFlag adds a boolean flag which refuses plugins from URLs unless they are
+// signed by a trusted key.
+func AddRequireSignedPluginsFlag(flag *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(
+		flag, requireSignedPluginsFlag, false,
+		"If true, refuse plugins loaded from a URL or plugin repository unless they have a detached signature from a key in the trusted-keys directory of the Sonobuoy directory.",
+	)
+}
+
 // AddExistingServiceAccountFlag adds a boolean flag which disables service account creation.
 func AddExistingServiceAccountFlag(flag *bool, flags *pflag.FlagSet) {
 	flags.BoolVar(
@@ -315,11 +350,20 @@ func AddRBACModeFlags(mode *RBACMode, flags *pflag.FlagSet, defaultMode RBACMode
 func AddSkipPreflightFlag(flag *[]string, flags *pflag.FlagSet) {
 	flags.StringSliceVar(
 		flag, "skip-preflight", []string{},
-		"Skips the specified preflight checks. Valid values are [dnscheck, versioncheck, existingnamespace] or true to skip all of the checks.",
+		"Skips the specified preflight checks. Valid values are [dnscheck, versioncheck, existingnamespace, rbaccheck, psacheck, quotacheck, nodecheck, imagepullcheck] or true to skip all of the checks.",
 	)
 	flags.Lookup("skip-preflight").NoOptDefVal = "true"
 }
 
+// AddPreflightImagePullFlag adds the flag enabling the preflight check that the nodes can pull the images of the run.
+func AddPreflightImagePullFlag(mode *ImagePullCheckMode, flags *pflag.FlagSet) {
+	*mode = ImagePullCheckOff
+	flags.Var(
+		mode, preflightImagePullFlag,
+		"Check that the nodes can pull the images of the run before starting it by running a pod on each node pool. Valid modes are off, manifest (the images in the manifest) or all (the images in the manifest and those pulled by the e2e tests, which are listed by running the conformance image with Docker).",
+	)
+}
+
 // AddDeleteAllFlag adds a boolean flag for deleting everything (including E2E tests).
 func AddDeleteAllFlag(flag *bool, flags *pflag.FlagSet) {
 	flags.BoolVar(
@@ -404,7 +448,7 @@ func AddSSHKeyPathFlag(path *string, pluginTransforms *map[string][]func(*manife
 // AddPluginSetFlag adds the flag for gen/run which keeps track of which plugins
 // to run and loads them from local files if necessary.
 func AddPluginSetFlag(p *pluginList, flags *pflag.FlagSet) {
-	flags.VarP(p, "plugin", "p", "Which plugins to run. Can either point to a URL, local file/directory, or be one of the known plugins (e2e or systemd-logs). Can be specified multiple times to run multiple plugins.")
+	flags.VarP(p, "plugin", "p", "Which plugins to run. Can either point to a URL, local file/directory, or be one of the known plugins (e2e, systemd-logs or query). Can be specified multiple times to run multiple plugins.")
 }
 
 // AddPluginEnvFlag adds the flag for gen/run which keeps track of which plugins
@@ -451,6 +495,55 @@ func AddDryRunFlag(flag *bool, flags *pflag.FlagSet) {
 	)
 }
 
+// AddImageClientFlag adds the flag choosing how image operations are performed.
+func AddImageClientFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(
+		str, imageClientFlag, imageClientDocker,
+		fmt.Sprintf("The client used for image operations. Valid values are %q (requires a local docker daemon) and %q (talks to registries directly).", imageClientDocker, imageClientRegistry),
+	)
+}
+
+// AddAllowHostExecFlag adds the flag allowing the registry image client to run the entrypoint of
+// images (e.g. to list the e2e test images) directly on this machine.
+func AddAllowHostExecFlag(flag *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(
+		flag, allowHostExecFlag, false,
+		fmt.Sprintf("If true, the %q image client runs the entrypoint of images (e.g. to list the e2e test images) directly on this machine, without a container runtime to isolate it. Only supported on Linux.", imageClientRegistry),
+	)
+}
+
+// AddStrictPlatformsFlag adds the flag which fails a push if an image isn't available for the platforms of the nodes.
+func AddStrictPlatformsFlag(flag *bool, flags *pflag.FlagSet) {
+	flags.BoolVar(
+		flag, strictPlatformsFlag, false,
+		"If true, fail if a pushed image isn't available for every platform of the cluster's nodes rather than only warning about it.",
+	)
+}
+
+// AddArchiveFormatFlag adds the flag choosing the format of downloaded images. It only applies to the registry image client.
+func AddArchiveFormatFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(
+		str, archiveFormatFlag, "",
+		fmt.Sprintf("The format of the tarball written by the registry image client. Valid values are %q (the default) and %q. Requires the registry image client.", image.ArchiveFormatDocker, image.ArchiveFormatOCI),
+	)
+}
+
+// AddInventoryFormatFlag adds the flag choosing the format of the image inventory.
+func AddInventoryFormatFlag(str *string, flags *pflag.FlagSet) {
+	flags.StringVar(
+		str, inventoryFormatFlag, "",
+		fmt.Sprintf("If set, print an inventory of the images (digest, size, creation date, platform and attested SBOM) instead of only checking they are available. Valid values are %q and %q. Requires --%v=%v.", inventoryFormatJSON, inventoryFormatCSV, imageClientFlag, imageClientRegistry),
+	)
+}
+
+// AddPlatformFlag adds the flag limiting the platforms of multi-platform images which are pushed.
+func AddPlatformFlag(p *[]string, flags *pflag.FlagSet) {
+	flags.StringSliceVar(
+		p, platformFlag, nil,
+		"Only push these platforms (in the form os/arch[/variant]) of multi-platform images. By default every platform is pushed. Requires the registry image client.",
+	)
+}
+
 // AddNodeSelectorFlag adds the flag for gen/run which keeps track of node selectors
 // to add to the aggregator. Allows running of the aggregator on Windows nodes.
 func AddNodeSelectorsFlag(p *NodeSelectors, flags *pflag.FlagSet) {
@@ -520,6 +613,26 @@ func regexpValidation(s string) error {
 	return nil
 }
 
+// ImagePullCheckMode determines which images the preflight image pull check pulls, if any.
+type ImagePullCheckMode string
+
+const (
+	ImagePullCheckOff      ImagePullCheckMode = "off"
+	ImagePullCheckManifest ImagePullCheckMode = "manifest"
+	ImagePullCheckAll      ImagePullCheckMode = "all"
+)
+
+func (m *ImagePullCheckMode) String() string { return string(*m) }
+func (m *ImagePullCheckMode) Type() string   { return "string" }
+func (m *ImagePullCheckMode) Set(str string) error {
+	switch mode := ImagePullCheckMode(strings.ToLower(str)); mode {
+	case ImagePullCheckOff, ImagePullCheckManifest, ImagePullCheckAll:
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("unknown image pull check mode %v", str)
+}
+
 // Mode represents the sonobuoy configuration for a given mode.
 type Mode struct {
 	name string
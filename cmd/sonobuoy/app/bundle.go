@@ -0,0 +1,530 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vmware-tanzu/sonobuoy/pkg/buildinfo"
+	"github.com/vmware-tanzu/sonobuoy/pkg/client"
+	"github.com/vmware-tanzu/sonobuoy/pkg/errlog"
+	"github.com/vmware-tanzu/sonobuoy/pkg/image"
+	"github.com/vmware-tanzu/sonobuoy/pkg/tarball"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	bundleManifestFile   = "manifest.yaml"
+	bundlePluginsDir     = "plugins"
+	bundleImagesDir      = "images"
+	bundleTestListFile   = "e2e/tests.txt"
+	bundleRepoConfigFile = "e2e/repo-config.yaml"
+	bundleLockFile       = "bundle.lock"
+
+	// k8sVersionPlaceholder is replaced with the version of the cluster by the aggregator.
+	k8sVersionPlaceholder = "$SONOBUOY_K8S_VERSION"
+)
+
+// imageLineRE matches the lines of a YAML document which set an image, including those of plugin
+// definitions embedded in the manifest.
+var imageLineRE = regexp.MustCompile(`(?m)^([ \t]*(?:-[ \t]+)?image:[ \t]*)["']?([^"'\s]+)["']?([ \t]*)$`)
+
+// bundleLock records the contents of a bundle so that it can be verified when it is loaded.
+type bundleLock struct {
+	SonobuoyVersion   string `json:"sonobuoyVersion"`
+	KubernetesVersion string `json:"kubernetesVersion"`
+
+	// Images are the images saved in the OCI image layout with their digests.
+	Images []bundleImage `json:"images"`
+
+	// Files are the sha256 sums of the other files in the bundle.
+	Files map[string]string `json:"files"`
+}
+
+type bundleImage struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+}
+
+type bundleCreateFlags struct {
+	genflags      genFlags
+	output        string
+	allowHostExec bool
+}
+
+type bundleLoadFlags struct {
+	registry string
+	dir      string
+}
+
+// NewCmdBundle returns the command to create and load bundles for air-gapped runs.
+func NewCmdBundle() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Package everything a run needs so that it can be moved into an air-gapped environment",
+	}
+	cmd.AddCommand(bundleCreateCmd(), bundleLoadCmd())
+	return cmd
+}
+
+func bundleCreateCmd() *cobra.Command {
+	var flags bundleCreateFlags
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Creates a bundle with the images, installed plugins and generated manifest of a run",
+		Long: "Creates a bundle with the generated manifest, the installed plugins, the images they use (as an OCI " +
+			"image layout), the e2e test list and image registry config, and a lock file recording their digests. " +
+			"The manifest is generated with the same flags as `sonobuoy gen`. Images are fetched from the registries " +
+			"directly so docker is only required to list the e2e test images, unless --allow-host-exec is set.",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runBundleCreate(&flags); err != nil {
+				errlog.LogError(errors.Wrap(err, "could not create bundle"))
+				os.Exit(1)
+			}
+		},
+		Args: cobra.ExactArgs(0),
+	}
+	cmd.Flags().AddFlagSet(GenFlagSet(&flags.genflags, EnabledRBACMode))
+	cmd.Flags().StringVarP(
+		&flags.output, "output", "o", "sonobuoy-bundle.tar.gz",
+		"Path to write the bundle to.",
+	)
+	AddAllowHostExecFlag(&flags.allowHostExec, cmd.Flags())
+	return cmd
+}
+
+func bundleLoadCmd() *cobra.Command {
+	var flags bundleLoadFlags
+	cmd := &cobra.Command{
+		Use:   "load bundle.tar.gz",
+		Short: "Pushes the images of a bundle to a registry and rewrites the bundle to use them",
+		Long: "Extracts the bundle, verifies it against its lock file and pushes its images to the given registry. " +
+			"The images referenced by the manifest and plugins are rewritten to the registry, as is the e2e image " +
+			"registry config.",
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := flags.dir
+			if len(dir) == 0 {
+				dir = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(args[0]), ".gz"), ".tar")
+			}
+			c, err := image.NewRegistryClient("", nil)
+			if err != nil {
+				errlog.LogError(err)
+				os.Exit(1)
+			}
+			if err := loadBundle(args[0], dir, flags.registry, c.(image.RegistryClient)); err != nil {
+				errlog.LogError(errors.Wrapf(err, "could not load bundle %v", args[0]))
+				os.Exit(1)
+			}
+			fmt.Printf("Bundle loaded into %v. Run it with:\n  sonobuoy run -f %v\n", dir, filepath.Join(dir, bundleManifestFile))
+			fmt.Printf("The e2e test images were pushed to %v; use --e2e-repo-config %v when generating manifests which run the e2e plugin.\n", flags.registry, filepath.Join(dir, bundleRepoConfigFile))
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().StringVar(
+		&flags.registry, "registry", "",
+		"The registry to push the images to.",
+	)
+	cmd.Flags().StringVar(
+		&flags.dir, "dir", "",
+		"Directory to extract the bundle to. Defaults to the name of the bundle without its extension.",
+	)
+	if err := cmd.MarkFlagRequired("registry"); err != nil {
+		logrus.Fatal(err)
+	}
+	return cmd
+}
+
+func runBundleCreate(flags *bundleCreateFlags) error {
+	var manifest []byte
+	var k8sVersion string
+	if len(flags.genflags.genFile) > 0 {
+		b, err := os.ReadFile(flags.genflags.genFile)
+		if err != nil {
+			return err
+		}
+		manifest = b
+		if k8sVersion, err = getClusterVersion(flags.genflags.k8sVersion, flags.genflags.kubecfg); err != nil {
+			return err
+		}
+	} else {
+		cfg, err := flags.genflags.Config()
+		if err != nil {
+			return err
+		}
+		sbc := &client.SonobuoyClient{}
+		if manifest, err = sbc.GenerateManifest(cfg); err != nil {
+			return errors.Wrap(err, "error attempting to generate sonobuoy manifest")
+		}
+		k8sVersion = cfg.KubeVersion
+	}
+
+	c, err := image.NewRegistryClient("", nil)
+	if err != nil {
+		return err
+	}
+	rc := c.(image.RegistryClient)
+
+	// Listing the e2e test images runs the conformance image, which requires docker unless the
+	// user allows the registry client to run it on this machine.
+	runner := image.NewDockerClient()
+	if flags.allowHostExec {
+		rc.AllowHostExecution = true
+		runner = rc
+	}
+	if err := createBundle(flags.output, manifest, getPluginCacheLocation(), k8sVersion, flags.genflags.pluginEnvs, rc, runner); err != nil {
+		return err
+	}
+	fmt.Printf("Bundle written to %v\n", flags.output)
+	return nil
+}
+
+// createBundle writes the manifest, the installed plugins in pluginDir and all of the images they
+// use to a gzipped tarball at output. The e2e test images are listed by running the conformance
+// image with the runner.
+func createBundle(output string, manifest []byte, pluginDir, k8sVersion string, pluginEnvs PluginEnvVars, c image.RegistryClient, runner image.Client) error {
+	dir, err := os.MkdirTemp("", "sonobuoy-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writeBundleFile(dir, bundleManifestFile, manifest); err != nil {
+		return err
+	}
+	images := imagesFromYAML(manifest)
+
+	if len(pluginDir) > 0 {
+		// Errors are logged; install problems shouldn't prevent bundling the remaining plugins.
+		plugins, _ := loadPlugins(pluginDir)
+		for file := range plugins {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(pluginDir, file)
+			if err != nil {
+				return err
+			}
+			if err := writeBundleFile(dir, path.Join(bundlePluginsDir, filepath.ToSlash(rel)), b); err != nil {
+				return err
+			}
+			images = append(images, imagesFromYAML(b)...)
+		}
+	}
+
+	images = resolveImages(images, k8sVersion)
+	for _, conformanceImage := range conformanceImages(images) {
+		logrus.Infof("Collecting e2e test images from %v", conformanceImage)
+		e2eImages, err := listE2EImages(conformanceImage, pluginEnvs[e2ePlugin], runner)
+		if err != nil {
+			return err
+		}
+		images = append(images, e2eImages...)
+	}
+	if len(conformanceImages(images)) > 0 {
+		if err := writeE2EFiles(dir, k8sVersion); err != nil {
+			return err
+		}
+	}
+
+	images = uniqueImages(images)
+	logrus.Infof("Saving %v images", len(images))
+	digests, err := c.WriteLayout(filepath.Join(dir, bundleImagesDir), images)
+	if err != nil {
+		return err
+	}
+
+	lock := bundleLock{
+		SonobuoyVersion:   buildinfo.Version,
+		KubernetesVersion: k8sVersion,
+	}
+	for _, img := range images {
+		lock.Images = append(lock.Images, bundleImage{Image: img, Digest: digests[img].String()})
+	}
+	if lock.Files, err = hashBundleFiles(dir); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := writeBundleFile(dir, bundleLockFile, b); err != nil {
+		return err
+	}
+
+	return tarball.DirToTarball(dir, output, true)
+}
+
+// writeE2EFiles saves the e2e test list and default image registry config for the version. The
+// test list is only available for versions with an embedded list.
+func writeE2EFiles(dir, k8sVersion string) error {
+	tests, err := getTests(e2eInputOffline, "", k8sVersion)
+	if err != nil {
+		logrus.Warnf("Unable to find the e2e test list for %v, it won't be included in the bundle: %v", k8sVersion, err)
+	} else if err := writeBundleFile(dir, bundleTestListFile, []byte(strings.Join(tests, "\n")+"\n")); err != nil {
+		return err
+	}
+
+	registries, err := image.GetDefaultImageRegistries(k8sVersion)
+	if err != nil {
+		return errors.Wrap(err, "couldn't get image registries for version")
+	}
+	b, err := yaml.Marshal(registries)
+	if err != nil {
+		return errors.Wrap(err, "couldn't marshal registry information")
+	}
+	return writeBundleFile(dir, bundleRepoConfigFile, b)
+}
+
+// loadBundle extracts the bundle into dir, pushes its images to the registry and rewrites the
+// manifest, plugins and e2e registry config to use them.
+func loadBundle(bundle, dir, registry string, c image.RegistryClient) error {
+	f, err := os.Open(bundle)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := tarball.DecodeTarball(f, dir); err != nil {
+		return errors.Wrap(err, "couldn't extract bundle")
+	}
+
+	lock, err := verifyBundle(dir)
+	if err != nil {
+		return err
+	}
+
+	imagePairs := []image.TagPair{}
+	translated := map[string]string{}
+	for _, img := range lock.Images {
+		dst := translateRegistry(img.Image, registry, nil)
+		imagePairs = append(imagePairs, image.TagPair{Src: img.Image, Dst: dst})
+		translated[img.Image] = dst
+	}
+	if errs := c.PushFromLayout(filepath.Join(dir, bundleImagesDir), imagePairs, numDockerRetries); len(errs) > 0 {
+		for _, err := range errs {
+			errlog.LogError(err)
+		}
+		return errors.Errorf("failed to push %v images", len(errs))
+	}
+
+	for file := range lock.Files {
+		if file != bundleManifestFile && !strings.HasPrefix(file, bundlePluginsDir+"/") {
+			continue
+		}
+		p := filepath.Join(dir, filepath.FromSlash(file))
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		// Images with the version placeholder were bundled for the version in the lock file; keep
+		// the placeholder so the aggregator still sets the version.
+		for _, img := range imagesFromYAML(b) {
+			if _, ok := translated[resolveK8sVersion(img, lock.KubernetesVersion)]; ok && strings.Contains(img, k8sVersionPlaceholder) {
+				translated[img] = translateRegistry(img, registry, nil)
+			}
+		}
+		if err := os.WriteFile(p, rewriteImages(b, translated), 0644); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := lock.Files[bundleRepoConfigFile]; ok {
+		return rewriteRepoConfig(filepath.Join(dir, filepath.FromSlash(bundleRepoConfigFile)), registry)
+	}
+	return nil
+}
+
+// verifyBundle reads the lock file of the extracted bundle and checks the files match it.
+func verifyBundle(dir string) (*bundleLock, error) {
+	b, err := os.ReadFile(filepath.Join(dir, bundleLockFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't read bundle lock file")
+	}
+	lock := &bundleLock{}
+	if err := json.Unmarshal(b, lock); err != nil {
+		return nil, errors.Wrap(err, "couldn't decode bundle lock file")
+	}
+	if lock.SonobuoyVersion != buildinfo.Version {
+		logrus.Warnf("Bundle was created by Sonobuoy %v but this is %v", lock.SonobuoyVersion, buildinfo.Version)
+	}
+
+	hashes, err := hashBundleFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	delete(hashes, bundleLockFile)
+	for file, sum := range lock.Files {
+		if hashes[file] != sum {
+			return nil, errors.Errorf("file %v does not match the bundle lock file", file)
+		}
+		delete(hashes, file)
+	}
+	for file := range hashes {
+		return nil, errors.Errorf("file %v is not in the bundle lock file", file)
+	}
+	return lock, nil
+}
+
+// hashBundleFiles returns the sha256 sum of each file in the bundle other than the images, which
+// are content addressed already.
+func hashBundleFiles(dir string) (map[string]string, error) {
+	hashes := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == bundleImagesDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		hashes[rel] = fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+		return nil
+	})
+	return hashes, errors.Wrap(err, "hashing bundle files")
+}
+
+// rewriteRepoConfig sets every registry in the e2e image registry config to the given registry,
+// matching where the images were pushed.
+func rewriteRepoConfig(file, registry string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	registries := map[string]string{}
+	if err := yaml.Unmarshal(b, &registries); err != nil {
+		return errors.Wrap(err, "couldn't decode e2e image registry config")
+	}
+	for k := range registries {
+		registries[k] = registry
+	}
+	if b, err = yaml.Marshal(registries); err != nil {
+		return errors.Wrap(err, "couldn't encode e2e image registry config")
+	}
+	return os.WriteFile(file, b, 0644)
+}
+
+func writeBundleFile(dir, file string, b []byte) error {
+	p := filepath.Join(dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, b, 0644)
+}
+
+// imagesFromYAML returns the images set in the YAML document(s).
+func imagesFromYAML(b []byte) []string {
+	images := []string{}
+	for _, m := range imageLineRE.FindAllSubmatch(b, -1) {
+		images = append(images, string(m[2]))
+	}
+	return images
+}
+
+// resolveImages replaces the Kubernetes version placeholder in the images with the version. Images
+// which still can't be parsed, such as those using other placeholders, are skipped with a warning.
+func resolveImages(images []string, k8sVersion string) []string {
+	ret := []string{}
+	for _, img := range images {
+		resolved := resolveK8sVersion(img, k8sVersion)
+		if _, err := name.ParseReference(resolved); err != nil {
+			logrus.Warnf("Skipping image %v since it can't be resolved to an image reference: %v", img, err)
+			continue
+		}
+		ret = append(ret, resolved)
+	}
+	return ret
+}
+
+// resolveK8sVersion replaces the Kubernetes version placeholder in the image if the version is known.
+func resolveK8sVersion(img, k8sVersion string) string {
+	if len(k8sVersion) == 0 {
+		return img
+	}
+	return strings.ReplaceAll(img, k8sVersionPlaceholder, k8sVersion)
+}
+
+// rewriteImages replaces the images set in the YAML document(s) using the given mapping. Images
+// which aren't in the mapping are left unchanged.
+func rewriteImages(b []byte, translated map[string]string) []byte {
+	return imageLineRE.ReplaceAllFunc(b, func(line []byte) []byte {
+		m := imageLineRE.FindSubmatch(line)
+		dst, ok := translated[string(m[2])]
+		if !ok {
+			return line
+		}
+		return []byte(string(m[1]) + dst + string(m[3]))
+	})
+}
+
+// conformanceImages returns the images which are Kubernetes conformance images, whose test images
+// need to be bundled as well.
+func conformanceImages(images []string) []string {
+	ret := []string{}
+	for _, img := range uniqueImages(images) {
+		repo := img
+		if i := strings.Index(repo, "@"); i >= 0 {
+			repo = repo[:i]
+		}
+		if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+			repo = repo[:i]
+		}
+		if path.Base(repo) == "conformance" {
+			ret = append(ret, img)
+		}
+	}
+	return ret
+}
+
+// uniqueImages returns the sorted images without duplicates or the placeholders for images
+// which can't be pulled.
+func uniqueImages(images []string) []string {
+	seen := map[string]bool{}
+	ret := []string{}
+	for _, img := range images {
+		if seen[img] || strings.HasPrefix(img, "invalid") {
+			continue
+		}
+		seen[img] = true
+		ret = append(ret, img)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,273 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/vmware-tanzu/sonobuoy/pkg/image"
+)
+
+func newBundleTestRegistry(t *testing.T) string {
+	t.Helper()
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(s.Close)
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func pushBundleTestImage(t *testing.T, img string) string {
+	t.Helper()
+	i, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, i); err != nil {
+		t.Fatal(err)
+	}
+	d, err := i.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d.String()
+}
+
+func TestImagesFromYAML(t *testing.T) {
+	input := `spec:
+  containers:
+  - image: sonobuoy/sonobuoy:v1
+    name: kube-sonobuoy
+  - name: other
+    image: "registry.k8s.io/conformance:v1.27.1"
+data:
+  plugin.yaml: |
+    podSpec:
+      containers:
+        - image: 'example.com/plugin@sha256:abc'
+    notimage: foo
+`
+	expect := []string{"sonobuoy/sonobuoy:v1", "registry.k8s.io/conformance:v1.27.1", "example.com/plugin@sha256:abc"}
+	if got := imagesFromYAML([]byte(input)); !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %v but got %v", expect, got)
+	}
+
+	got := string(rewriteImages([]byte(input), map[string]string{
+		"sonobuoy/sonobuoy:v1":                "internal/sonobuoy:v1",
+		"registry.k8s.io/conformance:v1.27.1": "internal/conformance:v1.27.1",
+	}))
+	for _, line := range []string{
+		"  - image: internal/sonobuoy:v1\n",
+		"    image: internal/conformance:v1.27.1\n",
+		"        - image: 'example.com/plugin@sha256:abc'\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("Expected rewritten YAML to contain %q but got:\n%v", line, got)
+		}
+	}
+}
+
+func TestResolveImages(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		k8sVersion string
+		images     []string
+		expect     []string
+	}{
+		{
+			desc:       "Version placeholder is replaced",
+			k8sVersion: "v1.27.1",
+			images:     []string{"registry.k8s.io/conformance:$SONOBUOY_K8S_VERSION", "sonobuoy/sonobuoy:v1"},
+			expect:     []string{"registry.k8s.io/conformance:v1.27.1", "sonobuoy/sonobuoy:v1"},
+		}, {
+			desc:   "Unknown version skips the image",
+			images: []string{"registry.k8s.io/conformance:$SONOBUOY_K8S_VERSION", "sonobuoy/sonobuoy:v1"},
+			expect: []string{"sonobuoy/sonobuoy:v1"},
+		}, {
+			desc:       "Other placeholders skip the image",
+			k8sVersion: "v1.27.1",
+			images:     []string{"example.com/plugin:$PLUGIN_VERSION"},
+			expect:     []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := resolveImages(tc.images, tc.k8sVersion); !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("Expected %v but got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestConformanceImages(t *testing.T) {
+	got := conformanceImages([]string{
+		"registry.k8s.io/conformance:v1.27.1",
+		"internal:5000/mirror/conformance:v1.27.1",
+		"registry.k8s.io/conformance-amd64:v1.27.1",
+		"sonobuoy/sonobuoy:v1",
+		"registry.k8s.io/e2e-test-images/agnhost:2.43",
+	})
+	expect := []string{"internal:5000/mirror/conformance:v1.27.1", "registry.k8s.io/conformance:v1.27.1"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %v but got %v", expect, got)
+	}
+}
+
+func TestBundleCreateAndLoad(t *testing.T) {
+	src, dst := newBundleTestRegistry(t), newBundleTestRegistry(t)
+	digests := map[string]string{
+		src + "/sonobuoy/sonobuoy:v1": pushBundleTestImage(t, src+"/sonobuoy/sonobuoy:v1"),
+		src + "/systemd-logs:v1.27.1": pushBundleTestImage(t, src+"/systemd-logs:v1.27.1"),
+		src + "/custom/plugin:v2":     pushBundleTestImage(t, src+"/custom/plugin:v2"),
+	}
+
+	manifest := "spec:\n  containers:\n  - image: " + src + "/sonobuoy/sonobuoy:v1\n---\ndata:\n  systemd.yaml: |\n    spec:\n      image: " + src + "/systemd-logs:$SONOBUOY_K8S_VERSION\n"
+	pluginDir := t.TempDir()
+	plugin := "sonobuoy-config:\n  driver: Job\n  plugin-name: custom\nspec:\n  image: " + src + "/custom/plugin:v2\n  name: plugin\n"
+	if err := os.WriteFile(filepath.Join(pluginDir, "custom.yaml"), []byte(plugin), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := image.NewRegistryClient("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := c.(image.RegistryClient)
+	bundle := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := createBundle(bundle, []byte(manifest), pluginDir, "v1.27.1", PluginEnvVars{}, rc, rc); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("load pushes and rewrites images", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "bundle")
+		if err := loadBundle(bundle, dir, dst+"/internal", rc); err != nil {
+			t.Fatal(err)
+		}
+
+		for img, d := range digests {
+			translated := translateRegistry(img, dst+"/internal", nil)
+			ref, err := name.ParseReference(translated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			desc, err := remote.Head(ref)
+			if err != nil {
+				t.Fatalf("Expected %v to be pushed: %v", translated, err)
+			}
+			if desc.Digest.String() != d {
+				t.Errorf("Expected %v to have digest %v but got %v", translated, d, desc.Digest)
+			}
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, bundleManifestFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string{dst + "/internal/sonobuoy:v1", dst + "/internal/systemd-logs:$SONOBUOY_K8S_VERSION"}
+		if got := imagesFromYAML(b); !reflect.DeepEqual(got, expect) {
+			t.Errorf("Expected manifest images %v but got %v", expect, got)
+		}
+		b, err = os.ReadFile(filepath.Join(dir, bundlePluginsDir, "custom.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := imagesFromYAML(b); !reflect.DeepEqual(got, []string{dst + "/internal/plugin:v2"}) {
+			t.Errorf("Expected plugin image to be rewritten but got %v", got)
+		}
+	})
+
+	t.Run("tampered bundles are rejected", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "bundle")
+		if err := loadBundle(bundle, dir, dst+"/first", rc); err != nil {
+			t.Fatal(err)
+		}
+		// Loading extracts over the rewritten files; an unexpected file is still caught.
+		if err := os.WriteFile(filepath.Join(dir, bundlePluginsDir, "extra.yaml"), []byte("spec: {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := loadBundle(bundle, dir, dst+"/second", rc)
+		if err == nil || !strings.Contains(err.Error(), "extra.yaml") {
+			t.Errorf("Expected an error about the unexpected file but got %v", err)
+		}
+	})
+}
+
+func TestVerifyBundle(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeBundleFile(dir, bundleManifestFile, []byte("kind: Pod\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeBundleFile(dir, "images/index.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	files, err := hashBundleFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := files["images/index.json"]; ok || len(files) != 1 {
+		t.Errorf("Expected only the manifest to be hashed but got %v", files)
+	}
+	b, err := json.Marshal(bundleLock{Files: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeBundleFile(dir, bundleLockFile, b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyBundle(dir); err != nil {
+		t.Errorf("Expected bundle to be valid but got %v", err)
+	}
+
+	if err := writeBundleFile(dir, bundleManifestFile, []byte("kind: Job\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyBundle(dir); err == nil || !strings.Contains(err.Error(), bundleManifestFile) {
+		t.Errorf("Expected an error for the modified manifest but got %v", err)
+	}
+}
+
+func TestRewriteRepoConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "repo-config.yaml")
+	if err := os.WriteFile(file, []byte("e2eRegistry: gcr.io/kubernetes-e2e-test-images\ngcRegistry: registry.k8s.io\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := rewriteRepoConfig(file, "internal.example.com/mirror"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "e2eRegistry: internal.example.com/mirror\ngcRegistry: internal.example.com/mirror\n"
+	if string(b) != expect {
+		t.Errorf("Expected %q but got %q", expect, string(b))
+	}
+}
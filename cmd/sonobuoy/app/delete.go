@@ -17,6 +17,8 @@ limitations under the License.
 package app
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -35,6 +37,9 @@ type deleteFlags struct {
 	kubeconfig Kubeconfig
 	waitOutput WaitOutputMode
 	dryRun     bool
+	fleet      fleetFlags
+
+	namespaceScoped bool
 }
 
 func NewCmdDelete() *cobra.Command {
@@ -57,41 +62,82 @@ func NewCmdDelete() *cobra.Command {
 	} else {
 		AddWaitOutputFlag(&f.waitOutput, cmd.Flags(), SilentOutputMode)
 	}
+	cmd.Flags().BoolVar(
+		&f.namespaceScoped, namespaceScopedFlag, false,
+		"If true, only delete the Sonobuoy resources within the namespace, leaving the namespace itself in place. Use for runs started with --namespace-scoped.",
+	)
+	AddFleetFlags(&f.fleet, cmd.Flags())
 	return cmd
 }
 
 func deleteSonobuoyRun(f *deleteFlags) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
+		if f.fleet.enabled() {
+			if err := deleteFleetRuns(os.Stdout, f); err != nil {
+				errlog.LogError(err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		sbc, err := getSonobuoyClientFromKubecfg(f.kubeconfig)
 		if err != nil {
 			errlog.LogError(errors.Wrap(err, "could not create sonobuoy client"))
 			os.Exit(1)
 		}
 
-		kc, err := sbc.Client()
-		if err != nil {
+		if err := deleteRun(sbc, f, f.waitOutput.String()); err != nil {
 			errlog.LogError(err)
 			os.Exit(1)
 		}
+	}
+}
 
-		rbacEnabled, err := f.rbacMode.Enabled(kc)
-		if err != nil {
-			errlog.LogError(errors.Wrap(err, "couldn't detect RBAC status"))
-			os.Exit(1)
-		}
+func deleteRun(sbc *client.SonobuoyClient, f *deleteFlags, waitOutput string) error {
+	kc, err := sbc.Client()
+	if err != nil {
+		return err
+	}
 
-		deleteCfg := &client.DeleteConfig{
-			Namespace:  f.namespace,
-			EnableRBAC: rbacEnabled,
-			DeleteAll:  f.deleteAll,
-			Wait:       time.Duration(f.wait) * time.Minute,
-			WaitOutput: f.waitOutput.String(),
-			DryRun:     f.dryRun,
-		}
+	rbacEnabled, err := f.rbacMode.Enabled(kc)
+	if err != nil {
+		return errors.Wrap(err, "couldn't detect RBAC status")
+	}
 
-		if err := sbc.Delete(deleteCfg); err != nil {
-			errlog.LogError(errors.Wrap(err, "failed to delete sonobuoy resources"))
-			os.Exit(1)
+	deleteCfg := &client.DeleteConfig{
+		Namespace:  f.namespace,
+		EnableRBAC: rbacEnabled,
+		DeleteAll:  f.deleteAll,
+		Wait:       time.Duration(f.wait) * time.Minute,
+		WaitOutput: waitOutput,
+		DryRun:     f.dryRun,
+
+		NamespaceScoped: f.namespaceScoped,
+	}
+
+	return errors.Wrap(sbc.Delete(deleteCfg), "failed to delete sonobuoy resources")
+}
+
+// deleteFleetRuns deletes the runs on each cluster of the fleet concurrently.
+func deleteFleetRuns(w io.Writer, f *deleteFlags) error {
+	clusters, err := f.fleet.clusters(f.kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	res := forEachCluster(clusters, func(c fleetCluster, sbc *client.SonobuoyClient) fleetResult {
+		// Interleaved spinners/progress from many clusters would be unreadable.
+		if err := deleteRun(sbc, f, string(SilentOutputMode)); err != nil {
+			return fleetResult{err: err}
 		}
+		return fleetResult{detail: "deleted"}
+	})
+
+	if err := printFleetResults(w, res); err != nil {
+		return err
+	}
+	if failed := fleetFailures(res); failed > 0 {
+		return fmt.Errorf("failed to delete sonobuoy resources on %v of %v clusters", failed, len(res))
 	}
+	return nil
 }
@@ -0,0 +1,239 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/client"
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/aggregation"
+)
+
+const (
+	kubeconfigDirFlag = "kubeconfig-dir"
+	contextsFlag      = "contexts"
+)
+
+// fleetFlags allow a command to be run against many clusters at once.
+type fleetFlags struct {
+	kubeconfigDir string
+	contexts      []string
+}
+
+// AddFleetFlags adds the flags to target multiple clusters at once.
+func AddFleetFlags(f *fleetFlags, flags *pflag.FlagSet) {
+	flags.StringVar(
+		&f.kubeconfigDir, kubeconfigDirFlag, "",
+		"Directory of kubeconfig files. The command is run concurrently against the cluster of each file, named after the file.",
+	)
+	flags.StringSliceVar(
+		&f.contexts, contextsFlag, nil,
+		"Comma-separated list of kubeconfig contexts. The command is run concurrently against the cluster of each context.",
+	)
+}
+
+// enabled returns true if the command should be run against a fleet of clusters.
+func (f *fleetFlags) enabled() bool {
+	return f != nil && (len(f.kubeconfigDir) > 0 || len(f.contexts) > 0)
+}
+
+// fleetCluster is a single cluster in the fleet and the kubeconfig used to reach it.
+type fleetCluster struct {
+	name    string
+	kubecfg Kubeconfig
+}
+
+// clusters returns the clusters in the fleet. When using contexts, the base kubeconfig
+// determines which file the contexts are loaded from.
+func (f *fleetFlags) clusters(base Kubeconfig) ([]fleetCluster, error) {
+	if len(f.kubeconfigDir) > 0 && len(f.contexts) > 0 {
+		return nil, fmt.Errorf("only one of --%v and --%v may be set", kubeconfigDirFlag, contextsFlag)
+	}
+
+	clusters := []fleetCluster{}
+	if len(f.kubeconfigDir) > 0 {
+		entries, err := os.ReadDir(f.kubeconfigDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading kubeconfig directory %v", f.kubeconfigDir)
+		}
+		for _, e := range entries {
+			if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			clusters = append(clusters, fleetCluster{
+				name: strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())),
+				kubecfg: Kubeconfig{
+					ClientConfigLoadingRules: &clientcmd.ClientConfigLoadingRules{
+						ExplicitPath: filepath.Join(f.kubeconfigDir, e.Name()),
+					},
+				},
+			})
+		}
+	}
+
+	for _, ctx := range f.contexts {
+		ctx = strings.TrimSpace(ctx)
+		if len(ctx) == 0 {
+			continue
+		}
+		kubecfg := base
+		kubecfg.Context = ctx
+		clusters = append(clusters, fleetCluster{name: ctx, kubecfg: kubecfg})
+	}
+
+	if len(clusters) == 0 {
+		return nil, errors.New("no clusters found for the fleet")
+	}
+
+	seen := map[string]bool{}
+	for _, c := range clusters {
+		if seen[c.name] {
+			return nil, fmt.Errorf("cluster name %q is not unique", c.name)
+		}
+		seen[c.name] = true
+	}
+
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].name < clusters[j].name })
+	return clusters, nil
+}
+
+// fleetResult is the outcome of an action against a single cluster.
+type fleetResult struct {
+	cluster string
+	status  *aggregation.Status
+	detail  string
+	err     error
+}
+
+// forEachCluster runs fn concurrently against every cluster and returns the results in the
+// same order as the clusters.
+func forEachCluster(clusters []fleetCluster, fn func(fleetCluster, *client.SonobuoyClient) fleetResult) []fleetResult {
+	out := make([]fleetResult, len(clusters))
+	var wg sync.WaitGroup
+	wg.Add(len(clusters))
+	for i := range clusters {
+		go func(i int) {
+			defer wg.Done()
+			sbc, err := getSonobuoyClientFromKubecfg(clusters[i].kubecfg)
+			if err != nil {
+				out[i] = fleetResult{err: errors.Wrap(err, "could not create sonobuoy client")}
+			} else {
+				out[i] = fn(clusters[i], sbc)
+			}
+			out[i].cluster = clusters[i].name
+		}(i)
+	}
+	wg.Wait()
+	return out
+}
+
+// fleetFailures returns the number of clusters for which the action failed.
+func fleetFailures(res []fleetResult) int {
+	failed := 0
+	for _, r := range res {
+		if r.err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// printFleetResults prints the outcome of an action against each cluster.
+func printFleetResults(w io.Writer, res []fleetResult) error {
+	tw := defaultTabWriter(w)
+	fmt.Fprintf(tw, "CLUSTER\tRESULT\t\n")
+	for _, r := range res {
+		fmt.Fprintf(tw, "%s\t%s\t\n", r.cluster, fleetResultDetail(r))
+	}
+	return errors.Wrap(tw.Flush(), "couldn't write fleet results out")
+}
+
+func fleetResultDetail(r fleetResult) string {
+	if r.err != nil {
+		return "error: " + r.err.Error()
+	}
+	return r.detail
+}
+
+// printFleetStatus prints a combined table of the plugin statuses of every cluster.
+func printFleetStatus(w io.Writer, res []fleetResult) error {
+	tw := defaultTabWriter(w)
+	fmt.Fprintf(tw, "CLUSTER\tPLUGIN\tSTATUS\tRESULT\tCOUNT\tPROGRESS\t\n")
+	for _, r := range res {
+		if r.status == nil {
+			fmt.Fprintf(tw, "%s\t\t%s\t\t\t\t\n", r.cluster, fleetResultDetail(r))
+			continue
+		}
+		for _, summary := range summarizeStatus(r.status) {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t\n", r.cluster, summary.plugin, summary.status, summary.result, summary.count, summary.progressMsg)
+		}
+	}
+	return errors.Wrap(tw.Flush(), "couldn't write status out")
+}
+
+// printFleetSummary prints one line per cluster with the overall run status and result counts
+// followed by totals for the entire fleet.
+func printFleetSummary(w io.Writer, res []fleetResult) error {
+	tw := defaultTabWriter(w)
+	fmt.Fprintf(tw, "CLUSTER\tSTATUS\tPASSED\tFAILED\tOTHER\tDETAILS\t\n")
+
+	complete, withFailures := 0, 0
+	for _, r := range res {
+		if r.status == nil {
+			fmt.Fprintf(tw, "%s\t%s\t\t\t\t%s\t\n", r.cluster, "unknown", fleetResultDetail(r))
+			continue
+		}
+
+		passed, failed, other := 0, 0, 0
+		for _, p := range r.status.Plugins {
+			for result, count := range p.ResultStatusCounts {
+				switch result {
+				case results.StatusPassed:
+					passed += count
+				case results.StatusFailed:
+					failed += count
+				default:
+					other += count
+				}
+			}
+		}
+		if r.status.Status == aggregation.CompleteStatus {
+			complete++
+		}
+		if failed > 0 || r.status.Status == aggregation.FailedStatus {
+			withFailures++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\t\n", r.cluster, r.status.Status, passed, failed, other, fleetResultDetail(r))
+	}
+	if err := tw.Flush(); err != nil {
+		return errors.Wrap(err, "couldn't write fleet summary out")
+	}
+
+	fmt.Fprintf(w, "\n%d of %d clusters complete, %d with failures, %d with errors.\n", complete, len(res), withFailures, fleetFailures(res))
+	return nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/aggregation"
+)
+
+func TestFleetClusters(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"prod.yaml", "staging.kubeconfig", "dev", ".hidden"} {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	dupDir := t.TempDir()
+	for _, f := range []string{"prod.yaml", "prod.yml"} {
+		if err := os.WriteFile(filepath.Join(dupDir, f), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		desc        string
+		flags       fleetFlags
+		expect      []string
+		expectedErr string
+	}{
+		{
+			desc:   "Directory skips hidden files and subdirectories",
+			flags:  fleetFlags{kubeconfigDir: dir},
+			expect: []string{"dev", "prod", "staging"},
+		}, {
+			desc:   "Contexts are sorted and blanks ignored",
+			flags:  fleetFlags{contexts: []string{"b", " ", "a"}},
+			expect: []string{"a", "b"},
+		}, {
+			desc:        "Both directory and contexts",
+			flags:       fleetFlags{kubeconfigDir: dir, contexts: []string{"a"}},
+			expectedErr: "only one of --kubeconfig-dir and --contexts may be set",
+		}, {
+			desc:        "Duplicate names",
+			flags:       fleetFlags{kubeconfigDir: dupDir},
+			expectedErr: `cluster name "prod" is not unique`,
+		}, {
+			desc:        "Duplicate contexts",
+			flags:       fleetFlags{contexts: []string{"a", "a"}},
+			expectedErr: `cluster name "a" is not unique`,
+		}, {
+			desc:        "Empty directory",
+			flags:       fleetFlags{kubeconfigDir: t.TempDir()},
+			expectedErr: "no clusters found for the fleet",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			clusters, err := tc.flags.clusters(Kubeconfig{})
+			switch {
+			case len(tc.expectedErr) > 0 && err == nil:
+				t.Fatalf("Expected error %q but got nil", tc.expectedErr)
+			case len(tc.expectedErr) > 0 && err.Error() != tc.expectedErr:
+				t.Fatalf("Expected error %q but got %q", tc.expectedErr, err.Error())
+			case len(tc.expectedErr) == 0 && err != nil:
+				t.Fatalf("Expected no error but got %v", err)
+			}
+
+			var names []string
+			for _, c := range clusters {
+				names = append(names, c.name)
+				if len(tc.flags.contexts) > 0 && c.kubecfg.Context != c.name {
+					t.Errorf("Expected context %v but got %v", c.name, c.kubecfg.Context)
+				}
+			}
+			if !reflect.DeepEqual(names, tc.expect) {
+				t.Errorf("Expected clusters %v but got %v", tc.expect, names)
+			}
+		})
+	}
+}
+
+func TestPrintFleetSummary(t *testing.T) {
+	res := []fleetResult{
+		{
+			cluster: "a",
+			status: &aggregation.Status{
+				Status: aggregation.CompleteStatus,
+				Plugins: []aggregation.PluginStatus{
+					{Plugin: "e2e", ResultStatusCounts: map[string]int{results.StatusPassed: 10, results.StatusSkipped: 2}},
+				},
+			},
+			detail: "a/results.tar.gz",
+		}, {
+			cluster: "b",
+			status: &aggregation.Status{
+				Status: aggregation.CompleteStatus,
+				Plugins: []aggregation.PluginStatus{
+					{Plugin: "e2e", ResultStatusCounts: map[string]int{results.StatusPassed: 8, results.StatusFailed: 1}},
+				},
+			},
+		}, {
+			cluster: "c",
+			err:     errors.New("connection refused"),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := printFleetSummary(&buf, res); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, expect := range []string{
+		"a   complete       10        0       2",
+		"a/results.tar.gz",
+		"b   complete        8        1       0",
+		"c    unknown",
+		"error: connection refused",
+		"2 of 3 clusters complete, 1 with failures, 1 with errors.",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("Expected output to contain %q but got:\n%v", expect, out)
+		}
+	}
+}
+
+func TestPrintFleetStatus(t *testing.T) {
+	res := []fleetResult{
+		{
+			cluster: "a",
+			status: &aggregation.Status{
+				Status: aggregation.RunningStatus,
+				Plugins: []aggregation.PluginStatus{
+					{Plugin: "e2e", Node: "global", Status: aggregation.RunningStatus},
+				},
+			},
+		}, {
+			cluster: "b",
+			err:     errors.New("not found"),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := printFleetStatus(&buf, res); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines but got %v:\n%v", len(lines), buf.String())
+	}
+	if fields := strings.Fields(lines[1]); !reflect.DeepEqual(fields[:3], []string{"a", "e2e", "running"}) {
+		t.Errorf("Unexpected status line %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "error: not found") {
+		t.Errorf("Expected error for cluster b but got %q", lines[2])
+	}
+}
@@ -48,13 +48,17 @@ type genFlags struct {
 	sshKeyPath         string
 	k8sVersion         imagepkg.ConformanceImageVersion
 	showDefaultPodSpec bool
+	pinDigests         bool
+
+	requireSignedPlugins bool
 
 	// These values are mainly for `run` but we want `gen` to support all the same
 	// flags so you can just swap out gen/run.
-	skipPreflight []string
-	wait          int
-	waitOutput    WaitOutputMode
-	genFile       string
+	skipPreflight      []string
+	preflightImagePull ImagePullCheckMode
+	wait               int
+	waitOutput         WaitOutputMode
+	genFile            string
 
 	// plugins will keep a list of the plugins we want. Custom type for
 	// flag support.
@@ -87,14 +91,17 @@ func GenFlagSet(cfg *genFlags, rbac RBACMode) *pflag.FlagSet {
 	AddServiceAccountNameFlag(&cfg.sonobuoyConfig.ServiceAccountName, genset)
 	AddExistingServiceAccountFlag(&cfg.sonobuoyConfig.ExistingServiceAccount, genset)
 	AddNamespacePSAEnforceLevelFlag(&cfg.sonobuoyConfig.NamespacePSAEnforceLevel, genset)
+	AddNamespaceScopedFlag(&cfg.sonobuoyConfig.NamespaceScoped, genset)
 
 	AddNamespaceFlag(&cfg.sonobuoyConfig.Namespace, genset)
 	AddDNSNamespaceFlag(&cfg.dnsNamespace, genset)
 	AddDNSPodLabelsFlag(&cfg.dnsPodLabels, genset)
 	AddSonobuoyImage(&cfg.sonobuoyConfig.WorkerImage, genset)
+	AddPinDigestsFlag(&cfg.pinDigests, genset)
 	AddSSHKeyPathFlag(&cfg.sshKeyPath, &cfg.pluginTransforms, genset)
 
 	AddPluginSetFlag(&cfg.plugins, genset)
+	AddRequireSignedPluginsFlag(&cfg.requireSignedPlugins, genset)
 	AddPluginEnvFlag(&cfg.pluginEnvs, genset)
 	AddLegacyE2EFlags(&cfg.sonobuoyConfig, &cfg.pluginEnvs, &cfg.pluginTransforms, genset)
 
@@ -110,6 +117,7 @@ func GenFlagSet(cfg *genFlags, rbac RBACMode) *pflag.FlagSet {
 	AddSecurityContextMode(&cfg.sonobuoyConfig.SecurityContextMode, genset)
 
 	AddSkipPreflightFlag(&cfg.skipPreflight, genset)
+	AddPreflightImagePullFlag(&cfg.preflightImagePull, genset)
 	AddRunWaitFlag(&cfg.wait, genset)
 	if features.Enabled(features.WaitOutputProgressByDefault) {
 		AddWaitOutputFlag(&cfg.waitOutput, genset, ProgressOutputMode)
@@ -129,6 +137,11 @@ func (g *genFlags) Config() (*client.GenConfig, error) {
 	if len(g.plugins.DynamicPlugins) == 0 && len(g.plugins.StaticPlugins) == 0 {
 		g.plugins.DynamicPlugins = []string{e2ePlugin, systemdLogsPlugin}
 	}
+	if g.requireSignedPlugins {
+		if err := g.plugins.checkSigned(); err != nil {
+			return nil, err
+		}
+	}
 
 	// In some configurations, the kube client isn't actually needed for correct executation
 	// Therefore, delay reporting the error until we're sure we need the client
@@ -167,6 +180,12 @@ func (g *genFlags) Config() (*client.GenConfig, error) {
 		k8sVersion = g.k8sVersion.String()
 	}
 
+	// Namespace-scoped runs can't use cluster roles; switch the default permissions so that
+	// the flag works on its own. Explicitly choosing other permissions is caught by validation.
+	if g.sonobuoyConfig.NamespaceScoped && g.sonobuoyConfig.AggregatorPermissions == config.DefaultAggregatorPermissions {
+		g.sonobuoyConfig.AggregatorPermissions = config.AggregatorPermissionsNamespaceAdmin
+	}
+
 	if g.sonobuoyConfig.E2EDockerConfigFile != "" {
 		if err := verifyKubernetesVersion(k8sVersion); err != nil {
 			return nil, err
@@ -176,6 +195,15 @@ func (g *genFlags) Config() (*client.GenConfig, error) {
 		}
 	}
 
+	var resolver client.DigestResolver
+	if g.pinDigests {
+		c, err := imagepkg.NewRegistryClient("", nil)
+		if err != nil {
+			return nil, err
+		}
+		resolver = registryImageResolver{client: c.(imagepkg.RegistryClient)}
+	}
+
 	return &client.GenConfig{
 		Config:             &g.sonobuoyConfig.Config,
 		EnableRBAC:         rbacEnabled,
@@ -188,9 +216,24 @@ func (g *genFlags) Config() (*client.GenConfig, error) {
 		NodeSelectors:      g.nodeSelectors,
 		KubeVersion:        k8sVersion,
 		PluginTransforms:   g.pluginTransforms,
+		DigestResolver:     resolver,
 	}, nil
 }
 
+// registryImageResolver resolves digests by querying the registries directly. Listing the e2e
+// test images requires running the conformance image so uses the docker client.
+type registryImageResolver struct {
+	client imagepkg.RegistryClient
+}
+
+func (r registryImageResolver) ResolveDigest(image string) (string, error) {
+	return r.client.ResolveDigest(image)
+}
+
+func (r registryImageResolver) TestImages(conformanceImage string, env map[string]string) ([]string, error) {
+	return listE2EImages(conformanceImage, env, imagepkg.NewDockerClient())
+}
+
 func (g *genFlags) RunConfig() (*client.RunConfig, error) {
 	runcfg := &client.RunConfig{
 		Wait:       time.Duration(g.wait) * time.Minute,
@@ -17,10 +17,17 @@ limitations under the License.
 package app
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -29,6 +36,8 @@ import (
 	"github.com/vmware-tanzu/sonobuoy/pkg/errlog"
 	"github.com/vmware-tanzu/sonobuoy/pkg/image"
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 // Number times to retry docker commands before giving up
@@ -36,6 +45,12 @@ const (
 	numDockerRetries  = 1
 	e2ePlugin         = "e2e"
 	systemdLogsPlugin = "systemd-logs"
+
+	imageClientDocker   = "docker"
+	imageClientRegistry = "registry"
+
+	inventoryFormatJSON = "json"
+	inventoryFormatCSV  = "csv"
 )
 
 type imagesFlags struct {
@@ -47,6 +62,12 @@ type imagesFlags struct {
 	dryRun            bool
 	k8sVersion        image.ConformanceImageVersion
 	pluginEnvs        PluginEnvVars
+	imageClient       string
+	archiveFormat     string
+	platforms         []string
+	inventoryFormat   string
+	allowHostExec     bool
+	strictPlatforms   bool
 }
 
 var (
@@ -54,8 +75,47 @@ var (
 	transformSink = map[string][]func(*manifest.Manifest) error{}
 )
 
+// newImageClient returns the image client chosen by the flags. Dry-runs take precedence over
+// the chosen client so that nothing is pulled or pushed. Flags which only apply to the registry
+// client are rejected for the others rather than being silently ignored.
+func newImageClient(flags imagesFlags) (image.Client, error) {
+	if flags.imageClient != imageClientRegistry {
+		if len(flags.platforms) > 0 {
+			return nil, fmt.Errorf("flag %q requires --%v=%v", platformFlag, imageClientFlag, imageClientRegistry)
+		}
+		if len(flags.archiveFormat) > 0 {
+			return nil, fmt.Errorf("flag %q requires --%v=%v", archiveFormatFlag, imageClientFlag, imageClientRegistry)
+		}
+	}
+	if flags.dryRun {
+		return image.DryRunClient{}, nil
+	}
+	switch flags.imageClient {
+	case "", imageClientDocker:
+		return image.NewDockerClient(), nil
+	case imageClientRegistry:
+		platforms, err := image.ParsePlatforms(flags.platforms)
+		if err != nil {
+			return nil, err
+		}
+		c, err := image.NewRegistryClient(flags.archiveFormat, platforms)
+		if err != nil {
+			return nil, err
+		}
+		rc := c.(image.RegistryClient)
+		rc.AllowHostExecution = flags.allowHostExec
+		return rc, nil
+	default:
+		return nil, fmt.Errorf("unknown image client %q, expected %q or %q", flags.imageClient, imageClientDocker, imageClientRegistry)
+	}
+}
+
 func runListImages(flags imagesFlags) {
-	client := image.NewDockerClient()
+	client, err := newImageClient(flags)
+	if err != nil {
+		errlog.LogError(err)
+		os.Exit(1)
+	}
 	version, err := getClusterVersion(flags.k8sVersion, flags.kubeconfig)
 	if err != nil {
 		errlog.LogError(err)
@@ -68,14 +128,28 @@ func runListImages(flags imagesFlags) {
 }
 
 func runInspectImages(flags imagesFlags) {
-	client := image.NewDockerClient()
+	client, err := newImageClient(flags)
+	if err != nil {
+		errlog.LogError(err)
+		os.Exit(1)
+	}
 	version, err := getClusterVersion(flags.k8sVersion, flags.kubeconfig)
 	if err != nil {
 		errlog.LogError(err)
 		os.Exit(1)
 	}
 
-	if errs := inspectImages(flags.plugins, flags.pluginEnvs, version, client); err != nil {
+	if len(flags.inventoryFormat) > 0 {
+		if errs := inventoryImages(os.Stdout, flags.plugins, flags.pluginEnvs, version, flags.inventoryFormat, client); len(errs) > 0 {
+			for _, err := range errs {
+				errlog.LogError(err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
+	if errs := inspectImages(flags.plugins, flags.pluginEnvs, version, client); len(errs) > 0 {
 		for _, err := range errs {
 			errlog.LogError(err)
 		}
@@ -100,6 +174,8 @@ func NewCmdImages() *cobra.Command {
 	AddPluginListFlag(&flags.plugins, cmd.Flags())
 	AddPluginEnvFlag(&flags.pluginEnvs, cmd.Flags())
 	AddDryRunFlag(&flags.dryRun, cmd.Flags())
+	AddImageClientFlag(&flags.imageClient, cmd.Flags())
+	AddAllowHostExecFlag(&flags.allowHostExec, cmd.Flags())
 	AddKubernetesVersionFlag(&flags.k8sVersion, &transformSink, cmd.Flags())
 
 	cmd.AddCommand(listCmd())
@@ -119,6 +195,19 @@ func inspectCmd() *cobra.Command {
 		Use:   "inspect",
 		Short: "Inspect images",
 		Long:  "Inspect if image is available in the registry",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch flags.inventoryFormat {
+			case "":
+				return nil
+			case inventoryFormatJSON, inventoryFormatCSV:
+			default:
+				return fmt.Errorf("unknown inventory format %q, expected %q or %q", flags.inventoryFormat, inventoryFormatJSON, inventoryFormatCSV)
+			}
+			if flags.imageClient != imageClientRegistry || flags.dryRun {
+				return fmt.Errorf("flag %q requires --%v=%v", inventoryFormatFlag, imageClientFlag, imageClientRegistry)
+			}
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			runInspectImages(flags)
 		},
@@ -127,7 +216,10 @@ func inspectCmd() *cobra.Command {
 	AddKubeconfigFlag(&flags.kubeconfig, inspectCmd.Flags())
 	AddPluginListFlag(&flags.plugins, inspectCmd.Flags())
 	AddDryRunFlag(&flags.dryRun, inspectCmd.Flags())
+	AddImageClientFlag(&flags.imageClient, inspectCmd.Flags())
+	AddAllowHostExecFlag(&flags.allowHostExec, inspectCmd.Flags())
 	AddKubernetesVersionFlag(&flags.k8sVersion, &transformSink, inspectCmd.Flags())
+	AddInventoryFormatFlag(&flags.inventoryFormat, inspectCmd.Flags())
 
 	return inspectCmd
 }
@@ -146,6 +238,8 @@ func listCmd() *cobra.Command {
 	AddKubeconfigFlag(&flags.kubeconfig, listCmd.Flags())
 	AddPluginListFlag(&flags.plugins, listCmd.Flags())
 	AddDryRunFlag(&flags.dryRun, listCmd.Flags())
+	AddImageClientFlag(&flags.imageClient, listCmd.Flags())
+	AddAllowHostExecFlag(&flags.allowHostExec, listCmd.Flags())
 	AddKubernetesVersionFlag(&flags.k8sVersion, &transformSink, listCmd.Flags())
 
 	return listCmd
@@ -158,11 +252,10 @@ func pullCmd() *cobra.Command {
 		Use:   "pull",
 		Short: "Pulls images to local docker client for a specific plugin",
 		Run: func(cmd *cobra.Command, args []string) {
-			var client image.Client
-			if flags.dryRun {
-				client = image.DryRunClient{}
-			} else {
-				client = image.NewDockerClient()
+			client, err := newImageClient(flags)
+			if err != nil {
+				errlog.LogError(err)
+				os.Exit(1)
 			}
 			version, err := getClusterVersion(flags.k8sVersion, flags.kubeconfig)
 			if err != nil {
@@ -183,6 +276,8 @@ func pullCmd() *cobra.Command {
 	AddKubeconfigFlag(&flags.kubeconfig, pullCmd.Flags())
 	AddPluginListFlag(&flags.plugins, pullCmd.Flags())
 	AddDryRunFlag(&flags.dryRun, pullCmd.Flags())
+	AddImageClientFlag(&flags.imageClient, pullCmd.Flags())
+	AddAllowHostExecFlag(&flags.allowHostExec, pullCmd.Flags())
 	AddKubernetesVersionFlag(&flags.k8sVersion, &transformSink, pullCmd.Flags())
 
 	return pullCmd
@@ -197,21 +292,49 @@ func pushCmd() *cobra.Command {
 			if contains(flags.plugins, e2ePlugin) && len(flags.e2eRegistryConfig) == 0 && len(flags.e2eRegistry) == 0 {
 				return fmt.Errorf("required either flag %q or %q, but neither set", e2eRegistryConfigFlag, e2eRegistryFlag)
 			}
+			if len(flags.platforms) > 0 && flags.imageClient != imageClientRegistry {
+				return fmt.Errorf("flag %q requires --%v=%v", platformFlag, imageClientFlag, imageClientRegistry)
+			}
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			var client image.Client
-			if flags.dryRun {
-				client = image.DryRunClient{}
-			} else {
-				client = image.NewDockerClient()
+			client, err := newImageClient(flags)
+			if err != nil {
+				errlog.LogError(err)
+				os.Exit(1)
 			}
 			version, err := getClusterVersion(flags.k8sVersion, flags.kubeconfig)
 			if err != nil {
 				errlog.LogError(err)
 				os.Exit(1)
 			}
-			if errs := pushImages(flags.plugins, flags.pluginEnvs, flags.customRegistry, flags.e2eRegistryConfig, version, client); len(errs) > 0 {
+			imagePairs, errs := pushImages(flags.plugins, flags.pluginEnvs, flags.customRegistry, flags.e2eRegistryConfig, version, client)
+			if len(errs) > 0 {
+				for _, err := range errs {
+					errlog.LogError(err)
+				}
+				os.Exit(1)
+			}
+			if flags.dryRun {
+				return
+			}
+
+			reportClient, ok := client.(image.RegistryClient)
+			if !ok {
+				c, err := image.NewRegistryClient(image.ArchiveFormatDocker, nil)
+				if err != nil {
+					errlog.LogError(err)
+					os.Exit(1)
+				}
+				reportClient = c.(image.RegistryClient)
+			}
+			var platforms []string
+			if kubeClient, err := getClient(&flags.kubeconfig); err != nil {
+				logrus.Warnf("Unable to check the images against the platforms of the nodes: %v", err)
+			} else if platforms, err = getNodePlatforms(kubeClient); err != nil {
+				logrus.Warnf("Unable to check the images against the platforms of the nodes: %v", err)
+			}
+			if errs := reportPlatforms(os.Stdout, imagePairs, platforms, reportClient, flags.strictPlatforms); len(errs) > 0 {
 				for _, err := range errs {
 					errlog.LogError(err)
 				}
@@ -226,6 +349,10 @@ func pushCmd() *cobra.Command {
 	AddPluginListFlag(&flags.plugins, pushCmd.Flags())
 	AddCustomRegistryFlag(&flags.customRegistry, pushCmd.Flags())
 	AddDryRunFlag(&flags.dryRun, pushCmd.Flags())
+	AddImageClientFlag(&flags.imageClient, pushCmd.Flags())
+	AddAllowHostExecFlag(&flags.allowHostExec, pushCmd.Flags())
+	AddPlatformFlag(&flags.platforms, pushCmd.Flags())
+	AddStrictPlatformsFlag(&flags.strictPlatforms, pushCmd.Flags())
 	AddKubernetesVersionFlag(&flags.k8sVersion, &transformSink, pushCmd.Flags())
 
 	return pushCmd
@@ -237,11 +364,10 @@ func downloadCmd() *cobra.Command {
 		Use:   "download",
 		Short: "Saves downloaded images from local docker client to a tar file",
 		Run: func(cmd *cobra.Command, args []string) {
-			var client image.Client
-			if flags.dryRun {
-				client = image.DryRunClient{}
-			} else {
-				client = image.NewDockerClient()
+			client, err := newImageClient(flags)
+			if err != nil {
+				errlog.LogError(err)
+				os.Exit(1)
 			}
 			version, err := getClusterVersion(flags.k8sVersion, flags.kubeconfig)
 			if err != nil {
@@ -260,6 +386,9 @@ func downloadCmd() *cobra.Command {
 	AddKubeconfigFlag(&flags.kubeconfig, downloadCmd.Flags())
 	AddPluginListFlag(&flags.plugins, downloadCmd.Flags())
 	AddDryRunFlag(&flags.dryRun, downloadCmd.Flags())
+	AddImageClientFlag(&flags.imageClient, downloadCmd.Flags())
+	AddAllowHostExecFlag(&flags.allowHostExec, downloadCmd.Flags())
+	AddArchiveFormatFlag(&flags.archiveFormat, downloadCmd.Flags())
 	AddKubernetesVersionFlag(&flags.k8sVersion, &transformSink, downloadCmd.Flags())
 
 	return downloadCmd
@@ -271,11 +400,10 @@ func deleteCmd() *cobra.Command {
 		Use:   "delete",
 		Short: "Deletes all images downloaded to local docker client",
 		Run: func(cmd *cobra.Command, args []string) {
-			var client image.Client
-			if flags.dryRun {
-				client = image.DryRunClient{}
-			} else {
-				client = image.NewDockerClient()
+			client, err := newImageClient(flags)
+			if err != nil {
+				errlog.LogError(err)
+				os.Exit(1)
 			}
 
 			if errs := deleteImages(flags.plugins, flags.pluginEnvs, flags.kubeconfig, flags.e2eRegistryConfig, flags.k8sVersion.String(), client); len(errs) > 0 {
@@ -292,6 +420,8 @@ func deleteCmd() *cobra.Command {
 	AddKubeconfigFlag(&flags.kubeconfig, deleteCmd.Flags())
 	AddPluginListFlag(&flags.plugins, deleteCmd.Flags())
 	AddDryRunFlag(&flags.dryRun, deleteCmd.Flags())
+	AddImageClientFlag(&flags.imageClient, deleteCmd.Flags())
+	AddAllowHostExecFlag(&flags.allowHostExec, deleteCmd.Flags())
 	AddKubernetesVersionFlag(&flags.k8sVersion, &transformSink, deleteCmd.Flags())
 
 	return deleteCmd
@@ -338,6 +468,55 @@ func inspectImages(plugins []string, pluginEnvs PluginEnvVars, k8sVersion string
 	return client.InspectImages(images)
 }
 
+// inventoryImages writes an inventory of the images of the plugins in the given format. Images
+// which can't be inventoried are reported as errors without stopping the others.
+func inventoryImages(w io.Writer, plugins []string, pluginEnvs PluginEnvVars, k8sVersion, format string, client image.Client) []error {
+	rc, ok := client.(image.RegistryClient)
+	if !ok {
+		return []error{fmt.Errorf("the image inventory requires --%v=%v", imageClientFlag, imageClientRegistry)}
+	}
+	images, err := collectPluginsImages(plugins, pluginEnvs, k8sVersion, client)
+	if err != nil {
+		return []error{err, errors.Errorf("unable to collect images of plugins")}
+	}
+	sort.Strings(images)
+	items, errs := rc.Inventory(images)
+	if err := writeInventory(w, items, format); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func writeInventory(w io.Writer, items []image.InventoryItem, format string) error {
+	if format == inventoryFormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"image", "digest", "platform", "platform_digest", "size", "created", "sbom_type", "sbom_digest"}); err != nil {
+		return err
+	}
+	for _, item := range items {
+		err := cw.Write([]string{
+			item.Image,
+			item.Digest,
+			item.Platform,
+			item.PlatformDigest,
+			strconv.FormatInt(item.Size, 10),
+			item.Created.UTC().Format(time.RFC3339),
+			item.SBOMType,
+			item.SBOMDigest,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
 func pullImages(plugins []string, pluginEnvs PluginEnvVars, e2eRegistry, e2eRegistryConfig, k8sVersion string, client image.Client) []error {
 	images, err := collectPluginsImages(plugins, pluginEnvs, k8sVersion, client)
 	if err != nil {
@@ -389,16 +568,68 @@ func downloadImages(plugins []string, pluginEnvs PluginEnvVars, e2eRegistryConfi
 	return nil
 }
 
-func pushImages(plugins []string, pluginEnvs PluginEnvVars, customRegistry, e2eRegistryConfig, k8sVersion string, client image.Client) []error {
+// pushImages pushes the images of the plugins to the custom registries and returns the image pairs
+// which were pushed.
+func pushImages(plugins []string, pluginEnvs PluginEnvVars, customRegistry, e2eRegistryConfig, k8sVersion string, client image.Client) ([]image.TagPair, []error) {
 	images, err := collectPluginsImages(plugins, pluginEnvs, k8sVersion, client)
 	if err != nil {
-		return []error{err, errors.Errorf("unable to collect images of plugins")}
+		return nil, []error{err, errors.Errorf("unable to collect images of plugins")}
 	}
 	imagePairs, err := convertImagesToPairs(images, customRegistry, e2eRegistryConfig, k8sVersion)
 	if err != nil {
-		return []error{err}
+		return nil, []error{err}
 	}
-	return client.PushImages(imagePairs, numDockerRetries)
+	return imagePairs, client.PushImages(imagePairs, numDockerRetries)
+}
+
+// getNodePlatforms returns the platforms (os/arch) of the nodes in the cluster.
+func getNodePlatforms(client kubernetes.Interface) ([]string, error) {
+	nodes, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't list nodes")
+	}
+	seen := map[string]bool{}
+	platforms := []string{}
+	for _, node := range nodes.Items {
+		p := node.Status.NodeInfo.OperatingSystem + "/" + node.Status.NodeInfo.Architecture
+		if !seen[p] {
+			seen[p] = true
+			platforms = append(platforms, p)
+		}
+	}
+	sort.Strings(platforms)
+	return platforms, nil
+}
+
+// reportPlatforms prints the digest of each platform of the pushed images and warns about each image
+// which isn't available for all of the given node platforms. If strict, those images are returned as
+// errors instead.
+func reportPlatforms(w io.Writer, imagePairs []image.TagPair, nodePlatforms []string, client image.RegistryClient, strict bool) []error {
+	errs := []error{}
+	tw := tabwriter.NewWriter(w, 0, 2, 3, ' ', 0)
+	fmt.Fprintln(tw, "IMAGE\tPLATFORM\tDIGEST")
+	for _, pair := range imagePairs {
+		digests, err := client.GetPlatformDigests(pair.Dst)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, p := range digests.Platforms {
+			fmt.Fprintf(tw, "%v\t%v\t%v\n", pair.Dst, p.Platform, p.Digest)
+		}
+		missing := digests.Missing(nodePlatforms)
+		switch {
+		case len(missing) == 0:
+		case strict:
+			errs = append(errs, errors.Errorf("image %v is not available for the node platforms %v", pair.Dst, strings.Join(missing, ", ")))
+		default:
+			logrus.Warnf("Image %v is not available for the node platforms %v; pods using it will fail to start on those nodes", pair.Dst, strings.Join(missing, ", "))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
 }
 
 func deleteImages(plugins []string, pluginEnvs PluginEnvVars, kubeconfig Kubeconfig, e2eRegistryConfig, k8sVersion string, client image.Client) []error {
@@ -504,26 +735,35 @@ func collectPluginsImages(plugins []string, pluginEnvs PluginEnvVars, k8sVersion
 			images = append(images, conformanceImage)
 			logrus.Info("e2e image to be used: ", conformanceImage)
 
-			// pull before running to ensure stderr is empty, because...
-			client.PullImages([]string{conformanceImage}, numDockerRetries)
-
-			// we only need stdout, but this combines stdout and stderr
-			e2eImages, err := client.RunImage(conformanceImage, "e2e.test", pluginEnvs[e2ePlugin], "--list-images")
+			e2eImages, err := listE2EImages(conformanceImage, pluginEnvs[e2ePlugin], client)
 			if err != nil {
-				return images, errors.Wrap(err, "failed to gather test images from e2e image")
+				return images, err
 			}
-
-			// in case there are empty newlines getting parsed as a slice element
-			validE2eImages := []string{}
-			for _, e2eImage := range e2eImages {
-				if e2eImage != "" {
-					validE2eImages = append(validE2eImages, e2eImage)
-				}
-			}
-			images = append(images, validE2eImages...)
+			images = append(images, e2eImages...)
 		default:
 			return images, errors.Errorf("Unsupported plugin: %v", plugin)
 		}
 	}
 	return images, nil
 }
+
+// listE2EImages returns the images used by the tests in the given conformance image.
+func listE2EImages(conformanceImage string, env map[string]string, client image.Client) ([]string, error) {
+	// pull before running to ensure stderr is empty, because...
+	client.PullImages([]string{conformanceImage}, numDockerRetries)
+
+	// we only need stdout, but this combines stdout and stderr
+	e2eImages, err := client.RunImage(conformanceImage, "e2e.test", env, "--list-images")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to gather test images from e2e image")
+	}
+
+	// in case there are empty newlines getting parsed as a slice element
+	validE2eImages := []string{}
+	for _, e2eImage := range e2eImages {
+		if e2eImage != "" {
+			validE2eImages = append(validE2eImages, e2eImage)
+		}
+	}
+	return validE2eImages, nil
+}
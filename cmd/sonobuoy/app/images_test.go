@@ -16,10 +16,27 @@ limitations under the License.
 package app
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/vmware-tanzu/sonobuoy/pkg/image"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 const e2eConfigContent = `---
@@ -112,3 +129,195 @@ func TestGetClusterVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestNewImageClient(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		flags     imagesFlags
+		expect    image.Client
+		expectErr bool
+	}{
+		{
+			desc:   "Defaults to docker",
+			expect: image.NewDockerClient(),
+		}, {
+			desc:   "Dry-run takes precedence",
+			flags:  imagesFlags{dryRun: true, imageClient: imageClientRegistry},
+			expect: image.DryRunClient{},
+		}, {
+			desc:   "Registry client uses the archive format",
+			flags:  imagesFlags{imageClient: imageClientRegistry, archiveFormat: image.ArchiveFormatOCI},
+			expect: image.RegistryClient{ArchiveFormat: image.ArchiveFormatOCI},
+		}, {
+			desc:   "Registry client only runs images on the host if allowed",
+			flags:  imagesFlags{imageClient: imageClientRegistry, allowHostExec: true},
+			expect: image.RegistryClient{ArchiveFormat: image.ArchiveFormatDocker, AllowHostExecution: true},
+		}, {
+			desc:      "Registry client rejects unknown archive formats",
+			flags:     imagesFlags{imageClient: imageClientRegistry, archiveFormat: "zip"},
+			expectErr: true,
+		}, {
+			desc:      "Registry client rejects invalid platforms",
+			flags:     imagesFlags{imageClient: imageClientRegistry, platforms: []string{"linux"}},
+			expectErr: true,
+		}, {
+			desc:      "Platforms require the registry client",
+			flags:     imagesFlags{platforms: []string{"linux/amd64"}},
+			expectErr: true,
+		}, {
+			desc:      "Archive format requires the registry client",
+			flags:     imagesFlags{imageClient: imageClientDocker, dryRun: true, archiveFormat: image.ArchiveFormatOCI},
+			expectErr: true,
+		}, {
+			desc:      "Unknown client",
+			flags:     imagesFlags{imageClient: "podman"},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client, err := newImageClient(tc.flags)
+			switch {
+			case err != nil && !tc.expectErr:
+				t.Fatalf("Expected no error but got %v", err)
+			case err == nil && tc.expectErr:
+				t.Fatalf("Expected error but got client %T", client)
+			case err != nil:
+				return
+			}
+
+			if rc, ok := client.(image.RegistryClient); ok {
+				if rc.ArchiveFormat != tc.expect.(image.RegistryClient).ArchiveFormat {
+					t.Errorf("Expected archive format %v but got %v", tc.expect.(image.RegistryClient).ArchiveFormat, rc.ArchiveFormat)
+				}
+				if rc.AllowHostExecution != tc.expect.(image.RegistryClient).AllowHostExecution {
+					t.Errorf("Expected AllowHostExecution %v but got %v", tc.expect.(image.RegistryClient).AllowHostExecution, rc.AllowHostExecution)
+				}
+				return
+			}
+			if fmt.Sprintf("%T", client) != fmt.Sprintf("%T", tc.expect) {
+				t.Errorf("Expected %T but got %T", tc.expect, client)
+			}
+		})
+	}
+}
+
+func TestGetNodePlatforms(t *testing.T) {
+	node := func(name, os, arch string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{OperatingSystem: os, Architecture: arch}},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		node("a", "linux", "arm64"),
+		node("b", "linux", "amd64"),
+		node("c", "linux", "arm64"),
+		node("d", "windows", "amd64"),
+	)
+	got, err := getNodePlatforms(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"linux/amd64", "linux/arm64", "windows/amd64"}; !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %v but got %v", expect, got)
+	}
+}
+
+func TestReportPlatforms(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "http://")
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = cfg.DeepCopy()
+	cfg.OS, cfg.Architecture = "linux", "amd64"
+	if img, err = mutate.ConfigFile(img, cfg); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(host + "/conformance:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := image.NewRegistryClient("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs := []image.TagPair{{Src: "registry.k8s.io/conformance:v1", Dst: host + "/conformance:v1"}}
+
+	var out bytes.Buffer
+	errs := reportPlatforms(&out, pairs, []string{"linux/amd64", "linux/arm64"}, client.(image.RegistryClient), true)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "linux/arm64") {
+		t.Errorf("Expected an error for the missing arm64 platform but got %v", errs)
+	}
+	if !strings.Contains(out.String(), host+"/conformance:v1   linux/amd64   "+d.String()) {
+		t.Errorf("Expected the digest of the amd64 platform to be reported but got %q", out.String())
+	}
+
+	if errs := reportPlatforms(io.Discard, pairs, []string{"linux/amd64", "linux/arm64"}, client.(image.RegistryClient), false); len(errs) > 0 {
+		t.Errorf("Expected the missing platform to only be a warning but got %v", errs)
+	}
+	if errs := reportPlatforms(io.Discard, pairs, []string{"linux/amd64"}, client.(image.RegistryClient), true); len(errs) > 0 {
+		t.Errorf("Expected no errors but got %v", errs)
+	}
+}
+
+func TestWriteInventory(t *testing.T) {
+	items := []image.InventoryItem{
+		{
+			Image:          "registry.k8s.io/conformance:v1.27.1",
+			Digest:         "sha256:aaa",
+			Platform:       "linux/amd64",
+			PlatformDigest: "sha256:bbb",
+			Size:           1024,
+			Created:        time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			SBOMType:       "https://spdx.dev/Document",
+			SBOMDigest:     "sha256:ccc",
+			SBOM:           json.RawMessage(`{"spdxVersion":"SPDX-2.3"}`),
+		},
+	}
+
+	var csvOut bytes.Buffer
+	if err := writeInventory(&csvOut, items, inventoryFormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	expect := "image,digest,platform,platform_digest,size,created,sbom_type,sbom_digest\n" +
+		"registry.k8s.io/conformance:v1.27.1,sha256:aaa,linux/amd64,sha256:bbb,1024,2026-01-02T03:04:05Z,https://spdx.dev/Document,sha256:ccc\n"
+	if csvOut.String() != expect {
+		t.Errorf("Expected CSV %q but got %q", expect, csvOut.String())
+	}
+
+	var jsonOut bytes.Buffer
+	if err := writeInventory(&jsonOut, items, inventoryFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var got []image.InventoryItem
+	if err := json.Unmarshal(jsonOut.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !got[0].Created.Equal(items[0].Created) {
+		t.Fatalf("Expected the item to round trip through JSON but got %+v", got)
+	}
+	var sbom bytes.Buffer
+	if err := json.Compact(&sbom, got[0].SBOM); err != nil {
+		t.Fatal(err)
+	}
+	if sbom.String() != string(items[0].SBOM) {
+		t.Errorf("Expected SBOM %s but got %s", items[0].SBOM, sbom.String())
+	}
+}
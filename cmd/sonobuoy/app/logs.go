@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -37,6 +38,12 @@ type logFlags struct {
 	follow     bool
 	plugin     string
 	kubeconfig Kubeconfig
+	json       bool
+	since      time.Duration
+	tail       int64
+	container  string
+	node       string
+	previous   bool
 }
 
 func NewCmdLogs() *cobra.Command {
@@ -55,9 +62,38 @@ func NewCmdLogs() *cobra.Command {
 	AddKubeconfigFlag(&f.kubeconfig, cmd.Flags())
 	AddNamespaceFlag(&f.namespace, cmd.Flags())
 	cmd.Flags().StringVarP(&f.plugin, pluginFlag, "p", "", "Show logs only for a specific plugin. If 'sonobuoy' is provided, only shows the aggregator logs.")
+	cmd.Flags().BoolVar(
+		&f.json, "json", false,
+		"Write each log line as a JSON object including the namespace, pod, container, plugin and node it came from.",
+	)
+	cmd.Flags().DurationVar(&f.since, "since", 0, "Only show logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all logs.")
+	cmd.Flags().Int64Var(&f.tail, "tail", -1, "Lines of recent logs to show from each container. Defaults to all logs.")
+	cmd.Flags().StringVar(&f.container, "container", "", "Show logs only for containers with the given name (e.g. sonobuoy-worker).")
+	cmd.Flags().StringVar(&f.node, "node", "", "Show logs only for pods running on the given node. Useful for daemonset plugins.")
+	cmd.Flags().BoolVar(&f.previous, "previous", false, "Show the logs of the previous instance of each container, if it was restarted.")
 	return cmd
 }
 
+// logConfig converts the flags into the client configuration.
+func (f *logFlags) logConfig() *client.LogConfig {
+	logConfig := client.NewLogConfig()
+	logConfig.Namespace = f.namespace
+	logConfig.Follow = f.follow
+	logConfig.Plugin = f.plugin
+	logConfig.Since = f.since
+	logConfig.Container = f.container
+	logConfig.Node = f.node
+	logConfig.Previous = f.previous
+	if f.json {
+		logConfig.Format = client.LogFormatJSON
+	}
+	if f.tail >= 0 {
+		tail := f.tail
+		logConfig.TailLines = &tail
+	}
+	return logConfig
+}
+
 func getLogs(f *logFlags) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
 		sbc, err := getSonobuoyClientFromKubecfg(f.kubeconfig)
@@ -66,10 +102,7 @@ func getLogs(f *logFlags) func(cmd *cobra.Command, args []string) {
 			os.Exit(1)
 		}
 
-		logConfig := client.NewLogConfig()
-		logConfig.Namespace = f.namespace
-		logConfig.Follow = f.follow
-		logConfig.Plugin = f.plugin
+		logConfig := f.logConfig()
 
 		logreader, err := sbc.LogReader(logConfig)
 		if err != nil {
@@ -73,14 +73,22 @@ func NewCmdPlugin() *cobra.Command {
 		},
 	}
 
+	var requireSigned bool
 	installCmd := &cobra.Command{
 		Use:   "install <save-as-filename> <source filename or URL>",
 		Short: "Install a plugin so that it can be run via just its filename rather than a full path or URL.",
-		Args:  cobra.ExactArgs(2),
+		Long: "Install a plugin so that it can be run via just its filename rather than a full path or URL.\n\n" +
+			"Plugins from the plugin repositories are installed with a single argument in the form [repo/]name[@version], " +
+			"e.g. `sonobuoy plugin install myrepo/myplugin@1.2.0`. The latest version is installed if no version is given.",
+		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return installPlugin(getPluginCacheLocation(), filenameFromArg(args[0], ".yaml"), args[1])
+			if len(args) == 1 {
+				return installPluginFromRepo(os.Stdout, getPluginCacheLocation(), args[0], requireSigned)
+			}
+			return installPlugin(getPluginCacheLocation(), filenameFromArg(args[0], ".yaml"), args[1], requireSigned)
 		},
 	}
+	AddRequireSignedPluginsFlag(&requireSigned, installCmd.Flags())
 
 	uninstallCmd := &cobra.Command{
 		Use:   "uninstall <plugin filename>",
@@ -92,6 +100,7 @@ func NewCmdPlugin() *cobra.Command {
 	}
 
 	cmd.AddCommand(listCmd, showCmd, installCmd, uninstallCmd)
+	cmd.AddCommand(newCmdPluginRepo(), newCmdPluginSearch(), newCmdPluginUpgrade(), newCmdPluginLint(), newCmdPluginTest())
 
 	return cmd
 }
@@ -148,6 +157,9 @@ func listInstalledPlugins(installedDir string) error {
 		}
 		fmt.Printf("%vRun as: %v\nFilename: %v\nPlugin name (in aggregator): %v\nSource URL: %v\nDescription: %v\n",
 			prefix, strings.TrimSuffix(filepath.Base(filename), ".yaml"), filename, p.SonobuoyConfig.PluginName, p.SonobuoyConfig.SourceURL, p.SonobuoyConfig.Description)
+		if len(p.SonobuoyConfig.Version) > 0 {
+			fmt.Printf("Version: %v\n", p.SonobuoyConfig.Version)
+		}
 		first = false
 	}
 
@@ -230,7 +242,7 @@ func showInstalledPlugin(installedDir, reqPluginFile string) error {
 // installation directory with the given filename. If too many or too few plugins
 // are loaded, errors are returned. The returned string is a human-readable description
 // of the action taken.
-func installPlugin(installedDir, filename, src string) error {
+func installPlugin(installedDir, filename, src string, requireSigned bool) error {
 	if len(installedDir) == 0 {
 		return errors.New("unable to install plugins; installation directory unavailable")
 	}
@@ -244,6 +256,11 @@ func installPlugin(installedDir, filename, src string) error {
 	if err := pl.Set(src); err != nil {
 		return err
 	}
+	if requireSigned {
+		if err := pl.checkSigned(); err != nil {
+			return err
+		}
+	}
 
 	if len(pl.StaticPlugins) > 1 {
 		return fmt.Errorf("may only install one plugin at a time, found %v", len(pl.StaticPlugins))
@@ -259,6 +276,10 @@ func installPlugin(installedDir, filename, src string) error {
 	if err := os.WriteFile(newPath, yaml, 0666); err != nil {
 		return err
 	}
+	// Plugins from files are never verified, only those from URLs with a trusted signature.
+	if err := recordPluginVerification(installedDir, filename, isURL(src) && len(pl.unverified) == 0); err != nil {
+		return err
+	}
 	fmt.Printf("Installed plugin %v into file %v from source %v\n", pl.StaticPlugins[0].Spec.Name, newPath, src)
 	return nil
 }
@@ -278,6 +299,9 @@ func uninstallPlugin(installedDir, filename string) error {
 	if err := os.Remove(pluginPath); err != nil {
 		return errors.Wrapf(err, "failed to uninstall plugin file %v", pluginPath)
 	}
+	if err := recordPluginVerification(installedDir, filename, false); err != nil {
+		return err
+	}
 
 	fmt.Printf("Uninstalled plugin file %v\n", pluginPath)
 	return nil
@@ -0,0 +1,106 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/lint"
+)
+
+// pluginNamePath is the path of the plugin name in lint diagnostics. Plugins loaded by gen/run
+// may be renamed so their names are validated once the plugins are loaded instead.
+const pluginNamePath = "sonobuoy-config.plugin-name"
+
+func newCmdPluginLint() *cobra.Command {
+	var printSchema bool
+	cmd := &cobra.Command{
+		Use:   "lint <plugin filename or URL>...",
+		Short: "Check plugin definitions for mistakes such as unknown fields or drivers",
+		Long: "Check plugin definitions for mistakes such as unknown fields or drivers.\n\n" +
+			"Definitions are validated against the plugin JSON Schema, which doesn't allow unknown fields, " +
+			"and checked for problems which would otherwise only surface once the plugin is running. " +
+			"Problems are reported with the line and column of the field. The same checks are made when " +
+			"plugins are loaded by gen and run.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if printSchema {
+				return printPluginSchema(os.Stdout)
+			}
+			if len(args) == 0 {
+				return errors.New("requires at least one plugin filename or URL")
+			}
+			return lintPlugins(os.Stdout, args)
+		},
+	}
+	cmd.Flags().BoolVar(&printSchema, "print-schema", false, "If true, print the JSON Schema of plugin definitions instead of linting plugins.")
+	return cmd
+}
+
+func printPluginSchema(w io.Writer) error {
+	b, err := json.MarshalIndent(lint.PluginSchema(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode plugin schema")
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+// lintPlugins prints the problems found in each of the plugin files or URLs, returning an error
+// if any of them are errors.
+func lintPlugins(w io.Writer, sources []string) error {
+	errCount := 0
+	for _, source := range sources {
+		var b []byte
+		var err error
+		if isURL(source) {
+			b, err = fetchURL(source)
+		} else {
+			b, err = os.ReadFile(source)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read plugin %v", source)
+		}
+
+		for _, d := range lint.Lint(b) {
+			fmt.Fprintf(w, "%v:%v\n", source, d)
+			if d.Severity == lint.SeverityError {
+				errCount++
+			}
+		}
+	}
+	if errCount > 0 {
+		return fmt.Errorf("found %v errors in plugin definitions", errCount)
+	}
+	return nil
+}
+
+// warnPluginLint logs the problems found in the plugin definition from the source as warnings.
+// Only `sonobuoy plugin lint` fails because of them so that plugins which worked before keep working.
+func warnPluginLint(source string, b []byte) {
+	for _, d := range lint.Lint(b) {
+		if d.Path == pluginNamePath {
+			continue
+		}
+		logrus.Warnf("%v:%v (see `sonobuoy plugin lint`)", source, d)
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package app
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,6 +27,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/vmware-tanzu/sonobuoy/pkg/client"
 	"github.com/vmware-tanzu/sonobuoy/pkg/features"
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
 
@@ -53,11 +55,17 @@ type pluginList struct {
 	// Don't want to do this too early or else it happens before flags like `--level=trace` have been
 	// parsed, leading to misleading logs.
 	initInstallDir bool
+
+	// unverified lists the URLs of plugins, or the files of installed plugins, which were loaded
+	// without a signature from a trusted key. Flags may be parsed in any order so requiring signed
+	// plugins is enforced after parsing.
+	unverified []string
 }
 
 const (
 	pluginE2E         = "e2e"
 	pluginSystemdLogs = "systemd-logs"
+	pluginQuery       = "query"
 	fileExtensionYAML = ".yaml"
 
 	renameAsSeperator = "@"
@@ -92,7 +100,7 @@ func (p *pluginList) Set(str string) error {
 		renameAs = strSlice[1]
 	}
 
-	// Load first from cache, then special cases (e2e/systemd-logs), then local file.
+	// Load first from cache, then special cases (e2e/systemd-logs/query), then local file.
 	if p.GetInstallDir() != "" {
 		handled, err := p.loadPluginsFromInstalled(str, renameAs)
 		if handled {
@@ -114,6 +122,12 @@ func (p *pluginList) Set(str string) error {
 		if renameAs != "" {
 			return fmt.Errorf("cannot use @ renaming of plugins not loaded from file or URL")
 		}
+	case pluginQuery:
+		m := client.QueryManifest()
+		if renameAs != "" {
+			m.SonobuoyConfig.PluginName = renameAs
+		}
+		p.StaticPlugins = append(p.StaticPlugins, m)
 	default:
 		if isURL(str) {
 			return p.loadSinglePluginFromURL(str, renameAs)
@@ -135,13 +149,21 @@ func (p *pluginList) loadPluginsFromInstalled(str, renameAs string) (handled boo
 		return false, nil
 	}
 
-	m, err := loadPlugin(p.InstallDir, filenameFromArg(str, ".yaml"))
+	filename := filenameFromArg(str, ".yaml")
+	m, err := loadPlugin(p.InstallDir, filename)
 	if isNotExist(err) {
 		return false, err
 	}
 	if err != nil {
 		return true, err
 	}
+	verified, err := installedPluginVerified(p.InstallDir, filename)
+	if err != nil {
+		return true, err
+	}
+	if !verified {
+		p.unverified = append(p.unverified, filepath.Join(p.InstallDir, filename))
+	}
 	if len(renameAs) > 0 {
 		m.SonobuoyConfig.PluginName = renameAs
 	}
@@ -193,11 +215,33 @@ func (p *pluginList) loadSinglePluginFromURL(url, renameAs string) error {
 	if err != nil {
 		return errors.Wrapf(err, "unable to GET URL %q", url)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode > 399 {
 		return fmt.Errorf("unexpected HTTP response code %v", resp.StatusCode)
 	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read plugin from URL %q", url)
+	}
 
-	return errors.Wrapf(p.loadSinglePlugin(resp.Body, renameAs), "loading plugin from URL %q", url)
+	verified, err := verifyPluginSignature(url, b)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		p.unverified = append(p.unverified, url)
+	}
+
+	return errors.Wrapf(p.loadSinglePlugin(io.NopCloser(bytes.NewReader(b)), url, renameAs), "loading plugin from URL %q", url)
+}
+
+// checkSigned returns an error if any plugin was loaded from a URL without a signature from a
+// trusted key.
+func (p *pluginList) checkSigned() error {
+	if len(p.unverified) > 0 {
+		return unsignedPluginError(p.unverified[0])
+	}
+	return nil
 }
 
 // loadSinglePluginFromFile loads a single plugin located at the given path.
@@ -206,16 +250,20 @@ func (p *pluginList) loadSinglePluginFromFile(filepath, renameAs string) error {
 	if err != nil {
 		return errors.Wrapf(err, "unable to read file %q", filepath)
 	}
-	return errors.Wrapf(p.loadSinglePlugin(f, renameAs), "loading plugin from file %q", filepath)
+	return errors.Wrapf(p.loadSinglePlugin(f, filepath, renameAs), "loading plugin from file %q", filepath)
 }
 
-// loadSinglePlugin reads the data from the reader and loads the plugin.
-func (p *pluginList) loadSinglePlugin(r io.ReadCloser, renameAs string) error {
+// loadSinglePlugin reads the data from the reader and loads the plugin. The source is the file or
+// URL the data is from.
+func (p *pluginList) loadSinglePlugin(r io.ReadCloser, source, renameAs string) error {
 	defer r.Close()
 	b, err := io.ReadAll(r)
 	if err != nil {
 		return errors.Wrap(err, "failed to read data for plugin")
 	}
+	if features.Enabled(features.PluginLinting) {
+		warnPluginLint(source, b)
+	}
 
 	newPlugin, err := loadManifest(b)
 	if err != nil {
@@ -98,9 +98,12 @@ func TestSetPluginList(t *testing.T) {
 			desc:  "loading from url",
 			input: ts.URL,
 			list:  pluginList{},
-			expect: pluginList{StaticPlugins: []*manifest.Manifest{
-				{SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "test"}},
-			}},
+			expect: pluginList{
+				StaticPlugins: []*manifest.Manifest{
+					{SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "test"}},
+				},
+				unverified: []string{ts.URL},
+			},
 		},
 	}
 	for _, tc := range testCases {
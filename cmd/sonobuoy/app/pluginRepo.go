@@ -0,0 +1,559 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+	kuberuntime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// pluginReposFile lists the plugin repositories which have been added. It is not YAML so that
+	// it isn't loaded as a plugin from the installation directory.
+	pluginReposFile = "repositories.json"
+)
+
+// pluginIndex is the index file of a plugin repository listing the versions of each plugin.
+type pluginIndex struct {
+	APIVersion string                        `json:"apiVersion"`
+	Plugins    map[string][]pluginIndexEntry `json:"plugins"`
+}
+
+// pluginIndexEntry is a single version of a plugin in a repository.
+type pluginIndexEntry struct {
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+
+	// URL of the plugin definition; relative URLs are relative to the index.
+	URL string `json:"url"`
+
+	// SHA256 is the hex-encoded checksum of the plugin definition.
+	SHA256 string `json:"sha256"`
+}
+
+// pluginRepo is a plugin repository which has been added.
+type pluginRepo struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type pluginRepos struct {
+	Repositories []pluginRepo `json:"repositories"`
+}
+
+// pluginRef refers to a plugin in a repository in the form [repo/]name[@version].
+type pluginRef struct {
+	repo    string
+	name    string
+	version string
+}
+
+func parsePluginRef(s string) (pluginRef, error) {
+	var ref pluginRef
+	name, v, _ := strings.Cut(s, "@")
+	if i := strings.Index(name, "/"); i >= 0 {
+		ref.repo, name = name[:i], name[i+1:]
+	}
+	ref.name, ref.version = name, v
+	if len(ref.name) == 0 || strings.Contains(ref.name, "/") {
+		return ref, fmt.Errorf("invalid plugin %q, expected the form [repo/]name[@version]", s)
+	}
+	return ref, nil
+}
+
+func newCmdPluginRepo() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repo",
+		Short: "Manage the plugin repositories to search and install plugins from",
+	}
+
+	addCmd := &cobra.Command{
+		Use:   "add <name> <index URL>",
+		Short: "Add a plugin repository",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return addPluginRepo(os.Stdout, getPluginCacheLocation(), args[0], args[1])
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the plugin repositories",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repos, err := loadPluginRepos(getPluginCacheLocation())
+			if err != nil {
+				return err
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tURL")
+			for _, r := range repos.Repositories {
+				fmt.Fprintf(tw, "%v\t%v\n", r.Name, r.URL)
+			}
+			return tw.Flush()
+		},
+	}
+
+	removeCmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a plugin repository. Plugins installed from it are not uninstalled.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return removePluginRepo(getPluginCacheLocation(), args[0])
+		},
+	}
+
+	cmd.AddCommand(addCmd, listCmd, removeCmd)
+	return cmd
+}
+
+func newCmdPluginSearch() *cobra.Command {
+	return &cobra.Command{
+		Use:   "search [term]",
+		Short: "Search the plugin repositories for plugins whose name or description contains the term",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			term := ""
+			if len(args) > 0 {
+				term = args[0]
+			}
+			return searchPlugins(os.Stdout, getPluginCacheLocation(), term)
+		},
+	}
+}
+
+func newCmdPluginUpgrade() *cobra.Command {
+	var requireSigned bool
+	cmd := &cobra.Command{
+		Use:   "upgrade [plugin filename...]",
+		Short: "Upgrade installed plugins to the latest version in the repository they were installed from",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filenames := []string{}
+			for _, arg := range args {
+				filenames = append(filenames, filenameFromArg(arg, ".yaml"))
+			}
+			return upgradePlugins(os.Stdout, getPluginCacheLocation(), filenames, requireSigned)
+		},
+	}
+	AddRequireSignedPluginsFlag(&requireSigned, cmd.Flags())
+	return cmd
+}
+
+func loadPluginRepos(installedDir string) (*pluginRepos, error) {
+	if len(installedDir) == 0 {
+		return nil, errors.New("unable to load plugin repositories; installation directory unavailable")
+	}
+	repos := &pluginRepos{}
+	b, err := os.ReadFile(filepath.Join(installedDir, pluginReposFile))
+	switch {
+	case os.IsNotExist(err):
+		return repos, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to read plugin repositories")
+	}
+	if err := json.Unmarshal(b, repos); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %v", pluginReposFile)
+	}
+	return repos, nil
+}
+
+func savePluginRepos(installedDir string, repos *pluginRepos) error {
+	b, err := json.MarshalIndent(repos, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(installedDir, pluginReposFile), b, 0666)
+}
+
+// addPluginRepo adds the repository after checking that its index can be loaded.
+func addPluginRepo(w io.Writer, installedDir, name, indexURL string) error {
+	repos, err := loadPluginRepos(installedDir)
+	if err != nil {
+		return err
+	}
+	if len(name) == 0 || strings.ContainsAny(name, "/@") {
+		return fmt.Errorf("invalid repository name %q; it may not contain '/' or '@'", name)
+	}
+	for _, r := range repos.Repositories {
+		if r.Name == name {
+			return fmt.Errorf("repository %v already exists with URL %v", name, r.URL)
+		}
+	}
+
+	idx, err := fetchPluginIndex(indexURL)
+	if err != nil {
+		return err
+	}
+	repos.Repositories = append(repos.Repositories, pluginRepo{Name: name, URL: indexURL})
+	if err := savePluginRepos(installedDir, repos); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Added repository %v with %v plugins\n", name, len(idx.Plugins))
+	return nil
+}
+
+func removePluginRepo(installedDir, name string) error {
+	repos, err := loadPluginRepos(installedDir)
+	if err != nil {
+		return err
+	}
+	for i, r := range repos.Repositories {
+		if r.Name == name {
+			repos.Repositories = append(repos.Repositories[:i], repos.Repositories[i+1:]...)
+			return savePluginRepos(installedDir, repos)
+		}
+	}
+	return fmt.Errorf("repository %v not found", name)
+}
+
+// fetchURL returns the body of the URL, failing on any non-successful response.
+func fetchURL(u string) ([]byte, error) {
+	c := http.Client{
+		Timeout: 10 * time.Second,
+	}
+	resp, err := c.Get(u)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to GET URL %q", u)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 399 {
+		return nil, fmt.Errorf("unexpected HTTP response code %v from %q", resp.StatusCode, u)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func fetchPluginIndex(indexURL string) (*pluginIndex, error) {
+	b, err := fetchURL(indexURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch plugin index")
+	}
+	idx := &pluginIndex{}
+	if err := yaml.Unmarshal(b, idx); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse plugin index %q", indexURL)
+	}
+	for name, entries := range idx.Plugins {
+		for _, e := range entries {
+			if _, err := version.NewVersion(e.Version); err != nil {
+				return nil, errors.Wrapf(err, "invalid version of plugin %v in index %q", name, indexURL)
+			}
+		}
+	}
+	return idx, nil
+}
+
+// find returns the latest version of the plugin in the index, or the given version if set.
+func (idx *pluginIndex) find(name, v string) (pluginIndexEntry, error) {
+	entries, ok := idx.Plugins[name]
+	if !ok || len(entries) == 0 {
+		return pluginIndexEntry{}, fmt.Errorf("plugin %v not found", name)
+	}
+	if len(v) > 0 {
+		want, err := version.NewVersion(v)
+		if err != nil {
+			return pluginIndexEntry{}, errors.Wrapf(err, "invalid version %q", v)
+		}
+		for _, e := range entries {
+			if have, _ := version.NewVersion(e.Version); have.Equal(want) {
+				return e, nil
+			}
+		}
+		return pluginIndexEntry{}, fmt.Errorf("version %v of plugin %v not found", v, name)
+	}
+
+	latest := entries[0]
+	for _, e := range entries[1:] {
+		if newerVersion(latest.Version, e.Version) {
+			latest = e
+		}
+	}
+	return latest, nil
+}
+
+// newerVersion returns true if candidate is a newer version than current. Plugins without a
+// version are considered older than any version.
+func newerVersion(current, candidate string) bool {
+	c, err := version.NewVersion(candidate)
+	if err != nil {
+		return false
+	}
+	cur, err := version.NewVersion(current)
+	if err != nil {
+		return true
+	}
+	return c.GreaterThan(cur)
+}
+
+// searchPlugins prints the latest version of every plugin in the repositories matching the term.
+func searchPlugins(w io.Writer, installedDir, term string) error {
+	repos, err := loadPluginRepos(installedDir)
+	if err != nil {
+		return err
+	}
+	if len(repos.Repositories) == 0 {
+		return errors.New("no plugin repositories; add one with `sonobuoy plugin repo add`")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVERSION\tDESCRIPTION")
+	term = strings.ToLower(term)
+	for _, r := range repos.Repositories {
+		idx, err := fetchPluginIndex(r.URL)
+		if err != nil {
+			return errors.Wrapf(err, "repository %v", r.Name)
+		}
+		names := []string{}
+		for name := range idx.Plugins {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			e, err := idx.find(name, "")
+			if err != nil {
+				continue
+			}
+			if !strings.Contains(strings.ToLower(name), term) && !strings.Contains(strings.ToLower(e.Description), term) {
+				continue
+			}
+			fmt.Fprintf(tw, "%v/%v\t%v\t%v\n", r.Name, name, e.Version, e.Description)
+		}
+	}
+	return tw.Flush()
+}
+
+// installPluginFromRepo installs the referenced plugin into the installation directory as
+// <name>.yaml. Without a repository, each one is searched and the plugin must only be in one.
+func installPluginFromRepo(w io.Writer, installedDir, refString string, requireSigned bool) error {
+	ref, err := parsePluginRef(refString)
+	if err != nil {
+		return err
+	}
+	repos, err := loadPluginRepos(installedDir)
+	if err != nil {
+		return err
+	}
+
+	var found []pluginRepo
+	for _, r := range repos.Repositories {
+		if len(ref.repo) > 0 && r.Name != ref.repo {
+			continue
+		}
+		idx, err := fetchPluginIndex(r.URL)
+		if err != nil {
+			return errors.Wrapf(err, "repository %v", r.Name)
+		}
+		if _, ok := idx.Plugins[ref.name]; ok {
+			found = append(found, r)
+		}
+	}
+	switch {
+	case len(found) == 0 && len(ref.repo) > 0:
+		return fmt.Errorf("plugin %v not found in repository %v", ref.name, ref.repo)
+	case len(found) == 0:
+		return fmt.Errorf("plugin %v not found in any repository", ref.name)
+	case len(found) > 1:
+		return fmt.Errorf("plugin %v is in repositories %v and %v; specify one as <repo>/%v", ref.name, found[0].Name, found[1].Name, ref.name)
+	}
+
+	m, verified, err := downloadRepoPlugin(found[0].URL, ref.name, ref.version, requireSigned)
+	if err != nil {
+		return err
+	}
+	filename := filenameFromArg(ref.name, ".yaml")
+	newPath := filepath.Join(installedDir, filename)
+	if err := writePluginFile(newPath, m); err != nil {
+		return err
+	}
+	if err := recordPluginVerification(installedDir, filename, verified); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Installed plugin %v version %v from repository %v into file %v\n", ref.name, m.SonobuoyConfig.Version, found[0].Name, newPath)
+	return nil
+}
+
+// downloadRepoPlugin fetches the plugin from the index and verifies its checksum and signature,
+// returning whether it was signed by a trusted key. The source URL of the plugin is set to the
+// index, with the plugin name as the fragment, for upgrades.
+func downloadRepoPlugin(indexURL, name, v string, requireSigned bool) (*manifest.Manifest, bool, error) {
+	idx, err := fetchPluginIndex(indexURL)
+	if err != nil {
+		return nil, false, err
+	}
+	entry, err := idx.find(name, v)
+	if err != nil {
+		return nil, false, err
+	}
+
+	base, err := url.Parse(indexURL)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "invalid index URL %q", indexURL)
+	}
+	u, err := base.Parse(entry.URL)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "invalid URL for version %v of plugin %v", entry.Version, name)
+	}
+	b, err := fetchURL(u.String())
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to fetch version %v of plugin %v", entry.Version, name)
+	}
+	sum := sha256.Sum256(b)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, entry.SHA256) {
+		return nil, false, fmt.Errorf("checksum of version %v of plugin %v is %v but the index lists %v", entry.Version, name, got, entry.SHA256)
+	}
+	verified, err := verifyPluginSignature(u.String(), b)
+	if err != nil {
+		return nil, false, err
+	}
+	if requireSigned && !verified {
+		return nil, false, unsignedPluginError(u.String())
+	}
+
+	m, err := loadManifest(b)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to load version %v of plugin %v", entry.Version, name)
+	}
+	source := *base
+	source.Fragment = name
+	m.SonobuoyConfig.SourceURL = source.String()
+	m.SonobuoyConfig.Version = entry.Version
+	if len(m.SonobuoyConfig.Description) == 0 {
+		m.SonobuoyConfig.Description = entry.Description
+	}
+	return m, verified, nil
+}
+
+func writePluginFile(path string, m *manifest.Manifest) error {
+	b, err := kuberuntime.Encode(manifest.Encoder, m)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode plugin")
+	}
+	return os.WriteFile(path, b, 0666)
+}
+
+// upgradePlugins upgrades the given installed plugin files (or all of them) which were installed
+// from a repository, based on their source URL, to the latest version in the repository.
+func upgradePlugins(w io.Writer, installedDir string, filenames []string, requireSigned bool) error {
+	if len(installedDir) == 0 {
+		return errors.New("unable to upgrade plugins; installation directory unavailable")
+	}
+	installed, _ := loadPlugins(installedDir)
+	paths := []string{}
+	if len(filenames) == 0 {
+		// Only the plugins installed from a repository can be upgraded; the others are skipped.
+		for path, m := range installed {
+			if !installedFromRepo(m) {
+				logrus.Debugf("Skipping plugin %v since it wasn't installed from a plugin repository", path)
+				continue
+			}
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+	}
+	for _, f := range filenames {
+		path := filepath.Join(installedDir, f)
+		if _, ok := installed[path]; !ok {
+			return fmt.Errorf("failed to find plugin file %v within directory %v", f, installedDir)
+		}
+		paths = append(paths, path)
+	}
+
+	var firstErr error
+	for _, path := range paths {
+		filename, err := filepath.Rel(installedDir, path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find plugin file %v within directory %v", path, installedDir)
+		}
+		upgraded, err := upgradePlugin(installedDir, filename, installed[path], requireSigned)
+		switch {
+		case err != nil:
+			fmt.Fprintf(w, "%v: %v\n", filename, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		case len(upgraded) > 0:
+			fmt.Fprintf(w, "%v: upgraded from version %v to %v\n", filename, installed[path].SonobuoyConfig.Version, upgraded)
+		default:
+			fmt.Fprintf(w, "%v: up to date\n", filename)
+		}
+	}
+	if firstErr != nil {
+		return errors.Wrap(firstErr, "failed to upgrade plugins")
+	}
+	return nil
+}
+
+// installedFromRepo returns true if the plugin was installed from a plugin repository.
+func installedFromRepo(m *manifest.Manifest) bool {
+	source, err := url.Parse(m.SonobuoyConfig.SourceURL)
+	return err == nil && len(m.SonobuoyConfig.SourceURL) > 0 && len(source.Fragment) > 0
+}
+
+// upgradePlugin replaces the plugin file with the latest version in its repository if it is newer,
+// returning the new version. Plugins not installed from a repository are an error.
+func upgradePlugin(installedDir, filename string, m *manifest.Manifest, requireSigned bool) (string, error) {
+	if !installedFromRepo(m) {
+		return "", errors.New("not installed from a plugin repository")
+	}
+	source, err := url.Parse(m.SonobuoyConfig.SourceURL)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	name := source.Fragment
+	source.Fragment = ""
+
+	idx, err := fetchPluginIndex(source.String())
+	if err != nil {
+		return "", err
+	}
+	latest, err := idx.find(name, "")
+	if err != nil {
+		return "", err
+	}
+	if !newerVersion(m.SonobuoyConfig.Version, latest.Version) {
+		return "", nil
+	}
+
+	newer, verified, err := downloadRepoPlugin(source.String(), name, latest.Version, requireSigned)
+	if err != nil {
+		return "", err
+	}
+	if err := writePluginFile(filepath.Join(installedDir, filename), newer); err != nil {
+		return "", err
+	}
+	if err := recordPluginVerification(installedDir, filename, verified); err != nil {
+		return "", err
+	}
+	return latest.Version, nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/loader"
+)
+
+// testPluginRepo serves an index and plugin files which can be changed between requests.
+type testPluginRepo struct {
+	files        map[string][]byte
+	descriptions map[string]string
+}
+
+func (r *testPluginRepo) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	b, ok := r.files[strings.TrimPrefix(req.URL.Path, "/")]
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	w.Write(b)
+}
+
+// addPlugin adds a version of the plugin to the repository and regenerates the index.
+func (r *testPluginRepo) addPlugin(name, version, description string) {
+	file := fmt.Sprintf("plugins/%v-%v.yaml", name, version)
+	r.descriptions[name] = description
+	r.files[file] = []byte(fmt.Sprintf("sonobuoy-config:\n  driver: Job\n  plugin-name: %v\nspec:\n  image: example.com/%v:%v\n  name: plugin\n", name, name, version))
+
+	index := "apiVersion: v1\nplugins:\n"
+	entries := map[string][]string{}
+	for f, b := range r.files {
+		if !strings.HasPrefix(f, "plugins/") {
+			continue
+		}
+		base := strings.TrimSuffix(strings.TrimPrefix(f, "plugins/"), ".yaml")
+		i := strings.LastIndex(base, "-")
+		sum := sha256.Sum256(b)
+		entries[base[:i]] = append(entries[base[:i]], fmt.Sprintf("  - version: %v\n    description: %v\n    url: %v\n    sha256: %v\n", base[i+1:], r.descriptions[base[:i]], f, hex.EncodeToString(sum[:])))
+	}
+	for n, e := range entries {
+		index += fmt.Sprintf("  %v:\n%v", n, strings.Join(e, ""))
+	}
+	r.files["index.yaml"] = []byte(index)
+}
+
+func newTestPluginRepo(t *testing.T) (*testPluginRepo, string) {
+	r := &testPluginRepo{files: map[string][]byte{}, descriptions: map[string]string{}}
+	s := httptest.NewServer(r)
+	t.Cleanup(s.Close)
+	return r, s.URL + "/index.yaml"
+}
+
+func TestParsePluginRef(t *testing.T) {
+	testCases := []struct {
+		input     string
+		expect    pluginRef
+		expectErr bool
+	}{
+		{input: "foo", expect: pluginRef{name: "foo"}},
+		{input: "repo/foo", expect: pluginRef{repo: "repo", name: "foo"}},
+		{input: "repo/foo@1.2.0", expect: pluginRef{repo: "repo", name: "foo", version: "1.2.0"}},
+		{input: "foo@1.2.0", expect: pluginRef{name: "foo", version: "1.2.0"}},
+		{input: "repo/", expectErr: true},
+		{input: "a/b/c", expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := parsePluginRef(tc.input)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected error but got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.expect {
+				t.Errorf("Expected %+v but got %+v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestPluginRepoInstallAndUpgrade(t *testing.T) {
+	repo, indexURL := newTestPluginRepo(t)
+	repo.addPlugin("cis", "1.0.0", "CIS benchmark")
+	repo.addPlugin("cis", "1.2.0", "CIS benchmark")
+	repo.addPlugin("netcheck", "0.1.0", "Network checks")
+	dir := t.TempDir()
+
+	var out bytes.Buffer
+	if err := addPluginRepo(&out, dir, "test", indexURL); err != nil {
+		t.Fatal(err)
+	}
+	if err := addPluginRepo(&out, dir, "test", indexURL); err == nil {
+		t.Error("Expected an error adding the same repository twice")
+	}
+
+	out.Reset()
+	if err := searchPlugins(&out, dir, "bench"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "test/cis") || !strings.Contains(lines[1], "1.2.0") {
+		t.Errorf("Expected only the latest version of test/cis but got:\n%v", out.String())
+	}
+
+	if err := installPluginFromRepo(&out, dir, "test/cis@1.0.0", false); err != nil {
+		t.Fatal(err)
+	}
+	m, err := loader.LoadDefinitionFromFile(filepath.Join(dir, "cis.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SonobuoyConfig.Version != "1.0.0" || m.SonobuoyConfig.SourceURL != indexURL+"#cis" || m.Spec.Image != "example.com/cis:1.0.0" {
+		t.Errorf("Expected version 1.0.0 installed from %v but got %+v %v", indexURL, m.SonobuoyConfig, m.Spec.Image)
+	}
+
+	repo.addPlugin("cis", "2.0.0", "CIS benchmark")
+	out.Reset()
+	if err := upgradePlugins(&out, dir, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "cis.yaml: upgraded from version 1.0.0 to 2.0.0\n"; out.String() != expect {
+		t.Errorf("Expected output %q but got %q", expect, out.String())
+	}
+	m, err = loader.LoadDefinitionFromFile(filepath.Join(dir, "cis.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SonobuoyConfig.Version != "2.0.0" || m.Spec.Image != "example.com/cis:2.0.0" {
+		t.Errorf("Expected version 2.0.0 but got %v %v", m.SonobuoyConfig.Version, m.Spec.Image)
+	}
+
+	out.Reset()
+	if err := upgradePlugins(&out, dir, []string{"cis.yaml"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "cis.yaml: up to date\n"; out.String() != expect {
+		t.Errorf("Expected output %q but got %q", expect, out.String())
+	}
+}
+
+func TestPluginRepoErrors(t *testing.T) {
+	repo, indexURL := newTestPluginRepo(t)
+	repo.addPlugin("cis", "1.0.0", "CIS benchmark")
+	dir := t.TempDir()
+	if err := addPluginRepo(&bytes.Buffer{}, dir, "test", indexURL); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		desc      string
+		ref       string
+		modify    func()
+		expectErr string
+	}{
+		{
+			desc:      "Unknown plugin",
+			ref:       "missing",
+			expectErr: "plugin missing not found in any repository",
+		}, {
+			desc:      "Unknown repository",
+			ref:       "other/cis",
+			expectErr: "plugin cis not found in repository other",
+		}, {
+			desc:      "Unknown version",
+			ref:       "cis@9.9.9",
+			expectErr: "version 9.9.9 of plugin cis not found",
+		}, {
+			desc: "Checksum mismatch",
+			ref:  "cis",
+			modify: func() {
+				repo.files["plugins/cis-1.0.0.yaml"] = append(repo.files["plugins/cis-1.0.0.yaml"], []byte("# tampered\n")...)
+			},
+			expectErr: "checksum of version 1.0.0 of plugin cis",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if tc.modify != nil {
+				tc.modify()
+			}
+			err := installPluginFromRepo(&bytes.Buffer{}, dir, tc.ref, false)
+			if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+				t.Errorf("Expected error containing %q but got %v", tc.expectErr, err)
+			}
+		})
+	}
+
+	// Plugins installed some other way can't be upgraded.
+	if err := os.WriteFile(filepath.Join(dir, "local.yaml"), repo.files["plugins/cis-1.0.0.yaml"], 0666); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := upgradePlugins(&out, dir, []string{"local.yaml"}, false); err == nil {
+		t.Error("Expected an error upgrading a plugin not installed from a repository")
+	}
+	if expect := "local.yaml: not installed from a plugin repository\n"; out.String() != expect {
+		t.Errorf("Expected output %q but got %q", expect, out.String())
+	}
+	out.Reset()
+	if err := upgradePlugins(&out, dir, nil, false); err != nil {
+		t.Errorf("Expected plugins not installed from a repository to be skipped but got %v", err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("Expected no output but got %q", out.String())
+	}
+
+	if repos, err := loadPluginRepos(dir); err != nil || !reflect.DeepEqual(repos.Repositories, []pluginRepo{{Name: "test", URL: indexURL}}) {
+		t.Errorf("Expected the test repository but got %+v, %v", repos, err)
+	}
+	if err := removePluginRepo(dir, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if repos, err := loadPluginRepos(dir); err != nil || len(repos.Repositories) != 0 {
+		t.Errorf("Expected no repositories but got %+v, %v", repos, err)
+	}
+}
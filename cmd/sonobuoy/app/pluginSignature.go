@@ -0,0 +1,269 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// trustedKeysDir is the directory within the Sonobuoy directory holding the PEM encoded public
+	// keys which plugin signatures are verified against.
+	trustedKeysDir = "trusted-keys"
+
+	// signatureExtension is appended to the path of a plugin URL to find its detached signature.
+	signatureExtension = ".sig"
+
+	// verifiedPluginsFile records the checksums of the installed plugin files which were signed by a
+	// trusted key when they were installed. It is not YAML so that it isn't loaded as a plugin.
+	verifiedPluginsFile = "verified-plugins.json"
+)
+
+// trustedKey is a public key from the trusted keys directory.
+type trustedKey struct {
+	file string
+	key  crypto.PublicKey
+}
+
+// trustedKeysLocation returns the trusted keys directory of the Sonobuoy directory. Unlike
+// getPluginCacheLocation it does not create the Sonobuoy directory.
+func trustedKeysLocation() string {
+	dir := os.Getenv(SonobuoyDirEnvKey)
+	if len(dir) == 0 {
+		dir = defaultSonobuoyDir
+	}
+	expanded, err := expandPath(dir)
+	if err != nil {
+		logrus.Errorf("failed to expand sonobuoy directory %q: %v", dir, err)
+		return ""
+	}
+	return filepath.Join(expanded, trustedKeysDir)
+}
+
+// loadTrustedKeys loads every public key in the files of the directory. A missing directory means
+// no keys are trusted.
+func loadTrustedKeys(dir string) ([]trustedKey, error) {
+	if len(dir) == 0 {
+		return nil, nil
+	}
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read trusted keys directory %q", dir)
+	}
+
+	keys := []trustedKey{}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read trusted key %q", path)
+		}
+		found := false
+		for block, rest := pem.Decode(b); block != nil; block, rest = pem.Decode(rest) {
+			if block.Type != "PUBLIC KEY" {
+				continue
+			}
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse trusted key %q", path)
+			}
+			keys = append(keys, trustedKey{file: f.Name(), key: key})
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("no PEM encoded public key found in trusted key file %q", path)
+		}
+	}
+	return keys, nil
+}
+
+// verifySignature checks the base64 encoded signature of the data against each of the keys,
+// returning the file of the key which signed it.
+func verifySignature(keys []trustedKey, data, encodedSig []byte) (string, error) {
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(encodedSig)))
+	if err != nil {
+		return "", errors.Wrap(err, "signature is not base64 encoded")
+	}
+	digest := sha256.Sum256(data)
+	for _, k := range keys {
+		var ok bool
+		switch key := k.key.(type) {
+		case ed25519.PublicKey:
+			ok = ed25519.Verify(key, data, sig)
+		case *ecdsa.PublicKey:
+			ok = ecdsa.VerifyASN1(key, digest[:], sig)
+		case *rsa.PublicKey:
+			ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+		}
+		if ok {
+			return k.file, nil
+		}
+	}
+	return "", errors.New("signature does not match any trusted key")
+}
+
+// signatureURL returns the URL of the detached signature of the plugin at the given URL.
+func signatureURL(pluginURL string) (string, error) {
+	u, err := url.Parse(pluginURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid plugin URL %q", pluginURL)
+	}
+	u.Path += signatureExtension
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// fetchPluginSignature returns the detached signature of the plugin at the given URL, or nil if
+// the plugin is not signed.
+func fetchPluginSignature(pluginURL string) ([]byte, error) {
+	sigURL, err := signatureURL(pluginURL)
+	if err != nil {
+		return nil, err
+	}
+	c := http.Client{
+		Timeout: 10 * time.Second,
+	}
+	resp, err := c.Get(sigURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to GET URL %q", sigURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode > 399 {
+		return nil, fmt.Errorf("unexpected HTTP response code %v from %q", resp.StatusCode, sigURL)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// verifyPluginSignature checks the plugin definition fetched from the URL against its detached
+// signature and returns whether it was signed by a trusted key. A signature which does not match
+// any trusted key is an error; an unsigned plugin is not, so callers decide whether to accept it.
+func verifyPluginSignature(pluginURL string, data []byte) (bool, error) {
+	dir := trustedKeysLocation()
+	keys, err := loadTrustedKeys(dir)
+	if err != nil {
+		return false, err
+	}
+	if len(keys) == 0 {
+		logrus.Warnf("Plugin from %q was not verified; no trusted keys are configured in %q", pluginURL, dir)
+		return false, nil
+	}
+
+	sig, err := fetchPluginSignature(pluginURL)
+	if err != nil {
+		logrus.Warnf("Plugin from %q was not verified; failed to fetch its signature: %v", pluginURL, err)
+		return false, nil
+	}
+	if sig == nil {
+		logrus.Warnf("Plugin from %q is not signed", pluginURL)
+		return false, nil
+	}
+	keyFile, err := verifySignature(keys, data, sig)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to verify signature of plugin from %q", pluginURL)
+	}
+	logrus.Debugf("Plugin from %q is signed by trusted key %v", pluginURL, keyFile)
+	return true, nil
+}
+
+// recordPluginVerification records whether the installed plugin file was signed by a trusted key so
+// that it can be required when the plugin is loaded from the installation directory later.
+func recordPluginVerification(installedDir, filename string, verified bool) error {
+	verifiedPlugins, err := loadVerifiedPlugins(installedDir)
+	if err != nil {
+		return err
+	}
+	delete(verifiedPlugins, filename)
+	if verified {
+		b, err := os.ReadFile(filepath.Join(installedDir, filename))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read installed plugin %v", filename)
+		}
+		verifiedPlugins[filename] = fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+	}
+	b, err := json.MarshalIndent(verifiedPlugins, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.Wrap(os.WriteFile(filepath.Join(installedDir, verifiedPluginsFile), b, 0666), "failed to record verified plugins")
+}
+
+// installedPluginVerified returns true if the installed plugin file was signed by a trusted key when
+// it was installed and hasn't changed since.
+func installedPluginVerified(installedDir, filename string) (bool, error) {
+	verifiedPlugins, err := loadVerifiedPlugins(installedDir)
+	if err != nil {
+		return false, err
+	}
+	sum, ok := verifiedPlugins[filename]
+	if !ok {
+		return false, nil
+	}
+	b, err := os.ReadFile(filepath.Join(installedDir, filename))
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to read installed plugin %v", filename)
+	}
+	return sum == fmt.Sprintf("sha256:%x", sha256.Sum256(b)), nil
+}
+
+func loadVerifiedPlugins(installedDir string) (map[string]string, error) {
+	verifiedPlugins := map[string]string{}
+	b, err := os.ReadFile(filepath.Join(installedDir, verifiedPluginsFile))
+	switch {
+	case os.IsNotExist(err):
+		return verifiedPlugins, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to read verified plugins")
+	}
+	if err := json.Unmarshal(b, &verifiedPlugins); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %v", verifiedPluginsFile)
+	}
+	return verifiedPlugins, nil
+}
+
+// unsignedPluginError is returned when signed plugins are required but the plugin from the URL
+// was not signed by a trusted key.
+func unsignedPluginError(pluginURL string) error {
+	return fmt.Errorf("plugin from %q is not signed by a trusted key; signed plugins are required", pluginURL)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTrustedKey writes the public key to the trusted keys directory of the Sonobuoy directory.
+func writeTrustedKey(t *testing.T, sonobuoyDir, name string, pub crypto.PublicKey) {
+	t.Helper()
+	b, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(sonobuoyDir, trustedKeysDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b}), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPluginSignatureVerification(t *testing.T) {
+	pluginYAML := []byte("sonobuoy-config:\n  driver: Job\n  plugin-name: signed\nspec:\n  image: example.com/signed:v1\n  name: plugin\n")
+
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(pluginYAML)
+	ecSig, err := ecdsa.SignASN1(rand.Reader, ecPriv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(b []byte) []byte { return []byte(base64.StdEncoding.EncodeToString(b) + "\n") }
+
+	testCases := []struct {
+		desc           string
+		noKeys         bool
+		sig            []byte
+		sigStatus      int
+		expectVerified bool
+		expectErr      string
+	}{
+		{
+			desc:           "Signed with ed25519 key",
+			sig:            encode(ed25519.Sign(edPriv, pluginYAML)),
+			expectVerified: true,
+		}, {
+			desc:           "Signed with ECDSA key",
+			sig:            encode(ecSig),
+			expectVerified: true,
+		}, {
+			desc: "Unsigned",
+		}, {
+			desc:      "Signature can't be fetched",
+			sig:       encode(ed25519.Sign(edPriv, pluginYAML)),
+			sigStatus: http.StatusForbidden,
+		}, {
+			desc:   "No trusted keys",
+			noKeys: true,
+			sig:    encode(ed25519.Sign(edPriv, pluginYAML)),
+		}, {
+			desc:      "Signed by untrusted key",
+			sig:       encode(ed25519.Sign(otherPriv, pluginYAML)),
+			expectErr: "signature does not match any trusted key",
+		}, {
+			desc:      "Signature not base64",
+			sig:       []byte("not a signature!"),
+			expectErr: "signature is not base64 encoded",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sonobuoyDir := t.TempDir()
+			t.Setenv(SonobuoyDirEnvKey, sonobuoyDir)
+			if !tc.noKeys {
+				writeTrustedKey(t, sonobuoyDir, "ed25519.pub", edPub)
+				writeTrustedKey(t, sonobuoyDir, "ecdsa.pub", &ecPriv.PublicKey)
+			}
+
+			repo := &testPluginRepo{files: map[string][]byte{"plugin.yaml": pluginYAML}}
+			if tc.sig != nil {
+				repo.files["plugin.yaml.sig"] = tc.sig
+			}
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if tc.sigStatus != 0 && strings.HasSuffix(req.URL.Path, signatureExtension) {
+					w.WriteHeader(tc.sigStatus)
+					return
+				}
+				repo.ServeHTTP(w, req)
+			}))
+			defer s.Close()
+			pluginURL := s.URL + "/plugin.yaml"
+
+			var pl pluginList
+			pl.initInstallDir = true
+			err := pl.Set(pluginURL)
+			switch {
+			case len(tc.expectErr) > 0:
+				if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+					t.Fatalf("Expected error containing %q but got %v", tc.expectErr, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(pl.StaticPlugins) != 1 {
+				t.Fatalf("Expected 1 plugin to be loaded but got %v", len(pl.StaticPlugins))
+			}
+
+			err = pl.checkSigned()
+			if tc.expectVerified && err != nil {
+				t.Errorf("Expected plugin to be verified but got %v", err)
+			}
+			if !tc.expectVerified && err == nil {
+				t.Errorf("Expected an error requiring signed plugins")
+			}
+
+			installDir := t.TempDir()
+			err = installPlugin(installDir, "signed.yaml", pluginURL, true)
+			_, statErr := os.Stat(filepath.Join(installDir, "signed.yaml"))
+			if tc.expectVerified && (err != nil || statErr != nil) {
+				t.Errorf("Expected plugin to be installed but got %v, %v", err, statErr)
+			}
+			if !tc.expectVerified && (err == nil || statErr == nil) {
+				t.Errorf("Expected unverified plugin not to be installed when signed plugins are required")
+			}
+
+			// Whether the plugin was verified is recorded so it is still required once installed.
+			if err := installPlugin(installDir, "signed.yaml", pluginURL, false); err != nil {
+				t.Fatalf("Unexpected error installing plugin: %v", err)
+			}
+			installed := pluginList{InstallDir: installDir, initInstallDir: true}
+			if err := installed.Set("signed"); err != nil {
+				t.Fatalf("Unexpected error loading installed plugin: %v", err)
+			}
+			err = installed.checkSigned()
+			if tc.expectVerified && err != nil {
+				t.Errorf("Expected installed plugin to be verified but got %v", err)
+			}
+			if !tc.expectVerified && err == nil {
+				t.Errorf("Expected an error requiring signed plugins for the installed plugin")
+			}
+
+			// Installed plugins which were changed since are no longer verified.
+			f, err := os.OpenFile(filepath.Join(installDir, "signed.yaml"), os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.WriteString("# modified\n"); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+			if verified, err := installedPluginVerified(installDir, "signed.yaml"); err != nil || verified {
+				t.Errorf("Expected modified plugin not to be verified but got %v, %v", verified, err)
+			}
+		})
+	}
+}
+
+func TestPluginRepoRequireSigned(t *testing.T) {
+	sonobuoyDir := t.TempDir()
+	t.Setenv(SonobuoyDirEnvKey, sonobuoyDir)
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTrustedKey(t, sonobuoyDir, "repo.pub", pub)
+
+	repo, indexURL := newTestPluginRepo(t)
+	repo.addPlugin("cis", "1.0.0", "CIS benchmark")
+	dir := t.TempDir()
+	if err := addPluginRepo(os.Stdout, dir, "test", indexURL); err != nil {
+		t.Fatal(err)
+	}
+
+	err = installPluginFromRepo(os.Stdout, dir, "cis", true)
+	if err == nil || !strings.Contains(err.Error(), "not signed by a trusted key") {
+		t.Fatalf("Expected error installing unsigned plugin but got %v", err)
+	}
+
+	repo.files["plugins/cis-1.0.0.yaml.sig"] = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, repo.files["plugins/cis-1.0.0.yaml"])))
+	if err := installPluginFromRepo(os.Stdout, dir, "cis", true); err != nil {
+		t.Fatalf("Expected signed plugin to be installed but got %v", err)
+	}
+}
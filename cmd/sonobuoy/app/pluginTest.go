@@ -0,0 +1,265 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/image"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/daemonset"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/job"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+	"github.com/vmware-tanzu/sonobuoy/pkg/tarball"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// testNodeName is the node name used for the results of DaemonSet plugins tested locally.
+	testNodeName = "local"
+
+	// doneFile is the file the worker waits for; it contains the path of the results to send.
+	doneFile = "done"
+
+	// gzipMimeType is the type of results which the aggregator extracts rather than saves.
+	gzipMimeType = "application/gzip"
+)
+
+// pluginImageRunner runs plugin images locally, mounting host directories into the container.
+type pluginImageRunner interface {
+	RunImageWithVolumes(image string, entryPoint string, env map[string]string, volumes map[string]string, args ...string) ([]string, error)
+}
+
+type pluginTestFlags struct {
+	resultsDir string
+	run        bool
+}
+
+func newCmdPluginTest() *cobra.Command {
+	var f pluginTestFlags
+	cmd := &cobra.Command{
+		Use:   "test <plugin filename>",
+		Short: "Process the results of a plugin locally, without a cluster",
+		Long: "Process the results of a plugin locally, without a cluster.\n\n" +
+			"The results directory is treated as the results volume of the plugin pod: the done file is " +
+			"read and the results it refers to are gathered as the worker and aggregator would. The " +
+			"results are then post-processed according to the plugin's result-format and the resulting " +
+			"tree is printed.\n\n" +
+			"With --run, the plugin image is first run with Docker and the results directory mounted as " +
+			"the results volume.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !f.run && len(f.resultsDir) == 0 {
+				return errors.New("--results-dir is required unless the plugin is run with --run")
+			}
+			var runner pluginImageRunner
+			if f.run {
+				runner = image.NewDockerClient().(image.DockerClient)
+			}
+			return testPlugin(os.Stdout, args[0], f.resultsDir, runner)
+		},
+	}
+	cmd.Flags().StringVar(&f.resultsDir, "results-dir", "", "Directory with the results written by the plugin, including the done file. With --run, a temporary directory is used if unset.")
+	cmd.Flags().BoolVar(&f.run, "run", false, "If true, run the plugin image with Docker to produce the results before processing them.")
+	return cmd
+}
+
+// testPlugin processes the results of the plugin in the results directory as they would be in the
+// cluster and prints the resulting item. If runner is set, the plugin image is run first.
+func testPlugin(w io.Writer, filename, resultsDir string, runner pluginImageRunner) error {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read plugin %v", filename)
+	}
+	warnPluginLint(filename, b)
+	m, err := loadManifest(b)
+	if err != nil {
+		return err
+	}
+
+	var p plugin.Interface
+	node := plugin.GlobalResult
+	switch strings.ToLower(m.SonobuoyConfig.Driver) {
+	case "job":
+		p = job.NewPlugin(*m, "", "", "", "", nil)
+	case "daemonset":
+		p = daemonset.NewPlugin(*m, "", "", "", "", nil)
+		node = testNodeName
+	default:
+		return fmt.Errorf("plugins with the %v driver don't have results to test", m.SonobuoyConfig.Driver)
+	}
+
+	if len(resultsDir) == 0 {
+		resultsDir, err = os.MkdirTemp("", "sonobuoy-plugin-results-")
+		if err != nil {
+			return errors.Wrap(err, "failed to create results directory")
+		}
+		defer os.RemoveAll(resultsDir)
+	}
+	if runner != nil {
+		if err := runPluginImage(runner, m, resultsDir); err != nil {
+			return err
+		}
+	}
+
+	outDir, err := os.MkdirTemp("", "sonobuoy-plugin-test-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create output directory")
+	}
+	defer os.RemoveAll(outDir)
+
+	pluginResultsDir := filepath.Join(outDir, results.PluginsDir, p.GetName(), results.ResultsDir, node)
+	if err := gatherPluginResults(resultsDir, pluginResultsDir); err != nil {
+		return err
+	}
+
+	item, errs := results.PostProcessPlugin(p, outDir)
+	out, err := yaml.Marshal(item)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode results")
+	}
+	if _, err := w.Write(out); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, e := range errs {
+			msgs[i] = e.Error()
+		}
+		return fmt.Errorf("failed to process results of plugin %v: %v", p.GetName(), strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// runPluginImage runs the plugin container with Docker, mounting the results directory at the
+// results directory of the plugin container and setting the same default env vars as the driver.
+func runPluginImage(runner pluginImageRunner, m *manifest.Manifest, resultsDir string) error {
+	absDir, err := filepath.Abs(resultsDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get absolute path of %v", resultsDir)
+	}
+
+	// The results volume is always mounted at the results directory in the cluster, regardless of
+	// the mount in the plugin definition.
+	env := map[string]string{
+		"RESULTS_DIR":          plugin.ResultsDir,
+		"SONOBUOY_RESULTS_DIR": plugin.ResultsDir,
+		"RESULT_TYPE":          m.SonobuoyConfig.PluginName,
+	}
+	for _, e := range m.Spec.Env {
+		if e.ValueFrom != nil {
+			logrus.Warnf("Skipping env var %v since values from the cluster are not available locally", e.Name)
+			continue
+		}
+		env[e.Name] = e.Value
+	}
+
+	entryPoint, args := "", m.Spec.Args
+	if len(m.Spec.Command) > 0 {
+		entryPoint = m.Spec.Command[0]
+		args = append(append([]string{}, m.Spec.Command[1:]...), m.Spec.Args...)
+	}
+
+	logrus.Infof("Running plugin image %v with results directory %v mounted at %v", m.Spec.Image, absDir, plugin.ResultsDir)
+	output, err := runner.RunImageWithVolumes(m.Spec.Image, entryPoint, env, map[string]string{absDir: plugin.ResultsDir}, args...)
+	for _, line := range output {
+		logrus.WithField("plugin", m.SonobuoyConfig.PluginName).Info(line)
+	}
+	return errors.Wrapf(err, "failed to run plugin image %v", m.Spec.Image)
+}
+
+// gatherPluginResults copies the results referred to by the done file in the results directory
+// into the output directory the way the worker and aggregator would: directories are sent as a
+// tarball, gzipped files are extracted and other files are saved as they are. If there is no done
+// file, the whole results directory is sent as the worker does once the other containers complete.
+func gatherPluginResults(resultsDir, outDir string) error {
+	resultFile := resultsDir
+	done, err := os.ReadFile(filepath.Join(resultsDir, doneFile))
+	switch {
+	case os.IsNotExist(err):
+		logrus.Warnf("No %v file was found in %v so the whole directory is used as the results", doneFile, resultsDir)
+	case err != nil:
+		return errors.Wrapf(err, "failed to read %v file", doneFile)
+	default:
+		// The done file contains a path in the worker's file system, where the results volume is
+		// mounted at the results directory.
+		donePath := path.Clean(strings.TrimSpace(string(done)))
+		rel := strings.TrimPrefix(donePath, plugin.ResultsDir)
+		if rel == donePath || (len(rel) > 0 && !strings.HasPrefix(rel, "/")) {
+			return fmt.Errorf("the %v file refers to %q which the worker can't read; results must be in %v", doneFile, donePath, plugin.ResultsDir)
+		}
+		resultFile = filepath.Join(resultsDir, filepath.FromSlash(rel))
+		logrus.Infof("The %v file refers to %v, gathering %v", doneFile, donePath, resultFile)
+	}
+
+	fi, err := os.Stat(resultFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat result file %v", resultFile)
+	}
+	if fi.IsDir() {
+		return gatherResultsDir(resultFile, outDir)
+	}
+	if mime.TypeByExtension(filepath.Ext(resultFile)) == gzipMimeType {
+		return extractResults(resultFile, outDir)
+	}
+	return copyResultFile(resultFile, outDir)
+}
+
+func gatherResultsDir(dir, outDir string) error {
+	tmpDir, err := os.MkdirTemp("", "sonobuoy-plugin-tarball-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary directory")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tarballPath := filepath.Join(tmpDir, "results.tar.gz")
+	if err := tarball.DirToTarball(dir, tarballPath, true); err != nil {
+		return errors.Wrapf(err, "failed to tar results directory %v", dir)
+	}
+	return extractResults(tarballPath, outDir)
+}
+
+func extractResults(file, outDir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %v", file)
+	}
+	defer f.Close()
+	return errors.Wrapf(tarball.DecodeTarball(f, outDir), "failed to extract results from %v", file)
+}
+
+func copyResultFile(file, outDir string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read result file %v", file)
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory %v", outDir)
+	}
+	return errors.Wrap(os.WriteFile(filepath.Join(outDir, filepath.Base(file)), b, 0644), "failed to save result file")
+}
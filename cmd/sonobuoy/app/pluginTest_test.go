@@ -0,0 +1,240 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+const (
+	testJUnitReport = `<testsuites><testsuite name="suite" tests="2" failures="1">` +
+		`<testcase name="ok"/><testcase name="bad"><failure message="boom"/></testcase></testsuite></testsuites>`
+
+	testJobPlugin = `sonobuoy-config:
+  driver: Job
+  plugin-name: demo
+  result-format: junit
+spec:
+  image: example.com/demo:v1
+  name: plugin
+  command: [/run.sh, -v]
+  args: [--fast]
+  env:
+  - name: MODE
+    value: quick
+  - name: NODE
+    valueFrom:
+      fieldRef:
+        fieldPath: spec.nodeName
+  volumeMounts:
+  - mountPath: /results
+    name: results
+`
+
+	testDaemonSetPlugin = `sonobuoy-config:
+  driver: DaemonSet
+  plugin-name: nodes
+  result-format: raw
+spec:
+  image: example.com/nodes:v1
+  name: plugin
+  volumeMounts:
+  - mountPath: /tmp/sonobuoy/results
+    name: results
+`
+
+	testJobOutput = `name: demo
+status: failed
+meta:
+  type: summary
+items:
+- name: report.xml
+  status: failed
+  meta:
+    file: results/global/report.xml
+    type: file
+  items:
+  - name: suite
+    status: failed
+    items:
+    - name: ok
+      status: passed
+    - name: bad
+      status: failed
+      details:
+        failure: boom
+`
+)
+
+// fakeImageRunner records how the image was run and writes the files into the mounted results directory.
+type fakeImageRunner struct {
+	files map[string]string
+
+	image, entryPoint string
+	env               map[string]string
+	mounts            []string
+	args              []string
+}
+
+func (f *fakeImageRunner) RunImageWithVolumes(image string, entryPoint string, env map[string]string, volumes map[string]string, args ...string) ([]string, error) {
+	f.image, f.entryPoint, f.env, f.args = image, entryPoint, env, args
+	for hostPath, containerPath := range volumes {
+		f.mounts = append(f.mounts, containerPath)
+		for name, contents := range f.files {
+			if err := os.WriteFile(filepath.Join(hostPath, name), []byte(contents), 0644); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return []string{"running"}, nil
+}
+
+func TestTestPlugin(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		plugin       string
+		files        map[string]string
+		expectOutput string
+		expectErr    string
+	}{
+		{
+			desc:         "Done file refers to a file",
+			plugin:       testJobPlugin,
+			files:        map[string]string{"report.xml": testJUnitReport, "done": "/tmp/sonobuoy/results/report.xml\n"},
+			expectOutput: testJobOutput,
+		}, {
+			desc:   "Done file refers to a directory of node results",
+			plugin: testDaemonSetPlugin,
+			files:  map[string]string{"out/a.txt": "a", "out/b.txt": "b", "ignored.txt": "x", "done": "/tmp/sonobuoy/results/out"},
+			expectOutput: `name: nodes
+status: passed
+meta:
+  type: summary
+items:
+- name: local
+  status: passed
+  meta:
+    type: node
+  items:
+  - name: a.txt
+    status: passed
+    meta:
+      file: results/local/a.txt
+  - name: b.txt
+    status: passed
+    meta:
+      file: results/local/b.txt
+`,
+		}, {
+			desc:         "No done file sends the whole directory",
+			plugin:       testJobPlugin,
+			files:        map[string]string{"report.xml": testJUnitReport},
+			expectOutput: testJobOutput,
+		}, {
+			desc:      "Done file outside of the results directory",
+			plugin:    testJobPlugin,
+			files:     map[string]string{"report.xml": testJUnitReport, "done": "/results/report.xml"},
+			expectErr: `the done file refers to "/results/report.xml" which the worker can't read; results must be in /tmp/sonobuoy/results`,
+		}, {
+			desc:      "Done file refers to missing results",
+			plugin:    testJobPlugin,
+			files:     map[string]string{"done": "/tmp/sonobuoy/results/report.xml"},
+			expectErr: "failed to stat result file",
+		}, {
+			desc:      "Invalid plugin",
+			plugin:    "sonobuoy-config: invalid\n",
+			expectErr: "couldn't decode yaml for plugin definition",
+		}, {
+			desc:      "Query plugins have no results",
+			plugin:    "sonobuoy-config:\n  driver: Query\n  plugin-name: query\n",
+			expectErr: "plugins with the Query driver don't have results to test",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			pluginFile := filepath.Join(dir, "plugin.yaml")
+			if err := os.WriteFile(pluginFile, []byte(tc.plugin), 0644); err != nil {
+				t.Fatal(err)
+			}
+			resultsDir := filepath.Join(dir, "results")
+			for name, contents := range tc.files {
+				if err := os.MkdirAll(filepath.Dir(filepath.Join(resultsDir, name)), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(resultsDir, name), []byte(contents), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var out bytes.Buffer
+			err := testPlugin(&out, pluginFile, resultsDir, nil)
+			if len(tc.expectErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+					t.Fatalf("Expected error containing %q but got %v", tc.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := pretty.Compare(tc.expectOutput, out.String()); diff != "" {
+				t.Errorf("Unexpected output (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestTestPluginRun(t *testing.T) {
+	pluginFile := filepath.Join(t.TempDir(), "plugin.yaml")
+	if err := os.WriteFile(pluginFile, []byte(testJobPlugin), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runner := &fakeImageRunner{files: map[string]string{"report.xml": testJUnitReport, "done": "/tmp/sonobuoy/results/report.xml"}}
+
+	var out bytes.Buffer
+	if err := testPlugin(&out, pluginFile, "", runner); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff := pretty.Compare(testJobOutput, out.String()); diff != "" {
+		t.Errorf("Unexpected output (-want +got):\n%v", diff)
+	}
+
+	expect := fakeImageRunner{
+		image:      "example.com/demo:v1",
+		entryPoint: "/run.sh",
+		env: map[string]string{
+			"MODE":                 "quick",
+			"RESULTS_DIR":          "/tmp/sonobuoy/results",
+			"SONOBUOY_RESULTS_DIR": "/tmp/sonobuoy/results",
+			"RESULT_TYPE":          "demo",
+		},
+		mounts: []string{"/tmp/sonobuoy/results"},
+		args:   []string{"-v", "--fast"},
+	}
+	got := fakeImageRunner{image: runner.image, entryPoint: runner.entryPoint, env: runner.env, mounts: runner.mounts, args: runner.args}
+	if diff := pretty.Compare(expect, got); diff != "" {
+		t.Errorf("Unexpected run of the plugin image (-want +got):\n%v", diff)
+	}
+}
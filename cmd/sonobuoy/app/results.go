@@ -31,9 +31,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
 	"github.com/vmware-tanzu/sonobuoy/pkg/discovery"
 	"github.com/vmware-tanzu/sonobuoy/pkg/errlog"
 	"gopkg.in/yaml.v2"
+	kyaml "sigs.k8s.io/yaml"
 )
 
 const (
@@ -59,11 +61,12 @@ const (
 )
 
 type resultsInput struct {
-	archive    string
-	plugin     string
-	mode       string
-	node       string
-	skipPrefix bool
+	archive     string
+	plugin      string
+	mode        string
+	node        string
+	skipPrefix  bool
+	logPatterns string
 }
 
 func NewCmdResults() *cobra.Command {
@@ -97,7 +100,12 @@ func NewCmdResults() *cobra.Command {
 		&data.skipPrefix, "skip-prefix", "s", false,
 		`When printing items linking to files, only print the file contents.`,
 	)
+	cmd.Flags().StringVar(
+		&data.logPatterns, "log-patterns", "",
+		`A JSON or YAML file with sets of patterns to search for in the logs, in addition to the defaults. Only affects the sonobuoy plugin.`,
+	)
 
+	cmd.AddCommand(NewCmdResultsRedact())
 	return cmd
 }
 
@@ -190,7 +198,8 @@ func printHealthSummary(input resultsInput, r *results.Reader) error {
 	var err error
 
 	//For detailed view we can just dump the contents of the clusterHealthSummaryPluginName file
-	if input.mode == resultModeDetailed {
+	//unless the logs need to be summarized again.
+	if input.mode == resultModeDetailed && len(input.logPatterns) == 0 {
 		reader, err := r.FileReader(results.ClusterHealthFilePath())
 		if err != nil {
 			return errors.Wrapf(err, "failed to get health summary results reader from file '%s'", results.ClusterHealthFilePath())
@@ -212,9 +221,21 @@ func printHealthSummary(input resultsInput, r *results.Reader) error {
 		return err
 	}
 
+	if len(input.logPatterns) > 0 {
+		if err := summarizeLogs(input, &clusterHealthSummary); err != nil {
+			return err
+		}
+	}
+
 	var data []byte
 
 	switch input.mode {
+	case resultModeDetailed:
+		data, err = json.Marshal(clusterHealthSummary)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
 	case resultModeDump:
 		data, err = yaml.Marshal(clusterHealthSummary)
 		if err != nil {
@@ -239,6 +260,42 @@ func printHealthSummary(input resultsInput, r *results.Reader) error {
 	return nil
 }
 
+// summarizeLogs replaces the log summary in the health summary with one using the patterns from
+// the input along with the defaults.
+func summarizeLogs(input resultsInput, summary *discovery.ClusterSummary) error {
+	patterns, err := loadLogPatterns(input.logPatterns)
+	if err != nil {
+		return err
+	}
+
+	// Load file with a new reader since we can't assume this reader has rewind capabilities.
+	r, cleanup, err := getReader(input.archive)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	summary.ErrorInfo, summary.LogSamples, err = discovery.ReadLogSummaryWithSamples(r, patterns)
+	return err
+}
+
+// loadLogPatterns reads a list of pattern sets from the file, which may be JSON or YAML, and adds
+// them to the default patterns.
+func loadLogPatterns(file string) (discovery.LogPatterns, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading log patterns")
+	}
+	sets := []config.LogPatternSet{}
+	if err := kyaml.UnmarshalStrict(b, &sets); err != nil {
+		return nil, errors.Wrapf(err, "decoding log patterns from %v", file)
+	}
+	patterns := discovery.GetDefaultLogPatterns()
+	if err := patterns.Add(sets...); err != nil {
+		return nil, errors.Wrapf(err, "invalid log patterns in %v", file)
+	}
+	return patterns, nil
+}
+
 type humanReadableWriter struct {
 	w io.Writer
 }
@@ -407,6 +464,22 @@ func filterAndSortHealthInfoDetails(details []discovery.HealthInfoDetails) []dis
 	return result
 }
 
+// formatNodeUsage describes the CPU and memory used compared to the allocatable amounts. It is empty
+// if the usage was not gathered from the node.
+func formatNodeUsage(usage *discovery.NodeResourceUsage) string {
+	if usage == nil {
+		return ""
+	}
+	var parts []string
+	if usage.CPUUsedMillis != nil {
+		parts = append(parts, fmt.Sprintf("cpu %dm/%dm", *usage.CPUUsedMillis, usage.CPUAllocatableMillis))
+	}
+	if usage.MemoryUsedBytes != nil {
+		parts = append(parts, fmt.Sprintf("memory %dMi/%dMi", *usage.MemoryUsedBytes/(1<<20), usage.MemoryAllocatableBytes/(1<<20)))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // printClusterHealthResultsSummary prints the summary of the "fake" plugin for health summary,
 // tryingf to emulate the format of printResultsSummary
 func printClusterHealthResultsSummary(summary discovery.ClusterSummary) error {
@@ -428,6 +501,16 @@ func printClusterHealthResultsSummary(summary discovery.ClusterSummary) error {
 		}
 		fmt.Println()
 	}
+	for _, node := range summary.NodeHealth.Details {
+		if len(node.Pressure) > 0 {
+			fmt.Printf("%s under pressure: %s\n", node.Name, strings.Join(node.Pressure, ", "))
+		}
+	}
+	for _, node := range summary.NodeHealth.Details {
+		if usage := formatNodeUsage(node.Resources); len(usage) > 0 {
+			fmt.Printf("%s usage: %s\n", node.Name, usage)
+		}
+	}
 
 	//It might be nice to group pods by namespace.
 	//Also here, use len instead of trusting Total
@@ -448,6 +531,28 @@ func printClusterHealthResultsSummary(summary discovery.ClusterSummary) error {
 		}
 	}
 
+	if summary.Events.Total > 0 {
+		fmt.Printf("Warning events: %d/%d\n", summary.Events.Warnings, summary.Events.Total)
+		reasons := sortedWarningReasons(summary.Events.WarningsByReason)
+		if len(reasons) > 0 {
+			maxWidth := len(fmt.Sprintf("%d", summary.Events.WarningsByReason[reasons[0]]))
+			for _, reason := range reasons {
+				fmt.Printf("%[1]*[2]d %[3]s\n", maxWidth, summary.Events.WarningsByReason[reason], reason)
+			}
+		}
+	}
+
+	if len(summary.LeakedResources) > 0 {
+		fmt.Printf("Warning: %d resources were left behind by the plugins:\n", len(summary.LeakedResources))
+		for _, leaked := range summary.LeakedResources {
+			name := leaked.Name
+			if len(leaked.Namespace) > 0 {
+				name = leaked.Namespace + "/" + name
+			}
+			fmt.Printf("%s %s\n", leaked.Resource, name)
+		}
+	}
+
 	if len(summary.ErrorInfo) > 0 {
 		fmt.Println("Errors detected in files:")
 		sortedFileNames := sortErrors(summary.ErrorInfo)
@@ -461,12 +566,30 @@ func printClusterHealthResultsSummary(summary discovery.ClusterSummary) error {
 			for _, fileName := range sortedFileNames[errorType] {
 				fmt.Printf("%[1]*[2]d %[3]s\n", maxWidth, summary.ErrorInfo[errorType][fileName], fileName)
 			}
+			for _, sample := range summary.LogSamples[errorType] {
+				fmt.Printf("  %s:%d: %s\n", sample.File, sample.Line, sample.Text)
+			}
 		}
 	}
 
 	return nil
 }
 
+// sortedWarningReasons returns the reasons with the most warnings first, breaking ties by name.
+func sortedWarningReasons(counts map[string]int) []string {
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	return reasons
+}
+
 func printResultsSummary(o *results.Item) error {
 	statusCounts := map[string]int{}
 	var failedList []string
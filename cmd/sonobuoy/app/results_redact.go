@@ -0,0 +1,152 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"github.com/vmware-tanzu/sonobuoy/pkg/errlog"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/aggregation"
+	"github.com/vmware-tanzu/sonobuoy/pkg/redact"
+	"sigs.k8s.io/yaml"
+)
+
+type redactInput struct {
+	archive string
+	rules   string
+	output  string
+}
+
+// NewCmdResultsRedact returns the command which redacts an existing results archive.
+func NewCmdResultsRedact() *cobra.Command {
+	input := redactInput{}
+	cmd := &cobra.Command{
+		Use:   "redact archive.tar.gz",
+		Short: "Writes a copy of a results archive with sensitive data removed",
+		Long: "Writes a copy of a results archive with the values matching the redaction rules masked. The rules " +
+			"file has the same form as the Redaction field of the Sonobuoy config (JSON or YAML). The TarInfo " +
+			"of the new archive is printed when complete.",
+		Run: func(cmd *cobra.Command, args []string) {
+			input.archive = args[0]
+			if err := redactArchive(input); err != nil {
+				errlog.LogError(errors.Wrapf(err, "could not redact archive: %v", args[0]))
+				os.Exit(1)
+			}
+		},
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().StringVar(
+		&input.rules, "rules", "",
+		"File containing the redaction rules.",
+	)
+	cmd.Flags().StringVarP(
+		&input.output, "output", "o", "",
+		"Path to write the redacted archive to. Defaults to the name of the archive with a _redacted suffix.",
+	)
+	if err := cmd.MarkFlagRequired("rules"); err != nil {
+		logrus.Fatal(err)
+	}
+	return cmd
+}
+
+func redactArchive(input redactInput) error {
+	redactor, err := loadRedactionRules(input.rules)
+	if err != nil {
+		return err
+	}
+
+	output := input.output
+	if len(output) == 0 {
+		output = redactedArchiveName(input.archive)
+	}
+	if filepath.Clean(output) == filepath.Clean(input.archive) {
+		return errors.New("the redacted archive cannot overwrite the original")
+	}
+
+	r, cleanup, err := getReader(input.archive)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file so that a failure never leaves a partially redacted archive behind.
+	tmp, err := os.CreateTemp(filepath.Dir(output), filepath.Base(output)+".*.part")
+	if err != nil {
+		return errors.Wrap(err, "creating output file")
+	}
+	defer os.Remove(tmp.Name())
+	if err := redactor.Archive(r, tmp, filepath.Base(input.archive)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "writing output file")
+	}
+	if err := os.Rename(tmp.Name(), output); err != nil {
+		return errors.Wrap(err, "writing output file")
+	}
+
+	for _, rec := range redactor.Records() {
+		logrus.Debugf("Redacted %v values from %v %v using %q", rec.Count, rec.File, rec.Object, rec.Rule)
+	}
+
+	info, err := aggregation.NewTarInfo(output)
+	if err != nil {
+		return errors.Wrap(err, "recording tarball info")
+	}
+	b, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// loadRedactionRules reads the rules file, which may be JSON or YAML.
+func loadRedactionRules(file string) (*redact.Redactor, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading redaction rules")
+	}
+	cfg := &config.RedactionConfig{}
+	if err := yaml.UnmarshalStrict(b, cfg); err != nil {
+		return nil, errors.Wrapf(err, "decoding redaction rules from %v", file)
+	}
+	redactor, err := redact.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if redactor == nil {
+		return nil, errors.Errorf("no redaction rules found in %v", file)
+	}
+	return redactor, nil
+}
+
+func redactedArchiveName(archive string) string {
+	base := strings.TrimSuffix(strings.TrimSuffix(archive, ".gz"), ".tar")
+	base = strings.TrimSuffix(base, ".tgz")
+	return base + "_redacted.tar.gz"
+}
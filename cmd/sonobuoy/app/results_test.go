@@ -18,9 +18,13 @@ package app
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/discovery"
 )
 
 func TestHumanReadableWriter(t *testing.T) {
@@ -263,3 +267,88 @@ func ExampleNewCmdResults_pluginDetailedArbitraryDetails() {
 	// {"name":"Item with arbitrary details","status":"complete","meta":{"path":"arbitrary-details|output-file"},"details":{"nested-details":{"key1":"value1","key2":"value2"},"string-array":["string 1","string 2","string 3"]}}
 	// {"name":"Another item with arbitrary details","status":"complete","meta":{"path":"arbitrary-details|output-file"},"details":{"integer-array":[1,2,3],"nested-details":{"key1":"value1","key2":"value2","key3":{"nested-key1":"nested-value1","nested-key2":"nested-value2","nested-key3":{"another-nested-key":"another-nested-value"}}}}}
 }
+
+func TestSortedWarningReasons(t *testing.T) {
+	got := sortedWarningReasons(map[string]int{"FailedScheduling": 2, "BackOff": 5, "Failed": 2})
+	expect := []string{"BackOff", "Failed", "FailedScheduling"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %v but got %v", expect, got)
+	}
+}
+
+func TestFormatNodeUsage(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		usage  *discovery.NodeResourceUsage
+		expect string
+	}{
+		{desc: "No resources", usage: nil, expect: ""},
+		{desc: "Only allocatable", usage: &discovery.NodeResourceUsage{CPUAllocatableMillis: 8000}, expect: ""},
+		{
+			desc: "Usage gathered",
+			usage: &discovery.NodeResourceUsage{
+				CPUAllocatableMillis:   8000,
+				CPUUsedMillis:          new(int64(412)),
+				MemoryAllocatableBytes: 4 << 30,
+				MemoryUsedBytes:        new(int64(512 << 20)),
+			},
+			expect: "cpu 412m/8000m, memory 512Mi/4096Mi",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := formatNodeUsage(tc.usage); got != tc.expect {
+				t.Errorf("Expected %q but got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestLoadLogPatterns(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		contents  string
+		expectErr string
+	}{
+		{
+			desc: "YAML sets are added to the defaults",
+			contents: `- Name: OOM
+  Lines: ["OOMKilled"]
+- Name: Panics
+  Files: ["^plugins/"]
+  Lines: ["panic:"]
+`,
+		}, {
+			desc:      "Unknown fields are rejected",
+			contents:  `[{"Name": "OOM", "Patterns": ["OOMKilled"]}]`,
+			expectErr: "decoding log patterns",
+		}, {
+			desc:      "Invalid expressions are rejected",
+			contents:  `[{"Name": "OOM", "Lines": ["("]}]`,
+			expectErr: "invalid log patterns",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "patterns.yaml")
+			if err := os.WriteFile(file, []byte(tc.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+			patterns, err := loadLogPatterns(file)
+			if len(tc.expectErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+					t.Fatalf("Expected error containing %q but got %v", tc.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"Errors", "Warnings", "OOM", "Panics"} {
+				if _, ok := patterns[name]; !ok {
+					t.Errorf("Expected patterns for %v", name)
+				}
+			}
+		})
+	}
+}
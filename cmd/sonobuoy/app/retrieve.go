@@ -20,8 +20,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vmware-tanzu/sonobuoy/pkg/client"
 	"github.com/vmware-tanzu/sonobuoy/pkg/errlog"
@@ -40,6 +43,10 @@ type retrieveFlags struct {
 	outputLocation string
 	filename       string
 	aggregatorPath string
+	partial        bool
+	plugins        []string
+	retries        int
+	fleet          fleetFlags
 }
 
 func NewCmdRetrieve() *cobra.Command {
@@ -56,6 +63,19 @@ func NewCmdRetrieve() *cobra.Command {
 	AddExtractFlag(&rcvFlags.extract, cmd.Flags())
 	AddFilenameFlag(&rcvFlags.filename, cmd.Flags())
 	AddRetrievePathFlag(&rcvFlags.aggregatorPath, cmd.Flags())
+	cmd.Flags().BoolVar(
+		&rcvFlags.partial, "partial", false,
+		"If true, retrieves the results of plugins which have already completed while the run is still in progress. The final results are unaffected.",
+	)
+	cmd.Flags().StringArrayVarP(
+		&rcvFlags.plugins, pluginFlag, "p", []string{},
+		"Only retrieve partial results for the given plugin. Can be specified multiple times. Requires --partial.",
+	)
+	cmd.Flags().IntVar(
+		&rcvFlags.retries, "retries", client.DefaultDownloadRetries,
+		"Number of times to resume the download if it is interrupted. The downloaded file is always verified against the checksum recorded by the aggregator.",
+	)
+	AddFleetFlags(&rcvFlags.fleet, cmd.Flags())
 
 	return cmd
 }
@@ -67,26 +87,31 @@ func retrieveResultsCmd(opts *retrieveFlags) func(cmd *cobra.Command, args []str
 			opts.outputLocation = args[0]
 		}
 
+		if opts.fleet.enabled() {
+			if err := retrieveFleetResults(os.Stdout, *opts); err != nil {
+				errlog.LogError(err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		sbc, err := getSonobuoyClientFromKubecfg(opts.kubecfg)
 		if err != nil {
 			errlog.LogError(errors.Wrap(err, "could not create sonobuoy client"))
 			os.Exit(1)
 		}
 
-		// Get a reader that contains the tar output of the results directory.
-		reader, ec, err := sbc.RetrieveResults(&client.RetrieveConfig{
-			Namespace: opts.namespace,
-			Path:      opts.aggregatorPath,
-		})
-		if err != nil {
-			errlog.LogError(err)
-			os.Exit(1)
+		filesCreated, err := fetchResults(sbc, *opts)
+		if err == nil {
+			err = processRetrievedFiles(*opts, filesCreated)
 		}
 
-		err = retrieveResults(*opts, reader, ec)
-		if _, ok := err.(exec.CodeExitError); ok {
+		if _, ok := err.(exec.CodeExitError); ok || err == client.ErrResultsNotReady {
 			fmt.Fprintln(os.Stderr, "Results not ready yet. Check `sonobuoy status` for status.")
 			os.Exit(1)
+		} else if _, ok := err.(*client.VerificationError); ok {
+			fmt.Fprintf(os.Stderr, "error verifying results: %v. The corrupt file has been removed; run `sonobuoy retrieve` again.\n", err)
+			os.Exit(2)
 		} else if err != nil {
 			fmt.Fprintf(os.Stderr, "error retrieving results: %v\n", err)
 			os.Exit(2)
@@ -94,39 +119,117 @@ func retrieveResultsCmd(opts *retrieveFlags) func(cmd *cobra.Command, args []str
 	}
 }
 
-func retrieveResults(opts retrieveFlags, r io.Reader, ec <-chan error) error {
+// fetchResults downloads the results into opts.outputLocation and returns the files created.
+// Final results are verified against the checksum recorded by the aggregator when possible.
+func fetchResults(sbc *client.SonobuoyClient, opts retrieveFlags) ([]string, error) {
+	if !opts.partial {
+		filename, err := sbc.DownloadResults(&client.DownloadConfig{
+			RetrieveConfig: client.RetrieveConfig{
+				Namespace: opts.namespace,
+				Path:      opts.aggregatorPath,
+			},
+			OutputDir: opts.outputLocation,
+			Filename:  opts.filename,
+			Retries:   opts.retries,
+		})
+		if err != client.ErrNoTarballInfo {
+			return []string{filename}, err
+		}
+		// Older aggregators don't record the tarball info; fall back to an unverified copy.
+		logrus.Warningf("Unable to verify results: %v", err)
+	}
+
+	// Get a reader that contains the tar output of the results directory.
+	reader, ec, err := sbc.RetrieveResults(&client.RetrieveConfig{
+		Namespace: opts.namespace,
+		Path:      opts.aggregatorPath,
+		Partial:   opts.partial,
+		Plugins:   opts.plugins,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return retrieveResults(opts, reader, ec)
+}
+
+func retrieveResults(opts retrieveFlags, r io.Reader, ec <-chan error) ([]string, error) {
+	var filesCreated []string
 	eg := &errgroup.Group{}
 	eg.Go(func() error { return <-ec })
 	eg.Go(func() error {
 		// This untars the request itself, which is tar'd as just part of the API request, not the sonobuoy logic.
-		filesCreated, err := client.UntarAll(r, opts.outputLocation, opts.filename)
-		if err != nil {
-			return err
+		var err error
+		filesCreated, err = client.UntarAll(r, opts.outputLocation, opts.filename)
+		return err
+	})
+
+	return filesCreated, eg.Wait()
+}
+
+// processRetrievedFiles either prints the names of the downloaded files or extracts them,
+// depending on the options.
+func processRetrievedFiles(opts retrieveFlags, filesCreated []string) error {
+	if !opts.extract {
+		// Only print the filename if not extracting. Allows capturing the filename for scripting.
+		for _, name := range filesCreated {
+			fmt.Println(name)
 		}
-		if !opts.extract {
-			// Only print the filename if not extracting. Allows capturing the filename for scripting.
-			for _, name := range filesCreated {
-				fmt.Println(name)
-			}
+		return nil
+	}
+
+	return extractFiles(filesCreated, opts.outputLocation)
+}
 
-			return nil
-		} else {
-			for _, filename := range filesCreated {
-				err := client.UntarFile(filename, opts.outputLocation, true)
-				if err != nil {
-					// Just log errors if it is just not cleaning up the file.
-					re, ok := err.(*client.DeletionError)
-					if ok {
-						errlog.LogError(re)
-					} else {
-						return err
-					}
-				}
+func extractFiles(filesCreated []string, outputLocation string) error {
+	for _, filename := range filesCreated {
+		err := client.UntarFile(filename, outputLocation, true)
+		if err != nil {
+			// Just log errors if it is just not cleaning up the file.
+			re, ok := err.(*client.DeletionError)
+			if ok {
+				errlog.LogError(re)
+			} else {
+				return err
 			}
+		}
+	}
+	return nil
+}
 
-			return err
+// retrieveFleetResults retrieves the results of each cluster in the fleet into its own directory
+// under the output location and then prints a summary of the fleet.
+func retrieveFleetResults(w io.Writer, opts retrieveFlags) error {
+	clusters, err := opts.fleet.clusters(opts.kubecfg)
+	if err != nil {
+		return err
+	}
+
+	res := forEachCluster(clusters, func(c fleetCluster, sbc *client.SonobuoyClient) fleetResult {
+		clusterOpts := opts
+		clusterOpts.outputLocation = filepath.Join(opts.outputLocation, c.name)
+
+		// The status is only used for the summary so failing to get it isn't fatal.
+		status, _ := sbc.GetStatus(&client.StatusConfig{Namespace: opts.namespace})
+
+		filesCreated, err := fetchResults(sbc, clusterOpts)
+		if err != nil {
+			return fleetResult{status: status, err: err}
 		}
+		if opts.extract {
+			if err := extractFiles(filesCreated, clusterOpts.outputLocation); err != nil {
+				return fleetResult{status: status, err: err}
+			}
+			return fleetResult{status: status, detail: clusterOpts.outputLocation}
+		}
+		return fleetResult{status: status, detail: strings.Join(filesCreated, ",")}
 	})
 
-	return eg.Wait()
+	if err := printFleetSummary(w, res); err != nil {
+		return err
+	}
+	if failed := fleetFailures(res); failed > 0 {
+		return fmt.Errorf("failed to retrieve results from %v of %v clusters", failed, len(res))
+	}
+	return nil
 }
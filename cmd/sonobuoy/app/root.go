@@ -70,6 +70,7 @@ func NewSonobuoyCommand() *cobra.Command {
 	cmds.AddCommand(NewCmdRetrieve())
 	cmds.AddCommand(NewCmdRun())
 	cmds.AddCommand(NewCmdImages())
+	cmds.AddCommand(NewCmdBundle())
 	cmds.AddCommand(NewCmdResults())
 	cmds.AddCommand(NewCmdSplat())
 	cmds.AddCommand(NewCmdWait())
@@ -18,14 +18,18 @@ package app
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	"github.com/vmware-tanzu/sonobuoy/pkg/client"
 	"github.com/vmware-tanzu/sonobuoy/pkg/errlog"
+	imagepkg "github.com/vmware-tanzu/sonobuoy/pkg/image"
 )
 
 var (
@@ -47,6 +51,7 @@ func givenAnyGenConfigFlags(fs *pflag.FlagSet, allowedFlagNames []string) bool {
 
 func NewCmdRun() *cobra.Command {
 	var f genFlags
+	var fleet fleetFlags
 	fs := GenFlagSet(&f, DetectRBACMode)
 	cmd := &cobra.Command{
 		Use:   "run",
@@ -54,11 +59,12 @@ func NewCmdRun() *cobra.Command {
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return checkFlagValidity(fs, f)
 		},
-		Run:  submitSonobuoyRun(&f),
+		Run:  submitSonobuoyRun(&f, &fleet),
 		Args: cobra.ExactArgs(0),
 	}
 
 	cmd.Flags().AddFlagSet(fs)
+	AddFleetFlags(&fleet, cmd.Flags())
 	return cmd
 }
 
@@ -69,8 +75,16 @@ func checkFlagValidity(fs *pflag.FlagSet, rf genFlags) error {
 	return nil
 }
 
-func submitSonobuoyRun(f *genFlags) func(cmd *cobra.Command, args []string) {
+func submitSonobuoyRun(f *genFlags, fleet *fleetFlags) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
+		if fleet.enabled() {
+			if err := submitFleetRun(os.Stdout, f, fleet); err != nil {
+				errlog.LogError(err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		sbc, err := getSonobuoyClientFromKubecfg(f.kubecfg)
 		if err != nil {
 			errlog.LogError(errors.Wrap(err, "could not create sonobuoy client"))
@@ -83,29 +97,138 @@ func submitSonobuoyRun(f *genFlags) func(cmd *cobra.Command, args []string) {
 			os.Exit(1)
 		}
 
-		if !contains(f.skipPreflight, "true") && !contains(f.skipPreflight, "*") {
-			pcfg := &client.PreflightConfig{
-				Namespace:           f.sonobuoyConfig.Namespace,
-				DNSNamespace:        f.dnsNamespace,
-				DNSPodLabels:        f.dnsPodLabels,
-				PreflightChecksSkip: f.skipPreflight,
-			}
-			if errs := sbc.PreflightChecks(pcfg); len(errs) > 0 {
-				errlog.LogError(errors.New("Preflight checks failed"))
-				for _, err := range errs {
-					errlog.LogError(err)
-				}
-				os.Exit(1)
+		manifest, err := sbc.Manifest(runCfg)
+		if err != nil {
+			errlog.LogError(errors.Wrap(err, "error attempting to run sonobuoy"))
+			os.Exit(1)
+		}
+
+		if errs := runPreflightChecks(sbc, f, manifest); len(errs) > 0 {
+			errlog.LogError(errors.New("Preflight checks failed"))
+			for _, err := range errs {
+				errlog.LogError(err)
 			}
+			os.Exit(1)
 		}
 
-		if err := sbc.Run(runCfg); err != nil {
+		if err := sbc.RunManifest(runCfg, manifest); err != nil {
 			errlog.LogError(errors.Wrap(err, "error attempting to run sonobuoy"))
 			os.Exit(1)
 		}
 	}
 }
 
+// runPreflightChecks runs the preflight checks against the manifest unless they have all been
+// skipped. Warnings are logged and only the failed checks are returned.
+func runPreflightChecks(sbc *client.SonobuoyClient, f *genFlags, manifest []byte) []error {
+	if contains(f.skipPreflight, "true") || contains(f.skipPreflight, "*") {
+		return nil
+	}
+	// Listing the e2e test images runs the conformance image so only do it when asked to.
+	var lister client.TestImageLister
+	if f.preflightImagePull == ImagePullCheckAll {
+		if c, err := imagepkg.NewRegistryClient("", nil); err == nil {
+			lister = registryImageResolver{client: c.(imagepkg.RegistryClient)}
+		}
+	}
+	errs := sbc.PreflightChecks(&client.PreflightConfig{
+		Namespace:           f.sonobuoyConfig.Namespace,
+		DNSNamespace:        f.dnsNamespace,
+		DNSPodLabels:        f.dnsPodLabels,
+		PreflightChecksSkip: f.skipPreflight,
+		NamespaceScoped:     f.sonobuoyConfig.NamespaceScoped,
+		Manifest:            manifest,
+		ImagePullCheck:      f.preflightImagePull == ImagePullCheckManifest || f.preflightImagePull == ImagePullCheckAll,
+		TestImageLister:     lister,
+	})
+	return preflightFailures(errs)
+}
+
+// preflightFailures logs the warnings and returns the remaining errors.
+func preflightFailures(errs []error) []error {
+	var failures []error
+	for _, err := range errs {
+		if client.IsPreflightWarning(err) {
+			logrus.Warnf("Preflight check warning: %v", err)
+			continue
+		}
+		failures = append(failures, err)
+	}
+	return failures
+}
+
+// submitFleetRun starts a run on each cluster of the fleet concurrently and reports the outcome
+// for each. If waiting, it also prints a summary of the results of the fleet.
+func submitFleetRun(w io.Writer, f *genFlags, fleet *fleetFlags) error {
+	clusters, err := fleet.clusters(f.kubecfg)
+	if err != nil {
+		return err
+	}
+
+	// Configs are generated one at a time since each cluster may resolve to different
+	// values (e.g. Kubernetes version) and the flags are not safe to share concurrently.
+	runCfgs := map[string]*client.RunConfig{}
+	manifests := map[string][]byte{}
+	genErrs := map[string]error{}
+	for _, c := range clusters {
+		clusterFlags := *f
+		clusterFlags.kubecfg = c.kubecfg
+		runCfg, err := clusterFlags.RunConfig()
+		if err != nil {
+			genErrs[c.name] = errors.Wrap(err, "could not generate config")
+			continue
+		}
+		// Interleaved spinners/progress from many clusters would be unreadable.
+		runCfg.WaitOutput = string(SilentOutputMode)
+		manifest, err := (&client.SonobuoyClient{}).Manifest(runCfg)
+		if err != nil {
+			genErrs[c.name] = errors.Wrap(err, "could not generate manifest")
+			continue
+		}
+		runCfgs[c.name] = runCfg
+		manifests[c.name] = manifest
+	}
+
+	res := forEachCluster(clusters, func(c fleetCluster, sbc *client.SonobuoyClient) fleetResult {
+		runCfg, ok := runCfgs[c.name]
+		if !ok {
+			return fleetResult{err: genErrs[c.name]}
+		}
+
+		if errs := runPreflightChecks(sbc, f, manifests[c.name]); len(errs) > 0 {
+			msgs := []string{}
+			for _, err := range errs {
+				msgs = append(msgs, err.Error())
+			}
+			return fleetResult{err: fmt.Errorf("preflight checks failed: %v", strings.Join(msgs, "; "))}
+		}
+
+		if err := sbc.RunManifest(runCfg, manifests[c.name]); err != nil {
+			return fleetResult{err: errors.Wrap(err, "error attempting to run sonobuoy")}
+		}
+		if runCfg.Wait == 0 {
+			return fleetResult{detail: "started"}
+		}
+
+		status, err := sbc.GetStatus(&client.StatusConfig{Namespace: runCfg.GetNamespace()})
+		return fleetResult{status: status, detail: "finished", err: err}
+	})
+
+	if f.wait > 0 {
+		err = printFleetSummary(w, res)
+	} else {
+		err = printFleetResults(w, res)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed := fleetFailures(res); failed > 0 {
+		return fmt.Errorf("failed to run sonobuoy on %v of %v clusters", failed, len(res))
+	}
+	return nil
+}
+
 func stringInList(list []string, s string) bool {
 	for _, v := range list {
 		if v == s {
@@ -19,21 +19,57 @@ package app
 import (
 	"archive/tar"
 	"compress/gzip"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/discovery"
 	"github.com/vmware-tanzu/sonobuoy/pkg/errlog"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/aggregation"
 
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	// partialStatusFile is the name of the file in the meta directory which holds the status
+	// of the run at the time a partial tarball was created.
+	partialStatusFile = "status.json"
+)
+
+type splatFlags struct {
+	file      string
+	offset    int64
+	partial   bool
+	namespace string
+	plugins   []string
+	kubecfg   Kubeconfig
+}
+
 func NewCmdSplat() *cobra.Command {
+	var f splatFlags
 	cmd := &cobra.Command{
 		Use:   "splat AGGREGATOR_RESULTS_PATH",
 		Short: "Reads all tarballs in the specified directory and prints the content to STDOUT (for internal use)",
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := runSplat(args[0]); err != nil {
+			var err error
+			switch {
+			case f.partial:
+				err = runPartialSplat(args[0], f)
+			case len(f.file) > 0:
+				err = runFileSplat(os.Stdout, args[0], f.file, f.offset)
+			default:
+				err = runSplat(args[0])
+			}
+			if err != nil {
 				errlog.LogError(err)
 				os.Exit(1)
 			}
@@ -41,6 +77,25 @@ func NewCmdSplat() *cobra.Command {
 		Hidden: true,
 		Args:   cobra.ExactArgs(1),
 	}
+
+	cmd.Flags().StringVar(
+		&f.file, "file", "",
+		"If set, prints the raw contents of the named tarball in the directory instead of an archive of all tarballs.",
+	)
+	cmd.Flags().Int64Var(
+		&f.offset, "offset", 0,
+		"Byte offset to start printing the file from. Used to resume interrupted downloads. Requires --file.",
+	)
+	cmd.Flags().BoolVar(
+		&f.partial, "partial", false,
+		"If true, prints a tarball of the results gathered so far by the given plugins instead of the final results.",
+	)
+	cmd.Flags().StringArrayVar(
+		&f.plugins, pluginFlag, []string{},
+		"Plugin to include in the partial results. Can be specified multiple times.",
+	)
+	AddNamespaceFlag(&f.namespace, cmd.Flags())
+	AddKubeconfigFlag(&f.kubecfg, cmd.Flags())
 	return cmd
 }
 
@@ -57,6 +112,179 @@ func runSplat(dirPath string) error {
 	return nil
 }
 
+// runFileSplat writes the raw contents of the named file in dirPath, starting at offset, to w.
+func runFileSplat(w io.Writer, dirPath, name string, offset int64) error {
+	if name != filepath.Base(name) {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+
+	f, err := os.Open(filepath.Join(dirPath, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return errors.Wrapf(err, "seeking to offset %v", offset)
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// runPartialSplat builds a tarball of the results that the given plugins have reported so far,
+// along with the current run log and status, and prints it to STDOUT in the same format as
+// runSplat. The tarball is built outside of dirPath so that the final results are unaffected.
+func runPartialSplat(dirPath string, f splatFlags) error {
+	runDir, err := inProgressRunDir(dirPath)
+	if err != nil {
+		return err
+	}
+
+	// Status is a nice-to-have; don't fail the retrieval if we can't get it.
+	var status []byte
+	if s, err := getPartialStatus(f.kubecfg, f.namespace); err != nil {
+		logrus.Warningf("Unable to get run status, it will not be included in the partial results: %v", err)
+	} else {
+		status = s
+	}
+
+	tmpDir, err := os.MkdirTemp("", "sonobuoy-partial-")
+	if err != nil {
+		return errors.Wrap(err, "creating temporary directory for partial results")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filename := fmt.Sprintf("%v_sonobuoy_%v_partial.tar.gz", time.Now().Format("200601021504"), filepath.Base(runDir))
+	tb := filepath.Join(tmpDir, filename)
+	outfile, err := os.Create(tb)
+	if err != nil {
+		return errors.Wrapf(err, "creating tarball %v", tb)
+	}
+	if err := writePartialTarball(outfile, runDir, f.plugins, status); err != nil {
+		outfile.Close()
+		return err
+	}
+	if err := outfile.Close(); err != nil {
+		return errors.Wrapf(err, "closing tarball %v", tb)
+	}
+
+	return loadResults(os.Stdout, []string{tb})
+}
+
+// inProgressRunDir finds the directory the aggregator is writing results to for the
+// current run; it is the only directory in dirPath with a meta directory in it.
+func inProgressRunDir(dirPath string) (string, error) {
+	metaDirs, err := filepath.Glob(filepath.Join(dirPath, "*", discovery.MetaLocation))
+	if err != nil {
+		return "", err
+	}
+	if len(metaDirs) == 0 {
+		return "", fmt.Errorf("no in-progress results found in %v", dirPath)
+	}
+	return filepath.Dir(metaDirs[0]), nil
+}
+
+func getPartialStatus(kubecfg Kubeconfig, namespace string) ([]byte, error) {
+	restConfig, err := kubecfg.Get()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting kubeconfig")
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating kubernetes client")
+	}
+	status, _, err := aggregation.GetStatus(client, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(status)
+}
+
+// writePartialTarball writes a gzipped tarball to w which contains the results directories of the
+// given plugins, the run log, and, if non-nil, the status. Paths mirror those of the final tarball.
+func writePartialTarball(w io.Writer, runDir string, plugins []string, status []byte) error {
+	gzipWriter := gzip.NewWriter(w)
+	defer gzipWriter.Close()
+
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
+
+	for _, p := range plugins {
+		pluginDir := filepath.Join(runDir, results.PluginsDir, p)
+		if _, err := os.Stat(pluginDir); os.IsNotExist(err) {
+			logrus.Warningf("No results found for plugin %v", p)
+			continue
+		}
+		if err := addPathToTarball(tarWriter, runDir, pluginDir); err != nil {
+			return errors.Wrapf(err, "adding results of plugin %v", p)
+		}
+	}
+
+	runLog := filepath.Join(runDir, discovery.MetaLocation, "run.log")
+	if _, err := os.Stat(runLog); err == nil {
+		if err := addPathToTarball(tarWriter, runDir, runLog); err != nil {
+			return errors.Wrap(err, "adding run log")
+		}
+	}
+
+	if status != nil {
+		header := &tar.Header{
+			Name:    path.Join(discovery.MetaLocation, partialStatusFile),
+			Mode:    0644,
+			Size:    int64(len(status)),
+			ModTime: time.Now(),
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return errors.Wrap(err, "writing header for status")
+		}
+		if _, err := tarWriter.Write(status); err != nil {
+			return errors.Wrap(err, "writing status")
+		}
+	}
+
+	return nil
+}
+
+// addPathToTarball adds the file or directory at root (recursively) to the tarball, naming
+// entries relative to baseDir.
+func addPathToTarball(tarWriter *tar.Writer, baseDir, root string) error {
+	return filepath.Walk(root, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() && !fi.Mode().IsDir() {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(fi, fi.Name())
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(baseDir, file)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if fi.IsDir() && !strings.HasSuffix(header.Name, "/") {
+			header.Name += "/"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tarWriter, f)
+		return err
+	})
+}
+
 func loadResults(w *os.File, filenames []string) error {
 	gzipWriter := gzip.NewWriter(w)
 	defer gzipWriter.Close()
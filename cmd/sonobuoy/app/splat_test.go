@@ -0,0 +1,128 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWritePartialTarball(t *testing.T) {
+	dir := t.TempDir()
+	runDir := filepath.Join(dir, "uuid")
+	for _, f := range []string{
+		"meta/run.log",
+		"meta/query-time.json",
+		"plugins/systemd-logs/results/node1/out.json",
+		"plugins/e2e/results/global/e2e.log",
+	} {
+		p := filepath.Join(runDir, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := inProgressRunDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error finding run dir: %v", err)
+	}
+	if found != runDir {
+		t.Fatalf("Expected run dir %v but got %v", runDir, found)
+	}
+
+	var buf bytes.Buffer
+	if err := writePartialTarball(&buf, runDir, []string{"systemd-logs", "missing"}, []byte(`{"status":"running"}`)); err != nil {
+		t.Fatalf("Unexpected error writing tarball: %v", err)
+	}
+
+	gzr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gzr)
+	files := []string{}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Typeflag == tar.TypeReg {
+			files = append(files, h.Name)
+		}
+	}
+	sort.Strings(files)
+
+	expected := []string{
+		"meta/run.log",
+		"meta/status.json",
+		"plugins/systemd-logs/results/node1/out.json",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected files %v but got %v", expected, files)
+	}
+}
+
+func TestInProgressRunDirMissing(t *testing.T) {
+	if _, err := inProgressRunDir(t.TempDir()); err == nil {
+		t.Error("Expected error when no run directory exists but got nil")
+	}
+}
+
+func TestRunFileSplat(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "results.tar.gz"), []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		desc      string
+		name      string
+		offset    int64
+		expect    string
+		expectErr bool
+	}{
+		{desc: "Whole file", name: "results.tar.gz", expect: "0123456789"},
+		{desc: "From offset", name: "results.tar.gz", offset: 4, expect: "456789"},
+		{desc: "Missing file", name: "missing.tar.gz", expectErr: true},
+		{desc: "Path traversal", name: "../results.tar.gz", expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := runFileSplat(&buf, dir, tc.name, tc.offset)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("Expected error: %v but got %v", tc.expectErr, err)
+			}
+			if buf.String() != tc.expect {
+				t.Errorf("Expected %q but got %q", tc.expect, buf.String())
+			}
+		})
+	}
+}
@@ -39,6 +39,7 @@ type statusFlags struct {
 	kubecfg   Kubeconfig
 	showAll   bool
 	json      bool
+	fleet     fleetFlags
 }
 
 type pluginSummaries []pluginSummary
@@ -87,6 +88,7 @@ func NewCmdStatus() *cobra.Command {
 		&f.json, "json", false,
 		"Print the status object as json",
 	)
+	AddFleetFlags(&f.fleet, flags)
 
 	return cmd
 }
@@ -95,6 +97,10 @@ func NewCmdStatus() *cobra.Command {
 // also --show-all
 func getStatus(f *statusFlags) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
+		if f.fleet.enabled() {
+			os.Exit(getFleetStatus(os.Stdout, f))
+		}
+
 		sbc, err := getSonobuoyClientFromKubecfg(f.kubecfg)
 		if err != nil {
 			errlog.LogError(errors.Wrap(err, "could not create sonobuoy client"))
@@ -125,6 +131,47 @@ func getStatus(f *statusFlags) func(cmd *cobra.Command, args []string) {
 	}
 }
 
+// getFleetStatus prints a combined status table and a summary of the runs on each cluster
+// of the fleet. Returns the exit code; non-zero if any status could not be retrieved or failed.
+func getFleetStatus(w io.Writer, f *statusFlags) int {
+	clusters, err := f.fleet.clusters(f.kubecfg)
+	if err != nil {
+		errlog.LogError(err)
+		return 1
+	}
+
+	res := forEachCluster(clusters, func(c fleetCluster, sbc *client.SonobuoyClient) fleetResult {
+		status, err := sbc.GetStatus(&client.StatusConfig{Namespace: f.namespace})
+		return fleetResult{status: status, err: err}
+	})
+
+	if f.json {
+		out := map[string]*aggregation.Status{}
+		for _, r := range res {
+			out[r.cluster] = r.status
+		}
+		err = json.NewEncoder(w).Encode(out)
+	} else {
+		err = printFleetStatus(w, res)
+		if err == nil {
+			fmt.Fprintln(w)
+			err = printFleetSummary(w, res)
+		}
+	}
+	if err != nil {
+		errlog.LogError(err)
+		return 1
+	}
+
+	code := 0
+	for _, r := range res {
+		if r.err != nil || exitCode(r.status) != 0 {
+			code = 1
+		}
+	}
+	return code
+}
+
 func exitCode(status *aggregation.Status) int {
 	// Allow status==nil to be non-error path. Explicit errors have been handled
 	// before this and we are only supposed to be erroring here if we can tell
@@ -207,6 +254,23 @@ func printAll(w io.Writer, status *aggregation.Status) error {
 func printSummary(w io.Writer, status *aggregation.Status) error {
 	tw := defaultTabWriter(w)
 
+	summaries := summarizeStatus(status)
+	fmt.Fprintf(tw, "PLUGIN\tSTATUS\tRESULT\tCOUNT\tPROGRESS\t\n")
+	for _, summary := range summaries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t\n", summary.plugin, summary.status, summary.result, summary.count, summary.progressMsg)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return errors.Wrap(err, "couldn't write status out")
+	}
+
+	fmt.Fprintf(w, "\n%s\n", humanReadableStatus(status.Status))
+	return nil
+}
+
+// summarizeStatus counts the unique combinations of status/result for each plugin,
+// sorted by plugin name.
+func summarizeStatus(status *aggregation.Status) pluginSummaries {
 	// [myPlugin][complete:passed]# matching those keys
 	totals := map[string]map[string]int{}
 
@@ -246,17 +310,7 @@ func printSummary(w io.Writer, status *aggregation.Status) error {
 		}
 	}
 	sort.Sort(summaries)
-	fmt.Fprintf(tw, "PLUGIN\tSTATUS\tRESULT\tCOUNT\tPROGRESS\t\n")
-	for _, summary := range summaries {
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t\n", summary.plugin, summary.status, summary.result, summary.count, summary.progressMsg)
-	}
-
-	if err := tw.Flush(); err != nil {
-		return errors.Wrap(err, "couldn't write status out")
-	}
-
-	fmt.Fprintf(w, "\n%s\n", humanReadableStatus(status.Status))
-	return nil
+	return summaries
 }
 
 func defaultTabWriter(w io.Writer) *tabwriter.Writer {
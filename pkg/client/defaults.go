@@ -52,5 +52,6 @@ func NewLogConfig() *LogConfig {
 		Follow:    false,
 		Namespace: config.DefaultNamespace,
 		Out:       os.Stdout,
+		Format:    LogFormatText,
 	}
 }
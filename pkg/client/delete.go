@@ -61,13 +61,22 @@ func (c *SonobuoyClient) Delete(cfg *DeleteConfig) error {
 	}
 
 	conditions := []ConditionFuncWithProgress{}
-	nsCondition, err := cleanupNamespace(cfg.Namespace, client, cfg)
-	if err != nil {
-		return err
+	if cfg.NamespaceScoped {
+		// The namespace isn't ours to delete and there are no cluster-scoped resources.
+		resCondition, err := cleanupNamespacedResources(cfg.Namespace, client, cfg)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, resCondition)
+	} else {
+		nsCondition, err := cleanupNamespace(cfg.Namespace, client, cfg)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, nsCondition)
 	}
-	conditions = append(conditions, nsCondition)
 
-	if cfg.EnableRBAC {
+	if cfg.EnableRBAC && !cfg.NamespaceScoped {
 		rbacCondition, err := deleteRBAC(client, cfg)
 		if err != nil {
 			return err
@@ -155,6 +164,69 @@ func cleanupNamespace(namespace string, client kubernetes.Interface, cfg *Delete
 	return nsDeletedCondition, nil
 }
 
+// cleanupNamespacedResources deletes the resources Sonobuoy created within the namespace, identified by
+// their labels. Plugin pods are owned by the aggregator pod and are garbage collected along with it.
+func cleanupNamespacedResources(namespace string, client kubernetes.Interface, cfg *DeleteConfig) (ConditionFuncWithProgress, error) {
+	selector := metav1.AddLabelToSelector(
+		&metav1.LabelSelector{},
+		clusterRoleFieldName,
+		clusterRoleFieldValue,
+	)
+	listOpts := metav1.ListOptions{LabelSelector: metav1.FormatLabelSelector(selector)}
+
+	var dryRun []string
+	if cfg.DryRun {
+		dryRun = append(dryRun, metav1.DryRunAll)
+	}
+	deleteOpts := metav1.DeleteOptions{DryRun: dryRun}
+
+	podsDeletedCondition := func() (string, bool, error) {
+		pods, err := client.CoreV1().Pods(namespace).List(context.TODO(), listOpts)
+		if err != nil {
+			return fmt.Sprintf("Error encountered when checking for pods in namespace %q: %v", namespace, err), false, err
+		}
+		if len(pods.Items) > 0 {
+			var names []string
+			for _, p := range pods.Items {
+				names = append(names, p.Name)
+			}
+			return fmt.Sprintf("Still found %v pods to delete in namespace %q: %v", len(names), namespace, names), false, nil
+		}
+		return fmt.Sprintf("Deleted all Sonobuoy resources in namespace %q.", namespace), true, nil
+	}
+
+	deletions := []struct {
+		kind string
+		fn   func(context.Context, metav1.DeleteOptions, metav1.ListOptions) error
+	}{
+		{"pods", client.CoreV1().Pods(namespace).DeleteCollection},
+		{"configmaps", client.CoreV1().ConfigMaps(namespace).DeleteCollection},
+		{"serviceaccounts", client.CoreV1().ServiceAccounts(namespace).DeleteCollection},
+		{"rolebindings", client.RbacV1().RoleBindings(namespace).DeleteCollection},
+		{"roles", client.RbacV1().Roles(namespace).DeleteCollection},
+	}
+	for _, d := range deletions {
+		err := d.fn(context.TODO(), deleteOpts, listOpts)
+		if err := logDelete(logrus.WithFields(logrus.Fields{"kind": d.kind, "namespace": namespace, "dry-run": cfg.DryRun}), err); err != nil {
+			return podsDeletedCondition, errors.Wrapf(err, "failed to delete %v", d.kind)
+		}
+	}
+
+	// Services don't support DeleteCollection.
+	services, err := client.CoreV1().Services(namespace).List(context.TODO(), listOpts)
+	if err != nil {
+		return podsDeletedCondition, errors.Wrap(err, "failed to fetch services for deletion")
+	}
+	for _, svc := range services.Items {
+		err := client.CoreV1().Services(namespace).Delete(context.TODO(), svc.Name, deleteOpts)
+		if err := logDelete(logrus.WithFields(logrus.Fields{"kind": "service", "name": svc.Name, "namespace": namespace, "dry-run": cfg.DryRun}), err); err != nil {
+			return podsDeletedCondition, errors.Wrap(err, "failed to delete service")
+		}
+	}
+
+	return podsDeletedCondition, nil
+}
+
 func deleteRBAC(client kubernetes.Interface, cfg *DeleteConfig) (ConditionFuncWithProgress, error) {
 	// ClusterRole and ClusterRoleBindings aren't namespaced, so delete them separately.
 	selector := metav1.AddLabelToSelector(
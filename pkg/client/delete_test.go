@@ -17,10 +17,14 @@ limitations under the License.
 package client
 
 import (
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"context"
 	"strings"
 	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestDeleteInvalidConfig(t *testing.T) {
@@ -39,6 +43,11 @@ func TestDeleteInvalidConfig(t *testing.T) {
 			config:           &DeleteConfig{},
 			expectedErrorMsg: "config validation failed",
 		},
+		{
+			desc:             "Deleting e2e namespaces is not possible when namespace-scoped",
+			config:           &DeleteConfig{Namespace: "tenant", NamespaceScoped: true, DeleteAll: true},
+			expectedErrorMsg: "e2e namespaces can not be deleted when namespace-scoped",
+		},
 	}
 
 	c, err := NewSonobuoyClient(nil, nil)
@@ -135,3 +144,47 @@ func TestIsE2ENamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestCleanupNamespacedResources(t *testing.T) {
+	labels := map[string]string{"component": "sonobuoy"}
+	client := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "sonobuoy-aggregator", Namespace: "tenant", Labels: labels}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "tenant"}},
+	)
+
+	if _, err := cleanupNamespacedResources("tenant", client, &DeleteConfig{Namespace: "tenant", NamespaceScoped: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	deletedCollections := map[string]bool{}
+	for _, a := range client.Actions() {
+		if a.GetNamespace() != "tenant" {
+			t.Errorf("Expected all actions to be within the namespace but got %v %v in %q", a.GetVerb(), a.GetResource().Resource, a.GetNamespace())
+		}
+		switch action := a.(type) {
+		case k8stesting.DeleteCollectionAction:
+			if sel := action.GetListRestrictions().Labels.String(); sel != "component=sonobuoy" {
+				t.Errorf("Expected label selector component=sonobuoy for %v but got %q", a.GetResource().Resource, sel)
+			}
+			deletedCollections[a.GetResource().Resource] = true
+		case k8stesting.DeleteAction:
+			if action.GetResource().Resource == "namespaces" {
+				t.Errorf("Expected the namespace to be left in place")
+			}
+		}
+	}
+	for _, r := range []string{"pods", "configmaps", "serviceaccounts", "roles", "rolebindings"} {
+		if !deletedCollections[r] {
+			t.Errorf("Expected %v to be deleted", r)
+		}
+	}
+
+	services, err := client.CoreV1().Services("tenant").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services.Items) != 1 || services.Items[0].Name != "unrelated" {
+		t.Errorf("Expected only the unrelated service to remain but got %v", services.Items)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// kubeTestRepoListEnv points the e2e tests at a file in the plugin's configmap, which doesn't
+	// exist when listing the test images locally.
+	kubeTestRepoListEnv = "KUBE_TEST_REPO_LIST"
+)
+
+// imagePinner resolves images and remembers the digest of each.
+type imagePinner struct {
+	resolver DigestResolver
+	digests  map[string]string
+}
+
+// pin returns the image referenced by its digest. Images which are already referenced by digest
+// are returned unchanged.
+func (p *imagePinner) pin(image string) (string, error) {
+	if len(image) == 0 || strings.Contains(image, "@") {
+		return image, nil
+	}
+	d, err := p.resolve(image)
+	if err != nil {
+		return "", err
+	}
+	return imageName(image) + "@" + d, nil
+}
+
+func (p *imagePinner) resolve(image string) (string, error) {
+	if d, ok := p.digests[image]; ok {
+		return d, nil
+	}
+	d, err := p.resolver.ResolveDigest(image)
+	if err != nil {
+		return "", err
+	}
+	p.digests[image] = d
+	return d, nil
+}
+
+// pinImageDigests rewrites the images of the aggregator, workers and plugins to reference their
+// digests and records them in the config. The images pulled by the e2e tests are resolved and
+// recorded too, but they are chosen by the tests so can't be rewritten.
+func pinImageDigests(cfg *GenConfig, plugins []*manifest.Manifest) error {
+	p := &imagePinner{resolver: cfg.DigestResolver, digests: map[string]string{}}
+
+	var err error
+	if cfg.Config.WorkerImage, err = p.pin(cfg.Config.WorkerImage); err != nil {
+		return err
+	}
+
+	for _, plugin := range plugins {
+		conformanceImage := plugin.Spec.Image
+		if plugin.Spec.Image, err = p.pin(plugin.Spec.Image); err != nil {
+			return errors.Wrapf(err, "plugin %v", plugin.SonobuoyConfig.PluginName)
+		}
+		if plugin.PodSpec != nil {
+			for i := range plugin.PodSpec.InitContainers {
+				if plugin.PodSpec.InitContainers[i].Image, err = p.pin(plugin.PodSpec.InitContainers[i].Image); err != nil {
+					return errors.Wrapf(err, "plugin %v", plugin.SonobuoyConfig.PluginName)
+				}
+			}
+			for i := range plugin.PodSpec.Containers {
+				if plugin.PodSpec.Containers[i].Image, err = p.pin(plugin.PodSpec.Containers[i].Image); err != nil {
+					return errors.Wrapf(err, "plugin %v", plugin.SonobuoyConfig.PluginName)
+				}
+			}
+		}
+
+		if plugin.SonobuoyConfig.PluginName != e2ePluginName {
+			continue
+		}
+		testImages, err := listTestImages(cfg.DigestResolver, conformanceImage, plugin.Spec.Env)
+		if err != nil {
+			return err
+		}
+		for _, image := range testImages {
+			if _, err := p.resolve(image); err != nil {
+				return errors.Wrap(err, "e2e test image")
+			}
+		}
+	}
+
+	cfg.Config.ImageDigests = p.digests
+	return nil
+}
+
+// listTestImages returns the images the e2e tests in the conformance image will pull given the env
+// of the e2e plugin. Placeholders for images which can't be pulled are omitted.
+func listTestImages(lister TestImageLister, conformanceImage string, pluginEnv []corev1.EnvVar) ([]string, error) {
+	env := map[string]string{}
+	for _, e := range pluginEnv {
+		if e.Name != kubeTestRepoListEnv {
+			env[e.Name] = e.Value
+		}
+	}
+	images, err := lister.TestImages(conformanceImage, env)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing e2e test images")
+	}
+	ret := []string{}
+	for _, image := range images {
+		if !strings.HasPrefix(image, "invalid") {
+			ret = append(ret, image)
+		}
+	}
+	return ret, nil
+}
+
+// imageName returns the image without its tag.
+func imageName(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
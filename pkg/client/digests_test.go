@@ -0,0 +1,143 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/client"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+type fakeDigestResolver struct {
+	digests    map[string]string
+	testImages []string
+
+	resolved         []string
+	conformanceImage string
+	env              map[string]string
+}
+
+func (f *fakeDigestResolver) ResolveDigest(image string) (string, error) {
+	f.resolved = append(f.resolved, image)
+	d, ok := f.digests[image]
+	if !ok {
+		return "", errors.New("not found: " + image)
+	}
+	return d, nil
+}
+
+func (f *fakeDigestResolver) TestImages(conformanceImage string, env map[string]string) ([]string, error) {
+	f.conformanceImage, f.env = conformanceImage, env
+	return f.testImages, nil
+}
+
+func TestGenerateManifestPinDigests(t *testing.T) {
+	newConfig := func(resolver client.DigestResolver) *client.GenConfig {
+		cfg := config.New()
+		cfg.WorkerImage = "sonobuoy/sonobuoy:v1"
+		return &client.GenConfig{
+			Config:         cfg,
+			KubeVersion:    "v1.27.1",
+			DynamicPlugins: []string{"e2e"},
+			StaticPlugins: []*manifest.Manifest{
+				{
+					SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "custom", Driver: "Job"},
+					Spec:           manifest.Container{Container: v1.Container{Name: "plugin", Image: "example.com/custom:v2"}},
+					PodSpec: &manifest.PodSpec{PodSpec: v1.PodSpec{
+						InitContainers: []v1.Container{{Name: "init", Image: "example.com:5000/init@sha256:aaa"}},
+						Containers:     []v1.Container{{Name: "sidecar", Image: "sonobuoy/sonobuoy:v1"}},
+					}},
+				},
+			},
+			DigestResolver: resolver,
+		}
+	}
+
+	t.Run("images are pinned and recorded", func(t *testing.T) {
+		resolver := &fakeDigestResolver{
+			digests: map[string]string{
+				"sonobuoy/sonobuoy:v1":                      "sha256:111",
+				"example.com/custom:v2":                     "sha256:222",
+				"registry.k8s.io/conformance:v1.27.1":       "sha256:333",
+				"registry.k8s.io/e2e-test-images/agnhost:2": "sha256:444",
+			},
+			testImages: []string{"registry.k8s.io/e2e-test-images/agnhost:2", "invalid.registry.k8s.io/invalid/alpine:3.1"},
+		}
+		cfg := newConfig(resolver)
+		b, plugins, err := (&client.SonobuoyClient{}).GenerateManifestAndPlugins(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cfg.Config.WorkerImage != "sonobuoy/sonobuoy@sha256:111" {
+			t.Errorf("Expected worker image to be pinned but got %v", cfg.Config.WorkerImage)
+		}
+		images := map[string]string{}
+		for _, p := range plugins {
+			images[p.SonobuoyConfig.PluginName] = p.Spec.Image
+		}
+		expectImages := map[string]string{
+			"custom": "example.com/custom@sha256:222",
+			"e2e":    "registry.k8s.io/conformance@sha256:333",
+		}
+		if !reflect.DeepEqual(images, expectImages) {
+			t.Errorf("Expected plugin images %v but got %v", expectImages, images)
+		}
+		for _, p := range plugins {
+			if p.SonobuoyConfig.PluginName != "custom" {
+				continue
+			}
+			if got := p.PodSpec.InitContainers[0].Image; got != "example.com:5000/init@sha256:aaa" {
+				t.Errorf("Expected image referenced by digest to be unchanged but got %v", got)
+			}
+			if got := p.PodSpec.Containers[0].Image; got != "sonobuoy/sonobuoy@sha256:111" {
+				t.Errorf("Expected sidecar image to be pinned but got %v", got)
+			}
+		}
+
+		if !reflect.DeepEqual(cfg.Config.ImageDigests, resolver.digests) {
+			t.Errorf("Expected digests %v to be recorded but got %v", resolver.digests, cfg.Config.ImageDigests)
+		}
+		if len(resolver.resolved) != len(resolver.digests) {
+			t.Errorf("Expected each image to be resolved once but resolved %v", resolver.resolved)
+		}
+		if resolver.conformanceImage != "registry.k8s.io/conformance:v1.27.1" {
+			t.Errorf("Expected test images to be listed from the tagged conformance image but got %v", resolver.conformanceImage)
+		}
+		if _, ok := resolver.env["KUBE_TEST_REPO_LIST"]; ok {
+			t.Errorf("Expected KUBE_TEST_REPO_LIST to be excluded from the env but got %v", resolver.env)
+		}
+		if !strings.Contains(string(b), `"ImageDigests":{`) {
+			t.Errorf("Expected the digests to be in the generated config")
+		}
+	})
+
+	t.Run("resolution errors are returned", func(t *testing.T) {
+		resolver := &fakeDigestResolver{digests: map[string]string{"sonobuoy/sonobuoy:v1": "sha256:111"}}
+		_, _, err := (&client.SonobuoyClient{}).GenerateManifestAndPlugins(newConfig(resolver))
+		if err == nil || !strings.Contains(err.Error(), "example.com/custom:v2") {
+			t.Errorf("Expected error about the custom plugin image but got %v", err)
+		}
+	})
+}
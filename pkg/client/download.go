@@ -0,0 +1,254 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	pluginaggregation "github.com/vmware-tanzu/sonobuoy/pkg/plugin/aggregation"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// partialDownloadSuffix is appended to the name of a tarball while it is being downloaded.
+	// If a download is interrupted, the next attempt resumes from the end of this file.
+	partialDownloadSuffix = ".part"
+
+	// DefaultDownloadRetries is the default number of times an interrupted download is resumed.
+	DefaultDownloadRetries = 5
+)
+
+var (
+	// ErrResultsNotReady is returned when trying to download results before the run has completed.
+	ErrResultsNotReady = errors.New("results not ready yet")
+
+	// ErrNoTarballInfo is returned when the aggregator has not recorded the size and checksum of
+	// the results tarball (e.g. older versions of Sonobuoy) so it cannot be verified.
+	ErrNoTarballInfo = errors.New("aggregator did not record information about the results tarball")
+
+	// defaultDownloadBackoff is the backoff between attempts to resume a download.
+	defaultDownloadBackoff = wait.Backoff{
+		Duration: 2 * time.Second,
+		Factor:   2.0,
+		Jitter:   0.1,
+		Cap:      time.Minute,
+	}
+)
+
+// VerificationError is returned when the downloaded tarball does not match the size or
+// checksum recorded by the aggregator.
+type VerificationError struct {
+	Filename string
+	Field    string
+	Expected string
+	Actual   string
+}
+
+func (v *VerificationError) Error() string {
+	return fmt.Sprintf("downloaded file %q failed verification: expected %v %v but got %v", v.Filename, v.Field, v.Expected, v.Actual)
+}
+
+// fetchFunc returns a reader of the results tarball starting at the given byte offset as well as a
+// channel of errors encountered while streaming it.
+type fetchFunc func(offset int64) (io.Reader, <-chan error, error)
+
+// DownloadResults downloads the results tarball of a completed run into cfg.OutputDir. If the transfer
+// is interrupted it will be resumed from the last byte received, up to cfg.Retries times with an
+// exponential backoff. The file is verified against the size and SHA256 recorded in the run status.
+// Returns the path of the downloaded file.
+func (c *SonobuoyClient) DownloadResults(cfg *DownloadConfig) (string, error) {
+	if cfg == nil {
+		return "", errors.New("nil DownloadConfig provided")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return "", errors.Wrap(err, "config validation failed")
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		return "", err
+	}
+
+	status, _, err := pluginaggregation.GetStatus(client, cfg.Namespace)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get the status of the run")
+	}
+	if status.Status != pluginaggregation.CompleteStatus {
+		return "", ErrResultsNotReady
+	}
+	if status.Tarball.Name == "" || status.Tarball.SHA256 == "" {
+		return "", ErrNoTarballInfo
+	}
+
+	name := status.Tarball.Name
+	if cfg.Filename != "" {
+		name = cfg.Filename
+	}
+	target := filepath.Join(cfg.OutputDir, name)
+
+	fetch := func(offset int64) (io.Reader, <-chan error, error) {
+		return c.execOnAggregator(client, cfg.Namespace, fileCmd(cfg.Path, status.Tarball.Name, offset))
+	}
+
+	backoff := defaultDownloadBackoff
+	backoff.Steps = cfg.Retries
+	return target, downloadWithResume(fetch, target, status.Tarball, backoff)
+}
+
+// downloadWithResume downloads the file described by info to target, resuming from any data already
+// in the partially downloaded file. Only once the file is complete and verified is it moved to target.
+func downloadWithResume(fetch fetchFunc, target string, info pluginaggregation.TarInfo, backoff wait.Backoff) error {
+	partial := target + partialDownloadSuffix
+	if err := os.MkdirAll(filepath.Dir(partial), 0755); err != nil {
+		return errors.Wrap(err, "creating output directory")
+	}
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		offset, err := fileSize(partial)
+		if err != nil {
+			return err
+		}
+
+		// Can't resume from a file larger than the expected one; it must be from a different run.
+		if offset > info.Size {
+			logrus.Warningf("Partially downloaded file %v is larger than expected, restarting download", partial)
+			if err := os.Remove(partial); err != nil {
+				return errors.Wrapf(err, "removing partially downloaded file %v", partial)
+			}
+			offset = 0
+		}
+
+		if offset == info.Size {
+			break
+		}
+
+		if attempt > 0 {
+			if lastErr == nil {
+				lastErr = fmt.Errorf("transfer ended after %v of %v bytes", offset, info.Size)
+			}
+			if backoff.Steps < 1 {
+				return errors.Wrapf(lastErr, "failed to download results after %v attempts", attempt)
+			}
+			d := backoff.Step()
+			logrus.Warningf("Download of %v interrupted at byte %v of %v (%v); resuming in %v", info.Name, offset, info.Size, lastErr, d)
+			time.Sleep(d)
+		}
+
+		lastErr = appendFrom(fetch, partial, offset)
+	}
+
+	if err := verifyFile(partial, info); err != nil {
+		// Corrupt data can't be resumed from; ensure the next attempt starts fresh.
+		if rmErr := os.Remove(partial); rmErr != nil {
+			logrus.Warningf("Failed to remove file %v which failed verification: %v", partial, rmErr)
+		}
+		if v, ok := err.(*VerificationError); ok {
+			v.Filename = target
+		}
+		return err
+	}
+
+	return errors.Wrapf(os.Rename(partial, target), "moving downloaded file to %v", target)
+}
+
+// appendFrom fetches the data starting at offset and appends it to the file at path.
+func appendFrom(fetch fetchFunc, path string, offset int64) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "opening file %v", path)
+	}
+	defer f.Close()
+
+	r, ec, err := fetch(offset)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		return <-ec
+	}
+
+	_, copyErr := io.Copy(f, r)
+	if copyErr != nil {
+		// Unblock the writer so the stream can finish.
+		if pr, ok := r.(*io.PipeReader); ok {
+			pr.CloseWithError(copyErr)
+		}
+	}
+	streamErr := <-ec
+
+	switch {
+	case streamErr != nil:
+		return streamErr
+	case copyErr != nil:
+		return errors.Wrapf(copyErr, "writing to file %v", path)
+	}
+	return errors.Wrapf(f.Close(), "closing file %v", path)
+}
+
+// verifyFile checks that the size and SHA256 of the file at path match info.
+func verifyFile(path string, info pluginaggregation.TarInfo) error {
+	size, err := fileSize(path)
+	if err != nil {
+		return err
+	}
+	if size != info.Size {
+		return &VerificationError{Filename: path, Field: "size", Expected: strconv.FormatInt(info.Size, 10), Actual: strconv.FormatInt(size, 10)}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "opening file %v", path)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return errors.Wrapf(err, "computing checksum of %v", path)
+	}
+	if sum := fmt.Sprintf("%x", h.Sum(nil)); sum != info.SHA256 {
+		return &VerificationError{Filename: path, Field: "sha256", Expected: info.SHA256, Actual: sum}
+	}
+	return nil
+}
+
+// fileSize returns the size of the file at path or 0 if it does not exist.
+func fileSize(path string) (int64, error) {
+	fi, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return 0, nil
+	case err != nil:
+		return 0, errors.Wrapf(err, "checking file %v", path)
+	}
+	return fi.Size(), nil
+}
+
+// fileCmd is the command to run on the aggregator to get the raw bytes of the named
+// results tarball, starting at the given offset.
+func fileCmd(path, name string, offset int64) []string {
+	return append(tarCmd(path), "--file", name, "--offset", strconv.FormatInt(offset, 10))
+}
@@ -0,0 +1,186 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	pluginaggregation "github.com/vmware-tanzu/sonobuoy/pkg/plugin/aggregation"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// fakeFetcher serves data from the requested offset but only up to chunk bytes per call,
+// reporting an error as if the stream had been dropped.
+type fakeFetcher struct {
+	data    []byte
+	chunk   int
+	offsets []int64
+}
+
+func (f *fakeFetcher) fetch(offset int64) (io.Reader, <-chan error, error) {
+	f.offsets = append(f.offsets, offset)
+	ec := make(chan error, 1)
+	end := int(offset) + f.chunk
+	if end >= len(f.data) {
+		end = len(f.data)
+	} else {
+		ec <- errors.New("stream dropped")
+	}
+	close(ec)
+	return bytes.NewReader(f.data[offset:end]), ec, nil
+}
+
+func tarInfoFor(name string, data []byte) pluginaggregation.TarInfo {
+	return pluginaggregation.TarInfo{
+		Name:   name,
+		Size:   int64(len(data)),
+		SHA256: fmt.Sprintf("%x", sha256.Sum256(data)),
+	}
+}
+
+func TestDownloadWithResume(t *testing.T) {
+	data := []byte(strings.Repeat("sonobuoy", 10))
+	testBackoff := wait.Backoff{Duration: time.Millisecond, Factor: 1}
+
+	testCases := []struct {
+		desc          string
+		chunk         int
+		steps         int
+		existing      []byte
+		info          pluginaggregation.TarInfo
+		expectOffsets []int64
+		expectErr     string
+	}{
+		{
+			desc:          "Single attempt",
+			chunk:         len(data),
+			info:          tarInfoFor("a.tar.gz", data),
+			expectOffsets: []int64{0},
+		}, {
+			desc:          "Resumes after dropped streams",
+			chunk:         30,
+			steps:         5,
+			info:          tarInfoFor("a.tar.gz", data),
+			expectOffsets: []int64{0, 30, 60},
+		}, {
+			desc:          "Resumes from existing partial file",
+			chunk:         len(data),
+			existing:      data[:50],
+			info:          tarInfoFor("a.tar.gz", data),
+			expectOffsets: []int64{50},
+		}, {
+			desc:          "Restarts if partial file too large",
+			chunk:         len(data),
+			existing:      append(append([]byte{}, data...), 'x'),
+			info:          tarInfoFor("a.tar.gz", data),
+			expectOffsets: []int64{0},
+		}, {
+			desc:          "Gives up after retries",
+			chunk:         10,
+			steps:         2,
+			info:          tarInfoFor("a.tar.gz", data),
+			expectOffsets: []int64{0, 10, 20},
+			expectErr:     "failed to download results after 3 attempts: stream dropped",
+		}, {
+			desc:          "Checksum mismatch",
+			chunk:         len(data),
+			info:          pluginaggregation.TarInfo{Name: "a.tar.gz", Size: int64(len(data)), SHA256: "abc"},
+			expectOffsets: []int64{0},
+			expectErr:     "expected sha256 abc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			target := filepath.Join(t.TempDir(), "out", "a.tar.gz")
+			if tc.existing != nil {
+				if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(target+partialDownloadSuffix, tc.existing, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			f := &fakeFetcher{data: data, chunk: tc.chunk}
+			b := testBackoff
+			b.Steps = tc.steps
+			err := downloadWithResume(f.fetch, target, tc.info, b)
+
+			if !reflect.DeepEqual(f.offsets, tc.expectOffsets) {
+				t.Errorf("Expected fetches at offsets %v but got %v", tc.expectOffsets, f.offsets)
+			}
+
+			if len(tc.expectErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+					t.Fatalf("Expected error containing %q but got %v", tc.expectErr, err)
+				}
+				if _, statErr := os.Stat(target); !os.IsNotExist(statErr) {
+					t.Errorf("Expected target file not to exist after failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			got, err := os.ReadFile(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("Expected downloaded data %q but got %q", data, got)
+			}
+			if _, err := os.Stat(target + partialDownloadSuffix); !os.IsNotExist(err) {
+				t.Errorf("Expected partial file to be removed")
+			}
+		})
+	}
+}
+
+func TestVerificationErrorType(t *testing.T) {
+	data := []byte("foo")
+	target := filepath.Join(t.TempDir(), "a.tar.gz")
+	f := &fakeFetcher{data: data, chunk: len(data)}
+	info := pluginaggregation.TarInfo{Name: "a.tar.gz", Size: 3, SHA256: "abc"}
+
+	err := downloadWithResume(f.fetch, target, info, wait.Backoff{})
+	v, ok := err.(*VerificationError)
+	if !ok {
+		t.Fatalf("Expected *VerificationError but got %T: %v", err, err)
+	}
+	if v.Filename != target || v.Field != "sha256" {
+		t.Errorf("Unexpected verification error details: %+v", v)
+	}
+}
+
+func TestFileCmd(t *testing.T) {
+	expected := []string{"/sonobuoy", "splat", "/tmp/sonobuoy", "--file", "a.tar.gz", "--offset", "42"}
+	if cmd := fileCmd("/tmp/sonobuoy", "a.tar.gz", 42); !reflect.DeepEqual(cmd, expected) {
+		t.Errorf("Expected %v but got %v", expected, cmd)
+	}
+}
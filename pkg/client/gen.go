@@ -31,11 +31,13 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
 	"github.com/vmware-tanzu/sonobuoy/pkg/config"
 	"github.com/vmware-tanzu/sonobuoy/pkg/discovery"
 	"github.com/vmware-tanzu/sonobuoy/pkg/errlog"
 	kutil "github.com/vmware-tanzu/sonobuoy/pkg/k8s"
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/query"
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
 	manifesthelper "github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest/helper"
 	corev1 "k8s.io/api/core/v1"
@@ -200,12 +202,24 @@ func (*SonobuoyClient) GenerateManifestAndPlugins(cfg *GenConfig) ([]byte, []*ma
 		return nil, nil, errors.Wrap(err, "plugin YAML generation")
 	}
 
+	if cfg.Config.NamespaceScoped {
+		if err := checkNamespaceScopedPlugins(plugins); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	cfg.PluginEnvOverrides, plugins = applyAutoEnvVars(cfg.KubeVersion, cfg.Config.ResultsDir, cfg.Config.ProgressUpdatesPort, cfg.PluginEnvOverrides, plugins)
 	discovery.AutoAttachResultsDir(plugins, cfg.Config.ResultsDir)
 	if err := applyEnvOverrides(cfg.PluginEnvOverrides, plugins); err != nil {
 		return nil, nil, err
 	}
 
+	if cfg.DigestResolver != nil {
+		if err := pinImageDigests(cfg, plugins); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to pin image digests")
+		}
+	}
+
 	var buf bytes.Buffer
 	if err := generateYAMLComponents(&buf, cfg, plugins, configs); err != nil {
 		return nil, nil, errors.Wrap(err, "failed to generate YAML from configuration")
@@ -262,6 +276,10 @@ func generateAdditionalConfigmaps(w io.Writer, cfg *GenConfig, configs map[strin
 		cm.SetGroupVersionKind(schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"})
 		cm.Name = fmt.Sprintf("plugin-%v-cm", pluginName)
 		cm.Namespace = cfg.Config.Namespace
+		if cfg.Config.NamespaceScoped {
+			// Without a namespace of our own, delete relies on labels to find what to clean up.
+			cm.Labels = map[string]string{"component": "sonobuoy"}
+		}
 
 		filenames := []string{}
 		for filename := range configs[pluginName] {
@@ -679,7 +697,8 @@ func generateServiceAcct(w io.Writer, cfg *GenConfig) error {
 }
 
 func generateNS(w io.Writer, cfg GenConfig) error {
-	if cfg.Config.AggregatorPermissions != config.AggregatorPermissionsClusterAdmin {
+	// Namespace-scoped runs target an existing namespace.
+	if cfg.Config.NamespaceScoped || cfg.Config.AggregatorPermissions != config.AggregatorPermissionsClusterAdmin {
 		return nil
 	}
 
@@ -773,6 +792,17 @@ func checkPluginNames(plugins []*manifest.Manifest) error {
 	return nil
 }
 
+// checkNamespaceScopedPlugins ensures that none of the plugins need to be scheduled on every node
+// since that requires listing the nodes of the cluster.
+func checkNamespaceScopedPlugins(plugins []*manifest.Manifest) error {
+	for _, p := range plugins {
+		if strings.EqualFold(p.SonobuoyConfig.Driver, "daemonset") {
+			return fmt.Errorf("plugin %v uses the DaemonSet driver which is not supported in namespace-scoped runs", p.SonobuoyConfig.PluginName)
+		}
+	}
+	return nil
+}
+
 func SystemdLogsManifest(cfg *GenConfig) *manifest.Manifest {
 	trueVal := true
 	m := &manifest.Manifest{
@@ -821,6 +851,21 @@ func SystemdLogsManifest(cfg *GenConfig) *manifest.Manifest {
 	return m
 }
 
+// QueryManifest returns the definition of a plugin which queries the cluster using the filters in
+// the Sonobuoy config. It can be renamed, ordered and given its own filters to snapshot the cluster
+// at different points of the run.
+func QueryManifest() *manifest.Manifest {
+	return &manifest.Manifest{
+		SonobuoyConfig: manifest.SonobuoyConfig{
+			PluginName:   "query",
+			Driver:       query.DriverName,
+			ResultFormat: results.ResultFormatManual,
+			ResultFiles:  []string{query.ResultsFile},
+		},
+		Query: &manifest.QuerySpec{},
+	}
+}
+
 func E2EManifest(cfg *GenConfig) *manifest.Manifest {
 	if cfg.Config == nil {
 		cfg.Config = config.New()
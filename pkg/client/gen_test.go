@@ -474,6 +474,46 @@ func TestGenerateManifestGolden(t *testing.T) {
 				KubeVersion: "v99+static.testing",
 			},
 			goldenFile: filepath.Join("testdata", "aggregatorpermissions-noncluster-admin.golden"),
+		}, {
+			name: "Namespace-scoped emits no cluster-scoped objects",
+			inputcm: &client.GenConfig{
+				Config: fromConfig(func(c *config.Config) *config.Config {
+					c.NamespaceScoped = true
+					c.AggregatorPermissions = config.AggregatorPermissionsNamespaceAdmin
+					return c
+				}),
+				EnableRBAC:  true,
+				KubeVersion: "v99+static.testing",
+				StaticPlugins: []*manifest.Manifest{
+					{
+						SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "myplugin", Driver: "Job"},
+						ConfigMap:      map[string]string{"config.yaml": "foo: bar"},
+					},
+				},
+			},
+			goldenFile: filepath.Join("testdata", "namespace-scoped.golden"),
+		}, {
+			name: "Namespace-scoped rejects daemonset plugins",
+			inputcm: &client.GenConfig{
+				Config: fromConfig(func(c *config.Config) *config.Config {
+					c.NamespaceScoped = true
+					c.AggregatorPermissions = config.AggregatorPermissionsNamespaceAdmin
+					return c
+				}),
+				KubeVersion: "v99+static.testing",
+			},
+			expectErr: "plugin systemd-logs uses the DaemonSet driver which is not supported in namespace-scoped runs",
+		}, {
+			name: "Namespace-scoped requires namespaceAdmin permissions",
+			inputcm: &client.GenConfig{
+				Config: fromConfig(func(c *config.Config) *config.Config {
+					c.NamespaceScoped = true
+					return c
+				}),
+				KubeVersion:    "v99+static.testing",
+				DynamicPlugins: []string{"e2e"},
+			},
+			expectErr: "config validation failed: namespace-scoped runs require namespaceAdmin aggregator permissions, got clusterAdmin",
 		},
 	}
 
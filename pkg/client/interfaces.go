@@ -50,14 +50,54 @@ type LogConfig struct {
 	Plugin string
 	// Out is the writer to write to.
 	Out io.Writer
+
+	// Format is the output format of the logs, either LogFormatText (the default) or LogFormatJSON.
+	Format string
+	// Since limits the logs to those newer than the given duration. All logs are shown if 0.
+	Since time.Duration
+	// TailLines limits the logs to the given number of lines from the end of each container's logs.
+	// All lines are shown if nil.
+	TailLines *int64
+	// Container limits the logs to the containers with the given name.
+	Container string
+	// Node limits the logs to the pods running on the given node. Useful for daemonset plugins.
+	Node string
+	// Previous shows the logs of the previous instance of each container, if it was restarted.
+	Previous bool
 }
 
+const (
+	// LogFormatText writes raw logs with a header whenever the source container changes.
+	LogFormatText = "text"
+
+	// LogFormatJSON writes each log line as a JSON object including its source.
+	LogFormatJSON = "json"
+)
+
 // Validate checks the config to determine if it is valid.
 func (lc *LogConfig) Validate() error {
 	if lc.Namespace == "" {
 		return errors.New("namespace cannot be empty")
 	}
 
+	switch lc.Format {
+	case "", LogFormatText, LogFormatJSON:
+	default:
+		return fmt.Errorf("unknown log format %q, must be one of [%v, %v]", lc.Format, LogFormatText, LogFormatJSON)
+	}
+
+	if lc.Since < 0 {
+		return errors.New("since cannot be negative")
+	}
+
+	if lc.TailLines != nil && *lc.TailLines < 0 {
+		return errors.New("tail lines cannot be negative")
+	}
+
+	if lc.Previous && lc.Follow {
+		return errors.New("logs of previous containers cannot be followed")
+	}
+
 	return nil
 }
 
@@ -99,6 +139,25 @@ type GenConfig struct {
 	// The version of Kubernetes to assume. Used to surface for plugin images
 	// and env vars.
 	KubeVersion string
+
+	// DigestResolver, if set, is used to pin the images of the aggregator, workers
+	// and plugins to their current digests. The mapping is recorded in the
+	// ImageDigests field of the config.
+	DigestResolver DigestResolver
+}
+
+// DigestResolver resolves images to the digests they currently refer to.
+type DigestResolver interface {
+	// ResolveDigest returns the digest (e.g. sha256:...) of the image.
+	ResolveDigest(image string) (string, error)
+
+	TestImageLister
+}
+
+// TestImageLister lists the images the e2e tests will pull, which aren't part of the manifest.
+type TestImageLister interface {
+	// TestImages returns the images pulled by the tests in the given conformance image.
+	TestImages(conformanceImage string, env map[string]string) ([]string, error)
 }
 
 // Validate checks the config to determine if it is valid.
@@ -118,6 +177,10 @@ func (gc *GenConfig) Validate() error {
 			}
 		}
 	}
+
+	if gc.Config != nil && gc.Config.NamespaceScoped && gc.Config.AggregatorPermissions != config.AggregatorPermissionsNamespaceAdmin {
+		return fmt.Errorf("namespace-scoped runs require %v aggregator permissions, got %v", config.AggregatorPermissionsNamespaceAdmin, gc.Config.AggregatorPermissions)
+	}
 	return nil
 }
 
@@ -185,6 +248,10 @@ type DeleteConfig struct {
 	Wait       time.Duration
 	WaitOutput string
 	DryRun     bool
+
+	// NamespaceScoped deletes only the Sonobuoy resources within the namespace, leaving
+	// the namespace itself and all cluster-scoped resources alone.
+	NamespaceScoped bool
 }
 
 // Validate checks the config to determine if it is valid.
@@ -193,6 +260,10 @@ func (dc *DeleteConfig) Validate() error {
 		return errors.New("namespace cannot be empty")
 	}
 
+	if dc.NamespaceScoped && dc.DeleteAll {
+		return errors.New("e2e namespaces can not be deleted when namespace-scoped")
+	}
+
 	return nil
 }
 
@@ -203,6 +274,12 @@ type RetrieveConfig struct {
 	// Path is the location that the aggregator stores results in. Should
 	// usually be the same value but can help with debugging some issues.
 	Path string
+	// Partial will retrieve the results of plugins which have already completed
+	// while the run is still in progress rather than the final results tarball.
+	Partial bool
+	// Plugins limits a partial retrieval to the given plugins. If empty, all
+	// completed plugins are retrieved.
+	Plugins []string
 }
 
 // Validate checks the config to determine if it is valid.
@@ -211,6 +288,38 @@ func (rc *RetrieveConfig) Validate() error {
 		return errors.New("namespace cannot be empty")
 	}
 
+	if len(rc.Plugins) > 0 && !rc.Partial {
+		return errors.New("plugins can only be specified for partial retrieval")
+	}
+
+	return nil
+}
+
+// DownloadConfig are the input options for downloading the results tarball of a completed run.
+type DownloadConfig struct {
+	RetrieveConfig
+	// OutputDir is the local directory the tarball will be written to.
+	OutputDir string
+	// Filename, if set, overrides the name of the tarball from the aggregator.
+	Filename string
+	// Retries is the number of times an interrupted download will be resumed before giving up.
+	Retries int
+}
+
+// Validate checks the config to determine if it is valid.
+func (dc *DownloadConfig) Validate() error {
+	if err := dc.RetrieveConfig.Validate(); err != nil {
+		return err
+	}
+
+	if dc.Partial {
+		return errors.New("partial results cannot be downloaded as a verified tarball")
+	}
+
+	if dc.Retries < 0 {
+		return errors.New("retries cannot be negative")
+	}
+
 	return nil
 }
 
@@ -235,6 +344,22 @@ type PreflightConfig struct {
 	DNSNamespace        string
 	DNSPodLabels        []string
 	PreflightChecksSkip []string
+
+	// NamespaceScoped changes the checks to reflect that the run uses an existing namespace
+	// and has no cluster-scoped permissions.
+	NamespaceScoped bool
+
+	// Manifest is the output of `sonobuoy gen` which will be run. Checks which inspect
+	// the objects or plugins to be created are skipped if it is empty.
+	Manifest []byte
+
+	// ImagePullCheck enables the check that the nodes can pull the images of the run. It
+	// creates a temporary namespace and pods so it only runs when requested.
+	ImagePullCheck bool
+
+	// TestImageLister, if set, is used to include the images pulled by the e2e tests
+	// when checking that the nodes can pull the images of the run.
+	TestImageLister TestImageLister
 }
 
 // Validate checks the config to determine if it is valid.
@@ -297,6 +422,9 @@ type Interface interface {
 	GenerateManifest(cfg *GenConfig) ([]byte, error)
 	// RetrieveResults copies results from a sonobuoy run into a Reader in tar format.
 	RetrieveResults(cfg *RetrieveConfig) (io.Reader, <-chan error, error)
+	// DownloadResults downloads the results tarball of a completed run, resuming interrupted
+	// transfers and verifying the result. Returns the name of the file written.
+	DownloadResults(cfg *DownloadConfig) (string, error)
 	// GetStatus determines the status of the sonobuoy run in order to assist the user.
 	GetStatus(cfg *StatusConfig) (*aggregation.Status, error)
 	// LogReader returns a reader that contains a merged stream of sonobuoy logs.
@@ -18,6 +18,7 @@ package client
 
 import (
 	"testing"
+	"time"
 
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
 )
@@ -151,6 +152,29 @@ func TestConfigValidation(t *testing.T) {
 			config: &LogConfig{Namespace: "valid-namespace"},
 			valid:  true,
 		},
+		{
+			desc:   "log config with json format and filters is valid",
+			config: &LogConfig{Namespace: "valid-namespace", Format: LogFormatJSON, Since: time.Minute, Container: "plugin", Node: "node1", Previous: true},
+			valid:  true,
+		},
+		{
+			desc:          "log config with unknown format is not valid",
+			config:        &LogConfig{Namespace: "valid-namespace", Format: "yaml"},
+			valid:         false,
+			expectedError: `unknown log format "yaml", must be one of [text, json]`,
+		},
+		{
+			desc:          "log config with negative tail is not valid",
+			config:        &LogConfig{Namespace: "valid-namespace", TailLines: new(int64(-1))},
+			valid:         false,
+			expectedError: "tail lines cannot be negative",
+		},
+		{
+			desc:          "log config following previous containers is not valid",
+			config:        &LogConfig{Namespace: "valid-namespace", Follow: true, Previous: true},
+			valid:         false,
+			expectedError: "logs of previous containers cannot be followed",
+		},
 		{
 			desc:          "delete config with no namespace is not valid",
 			config:        &DeleteConfig{},
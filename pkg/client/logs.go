@@ -17,15 +17,19 @@ limitations under the License.
 package client
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
@@ -158,6 +162,9 @@ func getPodsToStreamLogs(client kubernetes.Interface, cfg *LogConfig, podCh chan
 		selector := metav1.AddLabelToSelector(&metav1.LabelSelector{}, "sonobuoy-plugin", cfg.Plugin)
 		listOptions = metav1.ListOptions{LabelSelector: metav1.FormatLabelSelector(selector)}
 	}
+	if cfg.Node != "" {
+		listOptions.FieldSelector = nodeFieldSelector(cfg.Node)
+	}
 
 	podList, err := client.CoreV1().Pods(cfg.Namespace).List(context.TODO(), listOptions)
 	if err != nil {
@@ -189,6 +196,9 @@ func watchPodsToStreamLogs(client kubernetes.Interface, cfg *LogConfig, podCh ch
 			LabelSelector: metav1.FormatLabelSelector(selector),
 		}
 	}
+	if cfg.Node != "" {
+		listOptions.FieldSelector = nodeFieldSelector(cfg.Node)
+	}
 
 	lw := &cache.ListWatch{
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
@@ -221,6 +231,37 @@ func watchPodsToStreamLogs(client kubernetes.Interface, cfg *LogConfig, podCh ch
 	return nil
 }
 
+// nodeFieldSelector selects the pods scheduled on the given node.
+func nodeFieldSelector(node string) string {
+	return fields.OneTermEqualSelector("spec.nodeName", node).String()
+}
+
+// podLogOptions returns the options for getting the logs of the given container.
+func podLogOptions(cfg *LogConfig, container string) *v1.PodLogOptions {
+	opts := &v1.PodLogOptions{
+		Container: container,
+		Follow:    cfg.Follow,
+		Previous:  cfg.Previous,
+		TailLines: cfg.TailLines,
+	}
+	if cfg.Since > 0 {
+		seconds := int64(cfg.Since.Seconds())
+		if seconds == 0 {
+			seconds = 1
+		}
+		opts.SinceSeconds = &seconds
+	}
+	return opts
+}
+
+// pluginForPod returns the name of the plugin the pod belongs to or "sonobuoy" for the aggregator.
+func pluginForPod(pod *v1.Pod) string {
+	if pod.Labels["sonobuoy-component"] == "aggregator" {
+		return "sonobuoy"
+	}
+	return pod.Labels["sonobuoy-plugin"]
+}
+
 // LogReader configures a Reader that provides an io.Reader interface to a merged stream of logs from various containers.
 func (s *SonobuoyClient) LogReader(cfg *LogConfig) (*Reader, error) {
 	if cfg == nil {
@@ -256,19 +297,22 @@ func (s *SonobuoyClient) LogReader(cfg *LogConfig) (*Reader, error) {
 	drainPodChannelAndStartStreaming := func() {
 		for pod := range podCh {
 			for _, container := range pod.Spec.Containers {
+				if cfg.Container != "" && container.Name != cfg.Container {
+					continue
+				}
 				wg.Add(1)
 
 				ls := &logStreamer{
 					ns:        pod.Namespace,
 					pod:       pod.Name,
 					container: container.Name,
+					plugin:    pluginForPod(pod),
+					node:      pod.Spec.NodeName,
+					json:      cfg.Format == LogFormatJSON,
 					errc:      errc,
 					logc:      agg,
-					logOpts: &v1.PodLogOptions{
-						Container: container.Name,
-						Follow:    cfg.Follow,
-					},
-					client: client,
+					logOpts:   podLogOptions(cfg, container.Name),
+					client:    client,
 				}
 
 				go func(w *sync.WaitGroup, ls *logStreamer) {
@@ -320,10 +364,13 @@ func newMessage(preamble string, data []byte) *message {
 // logStreamer writes logs from a container to a fan-in channel.
 type logStreamer struct {
 	ns, pod, container string
-	errc               chan error
-	logc               chan *message
-	logOpts            *v1.PodLogOptions
-	client             kubernetes.Interface
+	plugin, node       string
+	// json writes each line as a logLine rather than raw chunks with a header.
+	json    bool
+	errc    chan error
+	logc    chan *message
+	logOpts *v1.PodLogOptions
+	client  kubernetes.Interface
 }
 
 func (l *logStreamer) podName() string {
@@ -378,6 +425,11 @@ func (l *logStreamer) stream() {
 	}
 	defer readCloser.Close()
 
+	if l.json {
+		l.streamLines(readCloser)
+		return
+	}
+
 	// newline because logs have new lines in them
 	preamble := fmt.Sprintf("namespace=%q pod=%q container=%q\n", l.ns, l.pod, l.container)
 
@@ -398,6 +450,47 @@ func (l *logStreamer) stream() {
 	}
 }
 
+// logLine is a single line of logs along with its source, as written in the JSON format.
+type logLine struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Plugin    string `json:"plugin,omitempty"`
+	Node      string `json:"node,omitempty"`
+	Message   string `json:"message"`
+}
+
+// streamLines pushes each line of the logs onto the fan-in channel as a JSON object. Messages have
+// no preamble so no headers get applied.
+func (l *logStreamer) streamLines(r io.Reader) {
+	br := bufio.NewReaderSize(r, bufSize)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			l.errc <- errors.Wrapf(err, "error reading logs from container [%v]", l.container)
+			return
+		}
+		if len(line) > 0 {
+			b, jsonErr := json.Marshal(logLine{
+				Namespace: l.ns,
+				Pod:       l.pod,
+				Container: l.container,
+				Plugin:    l.plugin,
+				Node:      l.node,
+				Message:   strings.TrimSuffix(line, "\n"),
+			})
+			if jsonErr != nil {
+				l.errc <- errors.Wrapf(jsonErr, "error encoding logs from container [%v]", l.container)
+				return
+			}
+			l.logc <- newMessage("", append(b, '\n'))
+		}
+		if err == io.EOF {
+			return
+		}
+	}
+}
+
 // applyHeaders takes a channel of messages and transforms it into a channel of bytes.
 // applyHeaders will write headers to the byte stream as appropriate.
 func applyHeaders(mesc chan *message) chan []byte {
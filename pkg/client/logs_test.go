@@ -17,8 +17,10 @@ package client
 
 import (
 	"io"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
@@ -399,3 +401,97 @@ func TestIsContainerRunning(t *testing.T) {
 		})
 	}
 }
+
+func TestPodsForLogsNodeFilter(t *testing.T) {
+	fclient := fake.NewSimpleClientset()
+	fclient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		fieldSelector := action.(k8stesting.ListAction).GetListRestrictions().Fields.String()
+		if fieldSelector != "spec.nodeName=node1" {
+			t.Errorf("expected field selector to be %q, got %q", "spec.nodeName=node1", fieldSelector)
+		}
+		return true, &v1.PodList{}, nil
+	})
+
+	podCh := make(chan *v1.Pod)
+	if err := getPodsToStreamLogs(fclient, &LogConfig{Plugin: "systemd-logs", Node: "node1"}, podCh); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for range podCh {
+	}
+}
+
+func TestPodLogOptions(t *testing.T) {
+	tail := int64(10)
+	testCases := []struct {
+		desc   string
+		cfg    *LogConfig
+		expect *v1.PodLogOptions
+	}{
+		{
+			desc:   "Defaults",
+			cfg:    &LogConfig{},
+			expect: &v1.PodLogOptions{Container: "c"},
+		}, {
+			desc:   "All options",
+			cfg:    &LogConfig{Follow: true, Since: 90 * time.Second, TailLines: &tail},
+			expect: &v1.PodLogOptions{Container: "c", Follow: true, SinceSeconds: new(int64(90)), TailLines: &tail},
+		}, {
+			desc:   "Sub-second since rounds up",
+			cfg:    &LogConfig{Since: time.Millisecond, Previous: true},
+			expect: &v1.PodLogOptions{Container: "c", Previous: true, SinceSeconds: new(int64(1))},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := podLogOptions(tc.cfg, "c")
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("Expected %+v but got %+v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestStreamLines(t *testing.T) {
+	logc := make(chan *message)
+	errc := make(chan error, 1)
+	ls := &logStreamer{
+		ns: "sonobuoy", pod: "sonobuoy-systemd-logs-abc", container: "plugin",
+		plugin: "systemd-logs", node: "node1", json: true,
+		logc: logc, errc: errc,
+	}
+	go func() {
+		ls.streamLines(strings.NewReader("first line\nsecond \"quoted\" line\nno newline"))
+		close(logc)
+	}()
+
+	got := []string{}
+	for m := range logc {
+		if m.preamble != "" {
+			t.Errorf("Expected no preamble but got %q", m.preamble)
+		}
+		got = append(got, string(m.buffer))
+	}
+
+	expect := []string{
+		`{"namespace":"sonobuoy","pod":"sonobuoy-systemd-logs-abc","container":"plugin","plugin":"systemd-logs","node":"node1","message":"first line"}` + "\n",
+		`{"namespace":"sonobuoy","pod":"sonobuoy-systemd-logs-abc","container":"plugin","plugin":"systemd-logs","node":"node1","message":"second \"quoted\" line"}` + "\n",
+		`{"namespace":"sonobuoy","pod":"sonobuoy-systemd-logs-abc","container":"plugin","plugin":"systemd-logs","node":"node1","message":"no newline"}` + "\n",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected lines\n%v\nbut got\n%v", expect, got)
+	}
+}
+
+func TestPluginForPod(t *testing.T) {
+	aggregator := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"sonobuoy-component": "aggregator"}}}
+	plugin := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"sonobuoy-component": "plugin", "sonobuoy-plugin": "e2e"}}}
+	if got := pluginForPod(aggregator); got != "sonobuoy" {
+		t.Errorf("Expected sonobuoy but got %q", got)
+	}
+	if got := pluginForPod(plugin); got != "e2e" {
+		t.Errorf("Expected e2e but got %q", got)
+	}
+	if got := pluginForPod(&v1.Pod{}); got != "" {
+		t.Errorf("Expected no plugin but got %q", got)
+	}
+}
@@ -19,35 +19,93 @@ package client
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/vmware-tanzu/sonobuoy/pkg/buildinfo"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/daemonset"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/loader"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
 
 	version "github.com/hashicorp/go-version"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	apicorev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
 )
 
+// PreflightSeverity determines whether a failed preflight check should stop the run or not.
+type PreflightSeverity string
+
+const (
+	// PreflightSeverityError indicates the run would fail or hang and should not be started.
+	PreflightSeverityError PreflightSeverity = "error"
+
+	// PreflightSeverityWarn indicates the run may be impacted but can still be started.
+	PreflightSeverityWarn PreflightSeverity = "warn"
+)
+
+// PreflightError is returned for each preflight check which did not pass.
+type PreflightError struct {
+	Check    string
+	Severity PreflightSeverity
+	Err      error
+}
+
+func (e *PreflightError) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the underlying error of the check.
+func (e *PreflightError) Cause() error {
+	return e.Err
+}
+
+// IsPreflightWarning returns true if the error is from a preflight check which only warns.
+func IsPreflightWarning(err error) bool {
+	pe, ok := err.(*PreflightError)
+	return ok && pe.Severity == PreflightSeverityWarn
+}
+
+// preflightCheck is a single named check. Checks may return a *PreflightError to override
+// their default severity for a particular result. The parsed manifest is nil if there isn't one.
+type preflightCheck struct {
+	severity PreflightSeverity
+	run      func(kubernetes.Interface, *PreflightConfig, *preflightManifest) error
+}
+
 var (
 	minimumKubeVersion = version.Must(version.NewVersion(buildinfo.MinimumKubeVersion))
 	maximumKubeVersion = version.Must(version.NewVersion(buildinfo.MaximumKubeVersion))
 
-	validPreflightChecks = map[string]func(kubernetes.Interface, *PreflightConfig) error{
-		"dnscheck":          preflightDNSCheck,
-		"versioncheck":      preflightVersionCheck,
-		"existingnamespace": preflightExistingNamespace,
+	validPreflightChecks = map[string]preflightCheck{
+		"dnscheck":          {PreflightSeverityError, preflightDNSCheck},
+		"versioncheck":      {PreflightSeverityError, preflightVersionCheck},
+		"existingnamespace": {PreflightSeverityError, preflightExistingNamespace},
+		"rbaccheck":         {PreflightSeverityError, preflightRBACCheck},
+		"psacheck":          {PreflightSeverityError, preflightPSACheck},
+		"quotacheck":        {PreflightSeverityWarn, preflightQuotaCheck},
+		"nodecheck":         {PreflightSeverityError, preflightNodeCheck},
+		"imagepullcheck":    {PreflightSeverityError, preflightImagePullCheck},
 	}
 )
 
 type listFunc func(context.Context, metav1.ListOptions) (*apicorev1.PodList, error)
 type nsGetFunc func(context.Context, string, metav1.GetOptions) (*apicorev1.Namespace, error)
 
-// PreflightChecks runs all preflight checks in order, returning the first error encountered.
+// PreflightChecks runs all preflight checks in order, returning the errors encountered. Each
+// failed check is returned as a *PreflightError; see IsPreflightWarning to determine which
+// only warrant a warning.
 func (c *SonobuoyClient) PreflightChecks(cfg *PreflightConfig) []error {
 	if cfg == nil {
 		return []error{errors.New("nil PreflightConfig provided")}
@@ -62,7 +120,11 @@ func (c *SonobuoyClient) PreflightChecks(cfg *PreflightConfig) []error {
 		return []error{err}
 	}
 
-	preflightChecks := make(map[string]func(kubernetes.Interface, *PreflightConfig) error)
+	return runPreflightChecks(client, cfg)
+}
+
+func runPreflightChecks(client kubernetes.Interface, cfg *PreflightConfig) []error {
+	preflightChecks := make(map[string]preflightCheck)
 	for key, value := range validPreflightChecks {
 		preflightChecks[key] = value
 	}
@@ -85,17 +147,47 @@ func (c *SonobuoyClient) PreflightChecks(cfg *PreflightConfig) []error {
 		}
 	}
 
+	// Run in a predictable order so the output is consistent.
+	names := []string{}
+	for name := range preflightChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	errors := []error{}
-	for _, check := range preflightChecks {
-		if err := check(client, cfg); err != nil {
-			errors = append(errors, err)
+
+	// The manifest is only parsed once for all of the checks which use it. If it can't be parsed,
+	// those checks are skipped.
+	var pm *preflightManifest
+	if len(cfg.Manifest) > 0 {
+		var err error
+		if pm, err = parsePreflightManifest(cfg); err != nil {
+			errors = append(errors, &PreflightError{Severity: PreflightSeverityError, Err: err})
+		}
+	}
+
+	for _, name := range names {
+		check := preflightChecks[name]
+		err := check.run(client, cfg, pm)
+		if err == nil {
+			continue
 		}
+		if pe, ok := err.(*PreflightError); ok {
+			pe.Check = name
+			errors = append(errors, pe)
+			continue
+		}
+		errors = append(errors, &PreflightError{Check: name, Severity: check.severity, Err: err})
 	}
 
 	return errors
 }
 
-func preflightDNSCheck(client kubernetes.Interface, cfg *PreflightConfig) error {
+func preflightDNSCheck(client kubernetes.Interface, cfg *PreflightConfig, _ *preflightManifest) error {
+	if cfg.NamespaceScoped && cfg.DNSNamespace != cfg.Namespace {
+		logrus.Debugf("Skipping DNS check; unable to inspect namespace %v when namespace-scoped", cfg.DNSNamespace)
+		return nil
+	}
 	return dnsCheck(
 		client.CoreV1().Pods(cfg.DNSNamespace).List,
 		cfg.DNSNamespace,
@@ -129,7 +221,7 @@ func dnsCheck(listPods listFunc, dnsNamespace string, dnsLabels ...string) error
 	return nil
 }
 
-func preflightVersionCheck(client kubernetes.Interface, cfg *PreflightConfig) error {
+func preflightVersionCheck(client kubernetes.Interface, cfg *PreflightConfig, _ *preflightManifest) error {
 	return versionCheck(
 		client.Discovery(),
 		minimumKubeVersion,
@@ -158,13 +250,32 @@ func versionCheck(versionClient discovery.ServerVersionInterface, min, max *vers
 	return nil
 }
 
-func preflightExistingNamespace(client kubernetes.Interface, cfg *PreflightConfig) error {
+func preflightExistingNamespace(client kubernetes.Interface, cfg *PreflightConfig, _ *preflightManifest) error {
+	if cfg.NamespaceScoped {
+		return nsExistsCheck(
+			client.CoreV1().Namespaces().Get,
+			cfg.Namespace,
+		)
+	}
 	return nsCheck(
 		client.CoreV1().Namespaces().Get,
 		cfg.Namespace,
 	)
 }
 
+// nsExistsCheck is the inverse of nsCheck; namespace-scoped runs require the namespace to exist
+// since they can not create it.
+func nsExistsCheck(getter nsGetFunc, ns string) error {
+	_, err := getter(context.TODO(), ns, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return fmt.Errorf("namespace %v does not exist; it must be created before a namespace-scoped run", ns)
+	case err != nil:
+		return errors.Wrap(err, "error checking for namespace")
+	}
+	return nil
+}
+
 func nsCheck(getter nsGetFunc, ns string) error {
 	_, err := getter(context.TODO(), ns, metav1.GetOptions{})
 	switch {
@@ -177,3 +288,743 @@ func nsCheck(getter nsGetFunc, ns string) error {
 	}
 	return nil
 }
+
+const (
+	// pluginsConfigMapName is the name of the ConfigMap holding the plugin definitions in the manifest.
+	pluginsConfigMapName = "sonobuoy-plugins-cm"
+
+	// psaEnforceLabel is the namespace label determining the enforced pod security level.
+	psaEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+	// psaPrivileged is the only pod security level which allows the daemonset plugins to run.
+	psaPrivileged = "privileged"
+
+	// workerContainerName is the sidecar added to every plugin pod at runtime.
+	workerContainerName = "sonobuoy-worker"
+)
+
+// preflightManifest is the parsed content of PreflightConfig.Manifest.
+type preflightManifest struct {
+	objects    []*unstructured.Unstructured
+	plugins    []manifest.Manifest
+	aggregator *apicorev1.Pod
+	namespace  *apicorev1.Namespace
+}
+
+// parsePreflightManifest decodes the objects in the manifest along with the plugins and the aggregator.
+func parsePreflightManifest(cfg *PreflightConfig) (*preflightManifest, error) {
+	objs, err := decodeManifest(cfg.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	pm := &preflightManifest{objects: objs}
+	for _, obj := range objs {
+		switch {
+		case obj.GetKind() == "ConfigMap" && obj.GetName() == pluginsConfigMapName:
+			data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+			if err != nil {
+				return nil, errors.Wrap(err, "reading plugins from manifest")
+			}
+			keys := []string{}
+			for k := range data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				def, err := loader.LoadDefinition([]byte(data[k]))
+				if err != nil {
+					return nil, err
+				}
+				pm.plugins = append(pm.plugins, def)
+			}
+		case obj.GetKind() == "Pod" && obj.GetName() == "sonobuoy":
+			pm.aggregator = &apicorev1.Pod{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, pm.aggregator); err != nil {
+				return nil, errors.Wrap(err, "reading aggregator pod from manifest")
+			}
+		case obj.GetKind() == "Namespace" && obj.GetName() == cfg.Namespace:
+			pm.namespace = &apicorev1.Namespace{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, pm.namespace); err != nil {
+				return nil, errors.Wrap(err, "reading namespace from manifest")
+			}
+		}
+	}
+	return pm, nil
+}
+
+// daemonSetPlugins returns the plugins which run on every node.
+func (pm *preflightManifest) daemonSetPlugins() []manifest.Manifest {
+	var out []manifest.Manifest
+	for _, p := range pm.plugins {
+		if strings.EqualFold(p.SonobuoyConfig.Driver, "daemonset") {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// preflightRBACCheck ensures the caller can create every object in the manifest.
+func preflightRBACCheck(client kubernetes.Interface, cfg *PreflightConfig, pm *preflightManifest) error {
+	if pm == nil {
+		return nil
+	}
+
+	groupResources, err := restmapper.GetAPIGroupResources(client.Discovery())
+	if err != nil {
+		return errors.Wrap(err, "failed to discover API resources")
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+
+	return rbacCheck(mapper, client.AuthorizationV1().SelfSubjectAccessReviews().Create, pm.objects)
+}
+
+type ssarCreateFunc func(context.Context, *authorizationv1.SelfSubjectAccessReview, metav1.CreateOptions) (*authorizationv1.SelfSubjectAccessReview, error)
+
+func rbacCheck(mapper meta.RESTMapper, create ssarCreateFunc, objs []*unstructured.Unstructured) error {
+	checked := map[authorizationv1.ResourceAttributes]bool{}
+	denied := []string{}
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return errors.Wrapf(err, "unable to find the API resource for %v", gvk)
+		}
+
+		attrs := authorizationv1.ResourceAttributes{
+			Verb:     "create",
+			Group:    mapping.Resource.Group,
+			Version:  mapping.Resource.Version,
+			Resource: mapping.Resource.Resource,
+		}
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			attrs.Namespace = obj.GetNamespace()
+		}
+		if checked[attrs] {
+			continue
+		}
+		checked[attrs] = true
+
+		review, err := create(context.TODO(), &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to check permissions")
+		}
+		if !review.Status.Allowed {
+			desc := mapping.Resource.GroupResource().String()
+			if len(attrs.Namespace) > 0 {
+				desc = fmt.Sprintf("%v in namespace %v", desc, attrs.Namespace)
+			}
+			denied = append(denied, desc)
+		}
+	}
+
+	if len(denied) > 0 {
+		return fmt.Errorf("missing permissions to create %v", strings.Join(denied, ", "))
+	}
+	return nil
+}
+
+// preflightPSACheck ensures the pod security level of the namespace allows the privileged
+// pods the daemonset plugins require.
+func preflightPSACheck(client kubernetes.Interface, cfg *PreflightConfig, pm *preflightManifest) error {
+	if pm == nil {
+		return nil
+	}
+
+	ns := pm.namespace
+	if ns == nil {
+		// The namespace isn't created by the run so it must already exist.
+		var err error
+		ns, err = client.CoreV1().Namespaces().Get(context.TODO(), cfg.Namespace, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			return nil
+		case err != nil:
+			return &PreflightError{Severity: PreflightSeverityWarn, Err: errors.Wrap(err, "unable to check the pod security level of the namespace")}
+		}
+	}
+
+	return psaCheck(ns, pm.daemonSetPlugins())
+}
+
+func psaCheck(ns *apicorev1.Namespace, daemonsets []manifest.Manifest) error {
+	level := ns.Labels[psaEnforceLabel]
+	if len(daemonsets) == 0 || len(level) == 0 || level == psaPrivileged {
+		return nil
+	}
+
+	names := []string{}
+	for _, p := range daemonsets {
+		names = append(names, p.SonobuoyConfig.PluginName)
+	}
+	return fmt.Errorf("namespace %v enforces the %q pod security level but the daemonset plugins [%v] require %q", ns.Name, level, strings.Join(names, ", "), psaPrivileged)
+}
+
+// preflightQuotaCheck ensures the ResourceQuotas and LimitRanges in the namespace won't reject the plugin pods.
+func preflightQuotaCheck(client kubernetes.Interface, cfg *PreflightConfig, pm *preflightManifest) error {
+	if pm == nil {
+		return nil
+	}
+
+	quotas, err := client.CoreV1().ResourceQuotas(cfg.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "unable to list resource quotas")
+	}
+	limits, err := client.CoreV1().LimitRanges(cfg.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "unable to list limit ranges")
+	}
+
+	// Daemonsets create a pod per node so this is only the minimum.
+	pods := len(pm.plugins)
+	containers := []apicorev1.Container{}
+	if pm.aggregator != nil {
+		pods++
+		containers = append(containers, pm.aggregator.Spec.Containers...)
+	}
+	for _, p := range pm.plugins {
+		containers = append(containers, p.Spec.Container, apicorev1.Container{Name: workerContainerName})
+		if p.PodSpec != nil {
+			containers = append(containers, p.PodSpec.Containers...)
+		}
+	}
+
+	problems := quotaCheck(quotas.Items, limits.Items, containers, pods)
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// quotaCheck returns a description of each way the quotas or limit ranges may block the given containers.
+func quotaCheck(quotas []apicorev1.ResourceQuota, limitRanges []apicorev1.LimitRange, containers []apicorev1.Container, pods int) []string {
+	// Defaults applied by the LimitRanges to containers which don't specify their own values.
+	defaultRequests, defaultLimits := apicorev1.ResourceList{}, apicorev1.ResourceList{}
+	for _, lr := range limitRanges {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != apicorev1.LimitTypeContainer {
+				continue
+			}
+			for name, q := range item.DefaultRequest {
+				defaultRequests[name] = q
+			}
+			for name, q := range item.Default {
+				defaultLimits[name] = q
+				// Requests default to the limit if not set.
+				if _, ok := defaultRequests[name]; !ok {
+					defaultRequests[name] = q
+				}
+			}
+		}
+	}
+
+	problems := []string{}
+	for _, quota := range quotas {
+		if hard, ok := quota.Spec.Hard[apicorev1.ResourcePods]; ok {
+			used := quota.Status.Used[apicorev1.ResourcePods]
+			if remaining := hard.Value() - used.Value(); remaining < int64(pods) {
+				problems = append(problems, fmt.Sprintf("ResourceQuota %v allows %v more pods but at least %v are needed", quota.Name, remaining, pods))
+			}
+		}
+
+		for name := range quota.Spec.Hard {
+			resource, isLimit := quotaComputeResource(name)
+			if len(resource) == 0 {
+				continue
+			}
+			defaults := defaultRequests
+			if isLimit {
+				defaults = defaultLimits
+			}
+			if _, ok := defaults[resource]; ok {
+				continue
+			}
+
+			missing := []string{}
+			for _, c := range containers {
+				values := c.Resources.Requests
+				if isLimit {
+					values = c.Resources.Limits
+				}
+				if _, ok := values[resource]; !ok {
+					missing = append(missing, c.Name)
+				}
+			}
+			if len(missing) > 0 {
+				problems = append(problems, fmt.Sprintf("ResourceQuota %v requires %v to be set but containers [%v] don't set it and no LimitRange provides a default", quota.Name, name, strings.Join(missing, ", ")))
+			}
+		}
+	}
+
+	for _, lr := range limitRanges {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != apicorev1.LimitTypeContainer {
+				continue
+			}
+			for name, max := range item.Max {
+				for _, c := range containers {
+					for _, values := range []apicorev1.ResourceList{c.Resources.Requests, c.Resources.Limits} {
+						if q, ok := values[name]; ok && q.Cmp(max) > 0 {
+							problems = append(problems, fmt.Sprintf("LimitRange %v allows at most %v %v per container but container %v uses %v", lr.Name, max.String(), name, c.Name, q.String()))
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// quotaComputeResource returns the container resource constrained by the quota and whether the
+// quota is on limits (as opposed to requests). Returns an empty name for non-compute quotas.
+func quotaComputeResource(name apicorev1.ResourceName) (apicorev1.ResourceName, bool) {
+	switch name {
+	case apicorev1.ResourceCPU, apicorev1.ResourceRequestsCPU:
+		return apicorev1.ResourceCPU, false
+	case apicorev1.ResourceMemory, apicorev1.ResourceRequestsMemory:
+		return apicorev1.ResourceMemory, false
+	case apicorev1.ResourceLimitsCPU:
+		return apicorev1.ResourceCPU, true
+	case apicorev1.ResourceLimitsMemory:
+		return apicorev1.ResourceMemory, true
+	default:
+		return "", false
+	}
+}
+
+// preflightNodeCheck ensures there are nodes the daemonset plugins can be scheduled on.
+func preflightNodeCheck(client kubernetes.Interface, cfg *PreflightConfig, pm *preflightManifest) error {
+	if pm == nil {
+		return nil
+	}
+	daemonsets := pm.daemonSetPlugins()
+	if len(daemonsets) == 0 {
+		return nil
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return &PreflightError{Severity: PreflightSeverityWarn, Err: errors.Wrap(err, "unable to list nodes to check daemonset scheduling")}
+	}
+
+	return nodeCheck(nodes.Items, daemonsets)
+}
+
+func nodeCheck(nodes []apicorev1.Node, daemonsets []manifest.Manifest) error {
+	problems := []string{}
+	severity := PreflightSeverityWarn
+	for _, def := range daemonsets {
+		// Use the plugin itself to determine which nodes it targets so that node selectors
+		// are handled identically to the run.
+		p := daemonset.NewPlugin(def, "", "", "", "", nil)
+		targeted := map[string]bool{}
+		for _, r := range p.ExpectedResults(nodes) {
+			targeted[r.NodeName] = true
+		}
+
+		tolerations := driver.DefaultPodSpec(def.SonobuoyConfig.Driver).Tolerations
+		if def.PodSpec != nil {
+			tolerations = def.PodSpec.Tolerations
+		}
+
+		excluded := []string{}
+		for _, node := range nodes {
+			if !targeted[node.Name] {
+				continue
+			}
+			if taints := untoleratedTaints(node, tolerations); len(taints) > 0 {
+				excluded = append(excluded, fmt.Sprintf("%v (%v)", node.Name, strings.Join(taints, ", ")))
+			}
+		}
+
+		switch {
+		case len(targeted) == 0:
+			severity = PreflightSeverityError
+			problems = append(problems, fmt.Sprintf("no nodes match the node selector of daemonset plugin %v", def.SonobuoyConfig.PluginName))
+		case len(excluded) == len(targeted):
+			severity = PreflightSeverityError
+			problems = append(problems, fmt.Sprintf("daemonset plugin %v does not tolerate the taints of any node: %v", def.SonobuoyConfig.PluginName, strings.Join(excluded, "; ")))
+		case len(excluded) > 0:
+			problems = append(problems, fmt.Sprintf("daemonset plugin %v will not be scheduled on %v of %v nodes due to untolerated taints: %v", def.SonobuoyConfig.PluginName, len(excluded), len(targeted), strings.Join(excluded, "; ")))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &PreflightError{Severity: severity, Err: errors.New(strings.Join(problems, "; "))}
+}
+
+// untoleratedTaints returns the taints which prevent scheduling on the node.
+func untoleratedTaints(node apicorev1.Node, tolerations []apicorev1.Toleration) []string {
+	out := []string{}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == apicorev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			out = append(out, taint.ToString())
+		}
+	}
+	return out
+}
+
+const (
+	// imagePullCheckCommand doesn't exist in the images so that the containers of the image pull
+	// pods fail to start as soon as their image has been pulled.
+	imagePullCheckCommand = "/sonobuoy-image-pull-check"
+
+	// imagePullCheckComponent is the sonobuoy-component label of the pods created by the image pull check.
+	imagePullCheckComponent = "preflight-image-pull"
+)
+
+var (
+	// imagePullCheckTimeout is how long to wait for the images to be pulled before giving up.
+	imagePullCheckTimeout = 5 * time.Minute
+
+	// imagePullCheckInterval is how often the image pull pods are polled.
+	imagePullCheckInterval = 2 * time.Second
+
+	// nodePoolLabels identify the node pool of a node for common providers, in order of preference.
+	// Nodes without any of them are grouped by OS and architecture.
+	nodePoolLabels = []string{
+		"cloud.google.com/gke-nodepool",
+		"eks.amazonaws.com/nodegroup",
+		"kubernetes.azure.com/agentpool",
+		"node.kubernetes.io/instance-type",
+	}
+
+	// imagePullFailureReasons are the container waiting reasons which mean the image can't be pulled.
+	imagePullFailureReasons = map[string]bool{
+		"ErrImagePull":      true,
+		"ImagePullBackOff":  true,
+		"InvalidImageName":  true,
+		"ErrImageNeverPull": true,
+	}
+)
+
+// imagePullTarget is a node, representing its node pool, and the images which need to be pulled there.
+type imagePullTarget struct {
+	pool   string
+	node   string
+	images []string
+}
+
+// preflightImagePullCheck runs a short-lived pod on a node of each node pool the aggregator and
+// plugins will be scheduled on, ensuring the nodes can pull every image the run needs. It only
+// runs if enabled in the config.
+func preflightImagePullCheck(client kubernetes.Interface, cfg *PreflightConfig, pm *preflightManifest) error {
+	if !cfg.ImagePullCheck || pm == nil {
+		return nil
+	}
+
+	warnings := []string{}
+	var testImages []string
+	var err error
+	if cfg.TestImageLister != nil {
+		for _, p := range pm.plugins {
+			if p.SonobuoyConfig.PluginName != e2ePluginName {
+				continue
+			}
+			testImages, err = listTestImages(cfg.TestImageLister, p.Spec.Image, p.Spec.Env)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("only the images in the manifest were checked: %v", err))
+			}
+		}
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return &PreflightError{Severity: PreflightSeverityWarn, Err: errors.Wrap(err, "unable to list nodes to check image pulls")}
+	}
+
+	targets := imagePullTargets(nodes.Items, pm, testImages)
+	if len(targets) == 0 {
+		return nil
+	}
+
+	failures, pullWarnings, err := imagePullCheck(client, cfg, pm, targets)
+	if err != nil {
+		return &PreflightError{Severity: PreflightSeverityWarn, Err: errors.Wrap(err, "unable to check image pulls")}
+	}
+	warnings = append(warnings, pullWarnings...)
+
+	switch {
+	case len(failures) > 0:
+		return &PreflightError{Severity: PreflightSeverityError, Err: errors.New(strings.Join(append(failures, warnings...), "; "))}
+	case len(warnings) > 0:
+		return &PreflightError{Severity: PreflightSeverityWarn, Err: errors.New(strings.Join(warnings, "; "))}
+	}
+	return nil
+}
+
+// imagePullTargets picks a node from each node pool and determines which images need to be pulled
+// there based on the node selectors and tolerations of the aggregator and plugins.
+func imagePullTargets(nodes []apicorev1.Node, pm *preflightManifest, testImages []string) []imagePullTarget {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	type workload struct {
+		def    manifest.Manifest
+		images []string
+	}
+	workloads := []workload{}
+	workerImage := ""
+	if pm.aggregator != nil {
+		workloads = append(workloads, workload{
+			def:    manifest.Manifest{PodSpec: &manifest.PodSpec{PodSpec: pm.aggregator.Spec}},
+			images: podSpecImages(pm.aggregator.Spec),
+		})
+		if len(pm.aggregator.Spec.Containers) > 0 {
+			workerImage = pm.aggregator.Spec.Containers[0].Image
+		}
+	}
+	for _, p := range pm.plugins {
+		// Node selection mirrors the run, which uses the driver's default pod spec if none is given.
+		def := p
+		if def.PodSpec == nil {
+			def.PodSpec = &manifest.PodSpec{PodSpec: driver.DefaultPodSpec(p.SonobuoyConfig.Driver)}
+		}
+		images := append(podSpecImages(def.PodSpec.PodSpec), p.Spec.Image, workerImage)
+		if p.SonobuoyConfig.PluginName == e2ePluginName {
+			images = append(images, testImages...)
+		}
+		workloads = append(workloads, workload{def: def, images: images})
+	}
+
+	pools := []string{}
+	targets := map[string]*imagePullTarget{}
+	for _, w := range workloads {
+		selected := daemonset.NewPlugin(w.def, "", "", "", "", nil).ExpectedResults(nodes)
+		matched := map[string]bool{}
+		for _, r := range selected {
+			matched[r.NodeName] = true
+		}
+		for _, node := range nodes {
+			if !matched[node.Name] || node.Spec.Unschedulable || len(untoleratedTaints(node, w.def.PodSpec.Tolerations)) > 0 {
+				continue
+			}
+			pool := nodePool(node)
+			t, ok := targets[pool]
+			if !ok {
+				t = &imagePullTarget{pool: pool, node: node.Name}
+				targets[pool] = t
+				pools = append(pools, pool)
+			}
+			t.images = append(t.images, w.images...)
+		}
+	}
+
+	sort.Strings(pools)
+	ret := []imagePullTarget{}
+	for _, pool := range pools {
+		t := targets[pool]
+		t.images = uniqueImages(t.images)
+		ret = append(ret, *t)
+	}
+	return ret
+}
+
+func podSpecImages(spec apicorev1.PodSpec) []string {
+	images := []string{}
+	for _, c := range append(spec.InitContainers, spec.Containers...) {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+// uniqueImages returns the sorted, non-empty images without duplicates.
+func uniqueImages(images []string) []string {
+	seen := map[string]bool{}
+	ret := []string{}
+	for _, image := range images {
+		if len(image) == 0 || seen[image] {
+			continue
+		}
+		seen[image] = true
+		ret = append(ret, image)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// nodePool returns the name of the node pool of the node.
+func nodePool(node apicorev1.Node) string {
+	for _, l := range nodePoolLabels {
+		if v, ok := node.Labels[l]; ok && len(v) > 0 {
+			return v
+		}
+	}
+	return fmt.Sprintf("%v/%v", node.Labels[apicorev1.LabelOSStable], node.Labels[apicorev1.LabelArchStable])
+}
+
+// imagePullCheck creates the image pull pods and waits for them to pull their images, returning
+// the images which couldn't be pulled and those which weren't pulled before the timeout.
+func imagePullCheck(client kubernetes.Interface, cfg *PreflightConfig, pm *preflightManifest, targets []imagePullTarget) ([]string, []string, error) {
+	ns, cleanup, err := imagePullNamespace(client, cfg, pm)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+
+	pullSecrets := []apicorev1.LocalObjectReference{}
+	if pm.aggregator != nil {
+		pullSecrets = append(pullSecrets, pm.aggregator.Spec.ImagePullSecrets...)
+	}
+	pullPolicy := apicorev1.PullIfNotPresent
+	if pm.aggregator != nil && len(pm.aggregator.Spec.Containers) > 0 && len(pm.aggregator.Spec.Containers[0].ImagePullPolicy) > 0 {
+		pullPolicy = pm.aggregator.Spec.Containers[0].ImagePullPolicy
+	}
+
+	pods := map[string]imagePullTarget{}
+	for i, t := range targets {
+		pod := imagePullPod(fmt.Sprintf("sonobuoy-image-pull-%d", i), t, pullPolicy, pullSecrets)
+		if _, err := client.CoreV1().Pods(ns).Create(context.TODO(), pod, metav1.CreateOptions{}); err != nil {
+			return nil, nil, errors.Wrapf(err, "creating image pull pod for node %v", t.node)
+		}
+		pods[pod.Name] = t
+	}
+
+	var failures, pending []string
+	deadline := time.Now().Add(imagePullCheckTimeout)
+	for {
+		list, err := client.CoreV1().Pods(ns).List(context.TODO(), metav1.ListOptions{LabelSelector: "sonobuoy-component=" + imagePullCheckComponent})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "listing image pull pods")
+		}
+		failures, pending = []string{}, []string{}
+		for i := range list.Items {
+			t, ok := pods[list.Items[i].Name]
+			if !ok {
+				continue
+			}
+			podFailures, podPending := imagePullStatus(&list.Items[i])
+			for image, msg := range podFailures {
+				failures = append(failures, fmt.Sprintf("image %v could not be pulled on node %v (pool %v): %v", image, t.node, t.pool, msg))
+			}
+			if len(podPending) > 0 {
+				pending = append(pending, fmt.Sprintf("images [%v] were not pulled on node %v (pool %v) within %v", strings.Join(podPending, ", "), t.node, t.pool, imagePullCheckTimeout))
+			}
+		}
+		if len(pending) == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(imagePullCheckInterval)
+	}
+	sort.Strings(failures)
+	sort.Strings(pending)
+	return failures, pending, nil
+}
+
+// imagePullNamespace returns the namespace to run the image pull pods in along with a func to
+// clean up after the check. Since the namespace of the run is created by the run itself, a
+// temporary namespace is used unless the run is namespace-scoped.
+func imagePullNamespace(client kubernetes.Interface, cfg *PreflightConfig, pm *preflightManifest) (string, func(), error) {
+	if cfg.NamespaceScoped {
+		return cfg.Namespace, func() {
+			err := client.CoreV1().Pods(cfg.Namespace).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: "sonobuoy-component=" + imagePullCheckComponent})
+			if err != nil {
+				logrus.Warningf("Failed to delete the image pull pods in namespace %v: %v", cfg.Namespace, err)
+			}
+		}, nil
+	}
+
+	ns := &apicorev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: cfg.Namespace + "-preflight"}}
+	if pm.namespace != nil {
+		// Use the same pod security level as the run.
+		ns.Labels = pm.namespace.Labels
+	}
+	if _, err := client.CoreV1().Namespaces().Create(context.TODO(), ns, metav1.CreateOptions{}); err != nil {
+		return "", nil, errors.Wrapf(err, "creating namespace %v", ns.Name)
+	}
+	cleanup := func() {
+		if err := client.CoreV1().Namespaces().Delete(context.TODO(), ns.Name, metav1.DeleteOptions{}); err != nil {
+			logrus.Warningf("Failed to delete namespace %v: %v", ns.Name, err)
+		}
+	}
+
+	// The pull secrets of the run are needed to pull private images.
+	for _, obj := range pm.objects {
+		if obj.GetKind() != "Secret" {
+			continue
+		}
+		secret := &apicorev1.Secret{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, secret); err != nil {
+			cleanup()
+			return "", nil, errors.Wrap(err, "reading secret from manifest")
+		}
+		if secret.Type != apicorev1.SecretTypeDockerConfigJson {
+			continue
+		}
+		secret.Namespace = ns.Name
+		if _, err := client.CoreV1().Secrets(ns.Name).Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+			cleanup()
+			return "", nil, errors.Wrapf(err, "creating secret %v", secret.Name)
+		}
+	}
+	return ns.Name, cleanup, nil
+}
+
+// imagePullPod returns a pod which pulls each image on the target node. The containers don't run
+// anything; they only exist so that the kubelet pulls the images.
+func imagePullPod(name string, t imagePullTarget, policy apicorev1.PullPolicy, pullSecrets []apicorev1.LocalObjectReference) *apicorev1.Pod {
+	automount := false
+	pod := &apicorev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"sonobuoy-component": imagePullCheckComponent}},
+		Spec: apicorev1.PodSpec{
+			NodeName:                     t.node,
+			RestartPolicy:                apicorev1.RestartPolicyNever,
+			AutomountServiceAccountToken: &automount,
+			ImagePullSecrets:             pullSecrets,
+			// The node has already been chosen based on the tolerations of the plugins.
+			Tolerations: []apicorev1.Toleration{{Operator: apicorev1.TolerationOpExists}},
+		},
+	}
+	for i, image := range t.images {
+		pod.Spec.Containers = append(pod.Spec.Containers, apicorev1.Container{
+			Name:            fmt.Sprintf("image-%d", i),
+			Image:           image,
+			ImagePullPolicy: policy,
+			Command:         []string{imagePullCheckCommand},
+		})
+	}
+	return pod
+}
+
+// imagePullStatus returns the images of the pod which failed to be pulled, mapped to the reason, and
+// those which are still being pulled. Any other state means the image was pulled.
+func imagePullStatus(pod *apicorev1.Pod) (map[string]string, []string) {
+	statuses := map[string]apicorev1.ContainerStatus{}
+	for _, s := range pod.Status.ContainerStatuses {
+		statuses[s.Name] = s
+	}
+
+	failures, pending := map[string]string{}, []string{}
+	for _, c := range pod.Spec.Containers {
+		s, ok := statuses[c.Name]
+		switch {
+		case !ok:
+			pending = append(pending, c.Image)
+		case s.State.Waiting != nil && imagePullFailureReasons[s.State.Waiting.Reason]:
+			failures[c.Image] = s.State.Waiting.Reason
+			if len(s.State.Waiting.Message) > 0 {
+				failures[c.Image] = s.State.Waiting.Message
+			}
+		case s.State.Waiting != nil && (s.State.Waiting.Reason == "ContainerCreating" || len(s.State.Waiting.Reason) == 0):
+			pending = append(pending, c.Image)
+		}
+	}
+	return failures, pending
+}
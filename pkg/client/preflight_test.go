@@ -20,17 +20,30 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	version "github.com/hashicorp/go-version"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	testhook "github.com/sirupsen/logrus/hooks/test"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	apicorev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	k8sversion "k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestVersionCheck(t *testing.T) {
@@ -242,6 +255,49 @@ func TestNamespaceCheck(t *testing.T) {
 	}
 }
 
+func TestNamespaceExistsCheck(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		getter    nsGetFunc
+		ns        string
+		expectErr string
+	}{
+		{
+			desc: "Existing namespace passes the check",
+			getter: func(context.Context, string, metav1.GetOptions) (*apicorev1.Namespace, error) {
+				return &apicorev1.Namespace{}, nil
+			},
+		}, {
+			desc: "Missing namespace fails the check",
+			getter: func(context.Context, string, metav1.GetOptions) (*apicorev1.Namespace, error) {
+				return nil, &statusErr{err: "test", status: metav1.Status{Reason: metav1.StatusReasonNotFound}}
+			},
+			ns:        "tenant",
+			expectErr: "namespace tenant does not exist; it must be created before a namespace-scoped run",
+		}, {
+			desc: "Random error bubbled up",
+			getter: func(context.Context, string, metav1.GetOptions) (*apicorev1.Namespace, error) {
+				return nil, errors.New("test")
+			},
+			expectErr: "error checking for namespace: test",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := nsExistsCheck(tc.getter, tc.ns)
+			if err != nil && len(tc.expectErr) == 0 {
+				t.Fatalf("Expected nil error but got %q", err)
+			}
+			if err == nil && len(tc.expectErr) > 0 {
+				t.Fatalf("Expected error %q but got nil", tc.expectErr)
+			}
+			if err != nil && fmt.Sprint(err) != tc.expectErr {
+				t.Fatalf("Expected error to be %q but got %q", tc.expectErr, err)
+			}
+		})
+	}
+}
+
 func TestPreflightChecksInvalidConfig(t *testing.T) {
 	testcases := []struct {
 		desc               string
@@ -296,3 +352,499 @@ func (e *statusErr) Error() string {
 func (e *statusErr) Status() metav1.Status {
 	return e.status
 }
+
+func TestRBACCheck(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+
+	obj := func(kind, name, ns string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind(kind)
+		u.SetName(name)
+		u.SetNamespace(ns)
+		return u
+	}
+	objs := []*unstructured.Unstructured{
+		obj("Namespace", "sonobuoy", ""),
+		obj("ConfigMap", "a", "sonobuoy"),
+		obj("ConfigMap", "b", "sonobuoy"),
+		obj("Pod", "sonobuoy", "sonobuoy"),
+	}
+
+	testCases := []struct {
+		desc          string
+		objs          []*unstructured.Unstructured
+		denied        map[string]bool
+		expectReviews int
+		expectErr     string
+	}{
+		{
+			desc:          "All allowed and duplicates are only checked once",
+			objs:          objs,
+			expectReviews: 3,
+		}, {
+			desc:          "Denied resources are reported",
+			objs:          objs,
+			denied:        map[string]bool{"namespaces": true, "pods": true},
+			expectReviews: 3,
+			expectErr:     "missing permissions to create namespaces, pods in namespace sonobuoy",
+		}, {
+			desc:      "Unknown kind",
+			objs:      []*unstructured.Unstructured{obj("Widget", "a", "")},
+			expectErr: `unable to find the API resource for /v1, Kind=Widget: no matches for kind "Widget" in version "v1"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			reviews := 0
+			create := func(_ context.Context, r *authorizationv1.SelfSubjectAccessReview, _ metav1.CreateOptions) (*authorizationv1.SelfSubjectAccessReview, error) {
+				reviews++
+				attrs := r.Spec.ResourceAttributes
+				if attrs.Verb != "create" {
+					t.Errorf("Expected create verb but got %v", attrs.Verb)
+				}
+				if attrs.Resource == "namespaces" && len(attrs.Namespace) > 0 {
+					t.Errorf("Expected cluster-scoped review for namespaces but got namespace %v", attrs.Namespace)
+				}
+				r.Status.Allowed = !tc.denied[attrs.Resource]
+				return r, nil
+			}
+
+			err := rbacCheck(mapper, create, tc.objs)
+			if fmt.Sprint(err) != fmt.Sprint(errOrNil(tc.expectErr)) {
+				t.Errorf("Expected error %q but got %q", tc.expectErr, err)
+			}
+			if len(tc.expectErr) == 0 && reviews != tc.expectReviews {
+				t.Errorf("Expected %v reviews but got %v", tc.expectReviews, reviews)
+			}
+		})
+	}
+}
+
+func TestPSACheck(t *testing.T) {
+	ns := func(level string) *apicorev1.Namespace {
+		n := &apicorev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sonobuoy"}}
+		if len(level) > 0 {
+			n.Labels = map[string]string{psaEnforceLabel: level}
+		}
+		return n
+	}
+	ds := []manifest.Manifest{{SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "systemd-logs", Driver: "DaemonSet"}}}
+
+	testCases := []struct {
+		desc       string
+		ns         *apicorev1.Namespace
+		daemonsets []manifest.Manifest
+		expectErr  string
+	}{
+		{desc: "Privileged", ns: ns("privileged"), daemonsets: ds},
+		{desc: "No label", ns: ns(""), daemonsets: ds},
+		{desc: "Restricted without daemonsets", ns: ns("restricted")},
+		{
+			desc:       "Baseline with daemonsets",
+			ns:         ns("baseline"),
+			daemonsets: ds,
+			expectErr:  `namespace sonobuoy enforces the "baseline" pod security level but the daemonset plugins [systemd-logs] require "privileged"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := psaCheck(tc.ns, tc.daemonsets)
+			if fmt.Sprint(err) != fmt.Sprint(errOrNil(tc.expectErr)) {
+				t.Errorf("Expected error %q but got %q", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestQuotaCheck(t *testing.T) {
+	quota := func(hard, used apicorev1.ResourceList) apicorev1.ResourceQuota {
+		return apicorev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota"},
+			Spec:       apicorev1.ResourceQuotaSpec{Hard: hard},
+			Status:     apicorev1.ResourceQuotaStatus{Used: used},
+		}
+	}
+	limitRange := func(item apicorev1.LimitRangeItem) apicorev1.LimitRange {
+		item.Type = apicorev1.LimitTypeContainer
+		return apicorev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "limits"},
+			Spec:       apicorev1.LimitRangeSpec{Limits: []apicorev1.LimitRangeItem{item}},
+		}
+	}
+	containers := []apicorev1.Container{
+		{
+			Name: "plugin",
+			Resources: apicorev1.ResourceRequirements{
+				Requests: apicorev1.ResourceList{apicorev1.ResourceCPU: resource.MustParse("500m")},
+				Limits:   apicorev1.ResourceList{apicorev1.ResourceCPU: resource.MustParse("2")},
+			},
+		},
+		{Name: workerContainerName},
+	}
+
+	testCases := []struct {
+		desc        string
+		quotas      []apicorev1.ResourceQuota
+		limitRanges []apicorev1.LimitRange
+		expect      []string
+	}{
+		{
+			desc: "No quotas or limits",
+		}, {
+			desc:   "Enough pods remaining",
+			quotas: []apicorev1.ResourceQuota{quota(apicorev1.ResourceList{apicorev1.ResourcePods: resource.MustParse("10")}, apicorev1.ResourceList{apicorev1.ResourcePods: resource.MustParse("8")})},
+		}, {
+			desc:   "Not enough pods remaining",
+			quotas: []apicorev1.ResourceQuota{quota(apicorev1.ResourceList{apicorev1.ResourcePods: resource.MustParse("10")}, apicorev1.ResourceList{apicorev1.ResourcePods: resource.MustParse("9")})},
+			expect: []string{"ResourceQuota quota allows 1 more pods but at least 2 are needed"},
+		}, {
+			desc:   "Compute quota without defaults",
+			quotas: []apicorev1.ResourceQuota{quota(apicorev1.ResourceList{apicorev1.ResourceRequestsCPU: resource.MustParse("4")}, nil)},
+			expect: []string{"ResourceQuota quota requires requests.cpu to be set but containers [sonobuoy-worker] don't set it and no LimitRange provides a default"},
+		}, {
+			desc:        "Compute quota with defaults",
+			quotas:      []apicorev1.ResourceQuota{quota(apicorev1.ResourceList{apicorev1.ResourceRequestsCPU: resource.MustParse("4"), apicorev1.ResourceLimitsCPU: resource.MustParse("4")}, nil)},
+			limitRanges: []apicorev1.LimitRange{limitRange(apicorev1.LimitRangeItem{Default: apicorev1.ResourceList{apicorev1.ResourceCPU: resource.MustParse("1")}})},
+		}, {
+			desc:        "Limit range max exceeded",
+			limitRanges: []apicorev1.LimitRange{limitRange(apicorev1.LimitRangeItem{Max: apicorev1.ResourceList{apicorev1.ResourceCPU: resource.MustParse("1")}})},
+			expect:      []string{"LimitRange limits allows at most 1 cpu per container but container plugin uses 2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			problems := quotaCheck(tc.quotas, tc.limitRanges, containers, 2)
+			if len(problems) != len(tc.expect) {
+				t.Fatalf("Expected problems %q but got %q", tc.expect, problems)
+			}
+			for i := range problems {
+				if problems[i] != tc.expect[i] {
+					t.Errorf("Expected problem %q but got %q", tc.expect[i], problems[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNodeCheck(t *testing.T) {
+	node := func(name string, labels map[string]string, taints ...apicorev1.Taint) apicorev1.Node {
+		return apicorev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       apicorev1.NodeSpec{Taints: taints},
+		}
+	}
+	gpuTaint := apicorev1.Taint{Key: "gpu", Value: "true", Effect: apicorev1.TaintEffectNoSchedule}
+	preferTaint := apicorev1.Taint{Key: "spot", Effect: apicorev1.TaintEffectPreferNoSchedule}
+
+	plugin := func(podSpec *manifest.PodSpec) manifest.Manifest {
+		return manifest.Manifest{
+			SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "ds", Driver: "DaemonSet"},
+			PodSpec:        podSpec,
+		}
+	}
+	noTolerations := &manifest.PodSpec{PodSpec: apicorev1.PodSpec{}}
+
+	testCases := []struct {
+		desc         string
+		nodes        []apicorev1.Node
+		plugin       manifest.Manifest
+		expectErr    string
+		expectWarn   bool
+		expectNilErr bool
+	}{
+		{
+			desc:         "Default tolerations tolerate everything",
+			nodes:        []apicorev1.Node{node("a", nil, gpuTaint)},
+			plugin:       plugin(nil),
+			expectNilErr: true,
+		}, {
+			desc:         "PreferNoSchedule is ignored",
+			nodes:        []apicorev1.Node{node("a", nil, preferTaint)},
+			plugin:       plugin(noTolerations),
+			expectNilErr: true,
+		}, {
+			desc:       "Some nodes excluded",
+			nodes:      []apicorev1.Node{node("a", nil), node("b", nil, gpuTaint)},
+			plugin:     plugin(noTolerations),
+			expectWarn: true,
+			expectErr:  "daemonset plugin ds will not be scheduled on 1 of 2 nodes due to untolerated taints: b (gpu=true:NoSchedule)",
+		}, {
+			desc:      "All nodes excluded",
+			nodes:     []apicorev1.Node{node("b", nil, gpuTaint)},
+			plugin:    plugin(noTolerations),
+			expectErr: "daemonset plugin ds does not tolerate the taints of any node: b (gpu=true:NoSchedule)",
+		}, {
+			desc:  "No nodes match the selector",
+			nodes: []apicorev1.Node{node("a", map[string]string{"os": "linux"})},
+			plugin: plugin(&manifest.PodSpec{PodSpec: apicorev1.PodSpec{
+				NodeSelector: map[string]string{"os": "windows"},
+			}}),
+			expectErr: "no nodes match the node selector of daemonset plugin ds",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := nodeCheck(tc.nodes, []manifest.Manifest{tc.plugin})
+			if tc.expectNilErr {
+				if err != nil {
+					t.Fatalf("Expected no error but got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected error %q but got nil", tc.expectErr)
+			}
+			if err.Error() != tc.expectErr {
+				t.Errorf("Expected error %q but got %q", tc.expectErr, err.Error())
+			}
+			if IsPreflightWarning(err) != tc.expectWarn {
+				t.Errorf("Expected warning: %v but got %v", tc.expectWarn, IsPreflightWarning(err))
+			}
+		})
+	}
+}
+
+func TestRunPreflightChecksSeverity(t *testing.T) {
+	gen := &GenConfig{
+		Config:         config.New(),
+		DynamicPlugins: []string{"systemd-logs"},
+		KubeVersion:    "v1.27.0",
+	}
+	m, err := (&SonobuoyClient{}).GenerateManifest(gen)
+	if err != nil {
+		t.Fatalf("Unexpected error generating manifest: %v", err)
+	}
+
+	client := fake.NewSimpleClientset(
+		&apicorev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"kubernetes.io/os": "linux"}}},
+		&apicorev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "pods", Namespace: gen.Config.Namespace},
+			Spec:       apicorev1.ResourceQuotaSpec{Hard: apicorev1.ResourceList{apicorev1.ResourcePods: resource.MustParse("1")}},
+		},
+	)
+
+	errs := runPreflightChecks(client, &PreflightConfig{
+		Namespace:           gen.Config.Namespace,
+		Manifest:            m,
+		PreflightChecksSkip: []string{"versioncheck", "rbaccheck", "imagepullcheck"},
+	})
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error but got %v: %v", len(errs), errs)
+	}
+	pe, ok := errs[0].(*PreflightError)
+	if !ok {
+		t.Fatalf("Expected *PreflightError but got %T", errs[0])
+	}
+	if pe.Check != "quotacheck" || !IsPreflightWarning(pe) {
+		t.Errorf("Expected quotacheck warning but got %v %v: %v", pe.Check, pe.Severity, pe)
+	}
+}
+
+func TestRunPreflightChecksInvalidManifest(t *testing.T) {
+	// The manifest is only parsed once so the failure is only reported once.
+	errs := runPreflightChecks(fake.NewSimpleClientset(), &PreflightConfig{
+		Namespace:           "sonobuoy",
+		Manifest:            []byte("kind: ["),
+		PreflightChecksSkip: []string{"dnscheck", "versioncheck", "existingnamespace"},
+	})
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error but got %v: %v", len(errs), errs)
+	}
+	if IsPreflightWarning(errs[0]) {
+		t.Errorf("Expected an invalid manifest to be an error but got a warning: %v", errs[0])
+	}
+}
+
+func errOrNil(msg string) error {
+	if len(msg) == 0 {
+		return nil
+	}
+	return errors.New(msg)
+}
+
+type fakeTestImageLister []string
+
+func (f fakeTestImageLister) TestImages(conformanceImage string, env map[string]string) ([]string, error) {
+	return f, nil
+}
+
+func TestImagePullTargets(t *testing.T) {
+	node := func(name string, labels map[string]string, taints ...apicorev1.Taint) apicorev1.Node {
+		return apicorev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       apicorev1.NodeSpec{Taints: taints},
+		}
+	}
+	linux := map[string]string{"kubernetes.io/os": "linux", "kubernetes.io/arch": "amd64"}
+	gpu := map[string]string{"kubernetes.io/os": "linux", "cloud.google.com/gke-nodepool": "gpu"}
+	gpuTaint := apicorev1.Taint{Key: "gpu", Value: "true", Effect: apicorev1.TaintEffectNoSchedule}
+
+	pm := &preflightManifest{
+		aggregator: &apicorev1.Pod{Spec: apicorev1.PodSpec{
+			Containers:   []apicorev1.Container{{Image: "sonobuoy/sonobuoy:v1"}},
+			NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+		}},
+		plugins: []manifest.Manifest{
+			{
+				SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "e2e", Driver: "Job"},
+				Spec:           manifest.Container{Container: apicorev1.Container{Image: "conformance:v1"}},
+			}, {
+				SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "logs", Driver: "DaemonSet"},
+				Spec:           manifest.Container{Container: apicorev1.Container{Image: "logs:v1"}},
+			}, {
+				SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "windows", Driver: "Job"},
+				Spec:           manifest.Container{Container: apicorev1.Container{Image: "windows:v1"}},
+				PodSpec: &manifest.PodSpec{PodSpec: apicorev1.PodSpec{
+					NodeSelector: map[string]string{"kubernetes.io/os": "windows"},
+				}},
+			},
+		},
+	}
+	nodes := []apicorev1.Node{
+		node("c", linux),
+		node("b", gpu, gpuTaint),
+		node("a", linux),
+		node("d", gpu, gpuTaint),
+	}
+
+	got := imagePullTargets(nodes, pm, []string{"agnhost:2"})
+	expect := []imagePullTarget{
+		{pool: "gpu", node: "b", images: []string{"logs:v1", "sonobuoy/sonobuoy:v1"}},
+		{pool: "linux/amd64", node: "a", images: []string{"agnhost:2", "conformance:v1", "logs:v1", "sonobuoy/sonobuoy:v1"}},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected targets %+v but got %+v", expect, got)
+	}
+}
+
+func TestImagePullStatus(t *testing.T) {
+	pod := &apicorev1.Pod{
+		Spec: apicorev1.PodSpec{Containers: []apicorev1.Container{
+			{Name: "image-0", Image: "pulled:v1"},
+			{Name: "image-1", Image: "started:v1"},
+			{Name: "image-2", Image: "missing:v1"},
+			{Name: "image-3", Image: "backoff:v1"},
+			{Name: "image-4", Image: "pulling:v1"},
+			{Name: "image-5", Image: "unknown:v1"},
+		}},
+		Status: apicorev1.PodStatus{ContainerStatuses: []apicorev1.ContainerStatus{
+			{Name: "image-0", State: apicorev1.ContainerState{Terminated: &apicorev1.ContainerStateTerminated{Reason: "StartError"}}},
+			{Name: "image-1", State: apicorev1.ContainerState{Waiting: &apicorev1.ContainerStateWaiting{Reason: "RunContainerError"}}},
+			{Name: "image-2", State: apicorev1.ContainerState{Waiting: &apicorev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "not found"}}},
+			{Name: "image-3", State: apicorev1.ContainerState{Waiting: &apicorev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			{Name: "image-4", State: apicorev1.ContainerState{Waiting: &apicorev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+		}},
+	}
+
+	failures, pending := imagePullStatus(pod)
+	expectFailures := map[string]string{"missing:v1": "not found", "backoff:v1": "ImagePullBackOff"}
+	if !reflect.DeepEqual(failures, expectFailures) {
+		t.Errorf("Expected failures %v but got %v", expectFailures, failures)
+	}
+	if expectPending := []string{"pulling:v1", "unknown:v1"}; !reflect.DeepEqual(pending, expectPending) {
+		t.Errorf("Expected pending %v but got %v", expectPending, pending)
+	}
+}
+
+func TestPreflightImagePullCheck(t *testing.T) {
+	defer func(timeout, interval time.Duration) {
+		imagePullCheckTimeout, imagePullCheckInterval = timeout, interval
+	}(imagePullCheckTimeout, imagePullCheckInterval)
+	imagePullCheckTimeout, imagePullCheckInterval = 100*time.Millisecond, time.Millisecond
+
+	gen := &GenConfig{
+		Config:         config.New(),
+		DynamicPlugins: []string{"e2e"},
+		KubeVersion:    "v1.27.0",
+	}
+	m, err := (&SonobuoyClient{}).GenerateManifest(gen)
+	if err != nil {
+		t.Fatalf("Unexpected error generating manifest: %v", err)
+	}
+	node := &apicorev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{
+		"kubernetes.io/os":   "linux",
+		"kubernetes.io/arch": "amd64",
+	}}}
+
+	testCases := []struct {
+		desc       string
+		disabled   bool
+		states     map[string]apicorev1.ContainerState
+		expectErr  string
+		expectWarn bool
+	}{
+		{
+			desc: "All images pulled",
+		}, {
+			desc:     "Check not enabled",
+			disabled: true,
+			states: map[string]apicorev1.ContainerState{
+				"registry.k8s.io/conformance:v1.27.0": {Waiting: &apicorev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "not found"}},
+			},
+		}, {
+			desc: "Test image can't be pulled",
+			states: map[string]apicorev1.ContainerState{
+				"registry.k8s.io/e2e-test-images/agnhost:2.43": {Waiting: &apicorev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "not found"}},
+			},
+			expectErr: "image registry.k8s.io/e2e-test-images/agnhost:2.43 could not be pulled on node a (pool linux/amd64): not found",
+		}, {
+			desc: "Image still pulling",
+			states: map[string]apicorev1.ContainerState{
+				"registry.k8s.io/conformance:v1.27.0": {Waiting: &apicorev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+			},
+			expectErr:  "images [registry.k8s.io/conformance:v1.27.0] were not pulled on node a (pool linux/amd64) within 100ms",
+			expectWarn: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := fake.NewSimpleClientset(node)
+			client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				pod := action.(k8stesting.CreateAction).GetObject().(*apicorev1.Pod)
+				for _, c := range pod.Spec.Containers {
+					state, ok := tc.states[c.Image]
+					if !ok {
+						state = apicorev1.ContainerState{Terminated: &apicorev1.ContainerStateTerminated{Reason: "StartError"}}
+					}
+					pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, apicorev1.ContainerStatus{Name: c.Name, State: state})
+				}
+				return false, nil, nil
+			})
+
+			cfg := &PreflightConfig{
+				Namespace:       gen.Config.Namespace,
+				Manifest:        m,
+				ImagePullCheck:  !tc.disabled,
+				TestImageLister: fakeTestImageLister{"registry.k8s.io/e2e-test-images/agnhost:2.43", "invalid.registry.k8s.io/invalid/alpine:3.1"},
+			}
+			pm, err := parsePreflightManifest(cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error parsing manifest: %v", err)
+			}
+			err = preflightImagePullCheck(client, cfg, pm)
+			if len(tc.expectErr) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error but got %v", err)
+				}
+			} else {
+				if err == nil || err.Error() != tc.expectErr {
+					t.Fatalf("Expected error %q but got %v", tc.expectErr, err)
+				}
+				if IsPreflightWarning(err) != tc.expectWarn {
+					t.Errorf("Expected warning: %v but got %v", tc.expectWarn, IsPreflightWarning(err))
+				}
+			}
+
+			if tc.disabled && len(client.Actions()) > 0 {
+				t.Errorf("Expected no requests when the check isn't enabled but got %v", client.Actions())
+			}
+			if _, err := client.CoreV1().Namespaces().Get(context.TODO(), gen.Config.Namespace+"-preflight", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+				t.Errorf("Expected the temporary namespace to be deleted but got %v", err)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/vmware-tanzu/sonobuoy/pkg/config"
@@ -32,6 +33,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
 )
@@ -57,30 +59,52 @@ func (c *SonobuoyClient) RetrieveResults(cfg *RetrieveConfig) (io.Reader, <-chan
 		return nil, nil, errors.Wrap(err, "config validation failed")
 	}
 
-	ec := make(chan error, 1)
 	client, err := c.Client()
 	if err != nil {
+		ec := make(chan error, 1)
 		ec <- err
 		return nil, ec, nil
 	}
 
+	cmd := tarCmd(cfg.Path)
+	if cfg.Partial {
+		status, _, err := pluginaggregation.GetStatus(client, cfg.Namespace)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to get the status of the run")
+		}
+
+		plugins, err := completedPlugins(status, cfg.Plugins)
+		if err != nil {
+			return nil, nil, err
+		}
+		cmd = partialTarCmd(cfg.Path, cfg.Namespace, plugins)
+	}
+
+	return c.execOnAggregator(client, cfg.Namespace, cmd)
+}
+
+// execOnAggregator runs the given command in the aggregator container and returns a Reader
+// of its stdout. Errors encountered while streaming are sent on the returned channel.
+func (c *SonobuoyClient) execOnAggregator(client kubernetes.Interface, namespace string, cmd []string) (io.Reader, <-chan error, error) {
+	ec := make(chan error, 1)
+
 	// Determine sonobuoy pod name
-	podName, err := pluginaggregation.GetAggregatorPodName(client, cfg.Namespace)
+	podName, err := pluginaggregation.GetAggregatorPodName(client, namespace)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get the name of the aggregator pod to fetch results from")
 	}
 
-	logrus.Tracef("Running command %v on the aggregator", tarCmd(cfg.Path))
+	logrus.Tracef("Running command %v on the aggregator", cmd)
 	restClient := client.CoreV1().RESTClient()
 	req := restClient.Post().
 		Resource("pods").
 		Name(podName).
-		Namespace(cfg.Namespace).
+		Namespace(namespace).
 		SubResource("exec").
 		Param("container", config.AggregatorContainerName)
 	req.VersionedParams(&corev1.PodExecOptions{
 		Container: config.AggregatorContainerName,
-		Command:   tarCmd(cfg.Path),
+		Command:   cmd,
 		Stdin:     false,
 		Stdout:    true,
 		Stderr:    false,
@@ -277,6 +301,55 @@ func tarCmd(path string) []string {
 	}
 }
 
+// partialTarCmd is the command to run on the aggregator to get a tarball of the results
+// gathered so far for the given plugins.
+func partialTarCmd(path, namespace string, plugins []string) []string {
+	cmd := append(tarCmd(path), "--partial", "--namespace", namespace)
+	for _, p := range plugins {
+		cmd = append(cmd, "--plugin", p)
+	}
+	return cmd
+}
+
+// completedPlugins returns the names of the plugins which have reported all of their
+// results (including failures). If requested is non-empty, it instead ensures each of
+// the requested plugins has completed and returns them.
+func completedPlugins(status *pluginaggregation.Status, requested []string) ([]string, error) {
+	done := map[string]bool{}
+	for _, p := range status.Plugins {
+		isDone := p.Status == pluginaggregation.CompleteStatus || p.Status == pluginaggregation.FailedStatus
+		if prev, ok := done[p.Plugin]; ok {
+			isDone = prev && isDone
+		}
+		done[p.Plugin] = isDone
+	}
+
+	if len(requested) > 0 {
+		for _, name := range requested {
+			isDone, ok := done[name]
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("plugin %q is not part of this run", name)
+			case !isDone:
+				return nil, fmt.Errorf("plugin %q has not completed yet", name)
+			}
+		}
+		return requested, nil
+	}
+
+	completed := []string{}
+	for name, isDone := range done {
+		if isDone {
+			completed = append(completed, name)
+		}
+	}
+	if len(completed) == 0 {
+		return nil, errors.New("no plugins have completed yet")
+	}
+	sort.Strings(completed)
+	return completed, nil
+}
+
 func drainReader(r io.Reader) error {
 	b, err := io.ReadAll(r)
 	if err != nil {
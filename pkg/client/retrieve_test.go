@@ -19,8 +19,11 @@ package client
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/aggregation"
 )
 
 func TestRetrieveInvalidConfig(t *testing.T) {
@@ -39,6 +42,11 @@ func TestRetrieveInvalidConfig(t *testing.T) {
 			expectedError:    true,
 			expectedErrorMsg: "config validation failed",
 		},
+		{
+			config:           &RetrieveConfig{Namespace: "sonobuoy", Plugins: []string{"e2e"}},
+			expectedError:    true,
+			expectedErrorMsg: "plugins can only be specified for partial retrieval",
+		},
 	}
 
 	c, err := NewSonobuoyClient(nil, nil)
@@ -131,6 +139,78 @@ func TestTarCmd(t *testing.T) {
 	}
 }
 
+func TestPartialTarCmd(t *testing.T) {
+	expected := []string{"/sonobuoy", "splat", "/tmp/sonobuoy", "--partial", "--namespace", "ns", "--plugin", "a", "--plugin", "b"}
+	cmd := partialTarCmd("/tmp/sonobuoy", "ns", []string{"a", "b"})
+	if !reflect.DeepEqual(cmd, expected) {
+		t.Errorf("Expected %v, got %v", expected, cmd)
+	}
+}
+
+func TestCompletedPlugins(t *testing.T) {
+	status := &aggregation.Status{
+		Plugins: []aggregation.PluginStatus{
+			{Plugin: "e2e", Node: "global", Status: aggregation.RunningStatus},
+			{Plugin: "systemd-logs", Node: "node1", Status: aggregation.CompleteStatus},
+			{Plugin: "systemd-logs", Node: "node2", Status: aggregation.FailedStatus},
+			{Plugin: "ds", Node: "node1", Status: aggregation.CompleteStatus},
+			{Plugin: "ds", Node: "node2", Status: aggregation.RunningStatus},
+			{Plugin: "job", Node: "global", Status: aggregation.CompleteStatus},
+		},
+	}
+
+	testCases := []struct {
+		desc        string
+		status      *aggregation.Status
+		requested   []string
+		expect      []string
+		expectedErr string
+	}{
+		{
+			desc:   "All completed plugins by default",
+			status: status,
+			expect: []string{"job", "systemd-logs"},
+		}, {
+			desc:      "Requested plugin completed",
+			status:    status,
+			requested: []string{"systemd-logs"},
+			expect:    []string{"systemd-logs"},
+		}, {
+			desc:        "Requested plugin partially complete",
+			status:      status,
+			requested:   []string{"ds"},
+			expectedErr: `plugin "ds" has not completed yet`,
+		}, {
+			desc:        "Requested plugin not in run",
+			status:      status,
+			requested:   []string{"foo"},
+			expectedErr: `plugin "foo" is not part of this run`,
+		}, {
+			desc: "No completed plugins",
+			status: &aggregation.Status{Plugins: []aggregation.PluginStatus{
+				{Plugin: "e2e", Node: "global", Status: aggregation.RunningStatus},
+			}},
+			expectedErr: "no plugins have completed yet",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			out, err := completedPlugins(tc.status, tc.requested)
+			switch {
+			case len(tc.expectedErr) > 0 && err == nil:
+				t.Fatalf("Expected error %q but got nil", tc.expectedErr)
+			case len(tc.expectedErr) > 0 && err.Error() != tc.expectedErr:
+				t.Fatalf("Expected error %q but got %q", tc.expectedErr, err.Error())
+			case len(tc.expectedErr) == 0 && err != nil:
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			if !reflect.DeepEqual(out, tc.expect) {
+				t.Errorf("Expected %v but got %v", tc.expect, out)
+			}
+		})
+	}
+}
+
 func TestDirExists(t *testing.T) {
 	// Create temporary directory for testing
 	tmpDir, err := os.MkdirTemp("", "sonobuoy-test-")
@@ -60,28 +60,12 @@ var (
 // separated by `---`. This method will disregard the RunConfig.GenConfig
 // and instead use the given []byte as the manifest.
 func (c *SonobuoyClient) RunManifest(cfg *RunConfig, manifest []byte) error {
-	buf := bytes.NewBuffer(manifest)
-	d := yaml.NewYAMLOrJSONDecoder(buf, bufferSize)
-
-	for {
-		ext := runtime.RawExtension{}
-		if err := d.Decode(&ext); err != nil {
-			if err == io.EOF {
-				break
-			}
-			return errors.Wrap(err, "couldn't decode template")
-		}
-
-		// Skip over empty or partial objects
-		ext.Raw = bytes.TrimSpace(ext.Raw)
-		if len(ext.Raw) == 0 || bytes.Equal(ext.Raw, []byte("null")) {
-			continue
-		}
+	objs, err := decodeManifest(manifest)
+	if err != nil {
+		return err
+	}
 
-		obj := &unstructured.Unstructured{}
-		if err := runtime.DecodeInto(scheme.Codecs.UniversalDecoder(), ext.Raw, obj); err != nil {
-			return errors.Wrap(err, "couldn't decode template")
-		}
+	for _, obj := range objs {
 		name, err := c.dynamicClient.Name(obj)
 		if err != nil {
 			return errors.Wrap(err, "could not get object name")
@@ -109,6 +93,36 @@ func (c *SonobuoyClient) RunManifest(cfg *RunConfig, manifest []byte) error {
 	return nil
 }
 
+// decodeManifest splits the output of `sonobuoy gen` into its objects.
+func decodeManifest(manifest []byte) ([]*unstructured.Unstructured, error) {
+	buf := bytes.NewBuffer(manifest)
+	d := yaml.NewYAMLOrJSONDecoder(buf, bufferSize)
+
+	objs := []*unstructured.Unstructured{}
+	for {
+		ext := runtime.RawExtension{}
+		if err := d.Decode(&ext); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "couldn't decode template")
+		}
+
+		// Skip over empty or partial objects
+		ext.Raw = bytes.TrimSpace(ext.Raw)
+		if len(ext.Raw) == 0 || bytes.Equal(ext.Raw, []byte("null")) {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := runtime.DecodeInto(scheme.Codecs.UniversalDecoder(), ext.Raw, obj); err != nil {
+			return nil, errors.Wrap(err, "couldn't decode template")
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
 // WaitForRun handles the 'wait' from sonobuoy run --wait. "Attaches" to the sonobuoy run
 // in the configured namespace and then returns when completed. Returns errors encountered
 func (c *SonobuoyClient) WaitForRun(cfg *RunConfig) error {
@@ -205,23 +219,31 @@ func (c *SonobuoyClient) Run(cfg *RunConfig) error {
 		return errors.New("nil RunConfig provided")
 	}
 
-	var manifest []byte
-	var err error
-	if len(cfg.GenFile) != 0 {
-		manifest, err = loadManifestFromFile(cfg.GenFile)
-		if err != nil {
-			return errors.Wrap(err, "loading manifest")
-		}
-	} else {
-		manifest, err = c.GenerateManifest(&cfg.GenConfig)
-		if err != nil {
-			return errors.Wrap(err, "couldn't run invalid manifest")
-		}
+	manifest, err := c.Manifest(cfg)
+	if err != nil {
+		return err
 	}
 
 	return c.RunManifest(cfg, manifest)
 }
 
+// Manifest returns the manifest which Run would create; either loaded from the RunConfig.GenFile
+// or generated from the RunConfig.GenConfig. Useful to inspect (e.g. via preflight checks) what
+// will be run before calling RunManifest.
+func (c *SonobuoyClient) Manifest(cfg *RunConfig) ([]byte, error) {
+	if cfg == nil {
+		return nil, errors.New("nil RunConfig provided")
+	}
+
+	if len(cfg.GenFile) != 0 {
+		manifest, err := loadManifestFromFile(cfg.GenFile)
+		return manifest, errors.Wrap(err, "loading manifest")
+	}
+
+	manifest, err := c.GenerateManifest(&cfg.GenConfig)
+	return manifest, errors.Wrap(err, "couldn't run invalid manifest")
+}
+
 func loadManifestFromFile(f string) ([]byte, error) {
 	if f == stdinFile {
 		if term.IsTerminal(int(os.Stdin.Fd())) {
@@ -54,6 +54,10 @@ const (
 	DefaultQueryQPS = 30
 	// DefaultQueryBurst is the peak number of queries per second Sonobuoy will make when gathering data.
 	DefaultQueryBurst = 50
+	// DefaultQueryConcurrency is the number of resource queries Sonobuoy will run at once when gathering data.
+	DefaultQueryConcurrency = 10
+	// DefaultQueryPageSize is the maximum number of objects Sonobuoy requests in each list call when gathering data.
+	DefaultQueryPageSize = int64(500)
 	// DefaultProgressUpdatesPort is the port on which the Sonobuoy worker will listen for status updates from its plugin.
 	DefaultProgressUpdatesPort = "8099"
 
@@ -88,11 +92,24 @@ var (
 
 	// DefaultResources is the default set of resources which are queried for after plugins run. The strings
 	// are compared against the resource.Name given by the client-go discovery client. The non-standard values
-	// that are included here are: podlogs, servergroups, serverversion. The value 'nodes', although a crawlable
-	// API value, also is used to query against the healthz and configz endpoints on the node.
-	// By providing the nil value we query them all (except secrets).
+	// that are included here are: podlogs, servergroups, serverversion, nodemetrics, noderesourcemetrics,
+	// nodestats and nodepods. The value 'nodes', although a crawlable API value, also is used to query against
+	// the healthz and configz endpoints on the node.
+	// By providing the nil value we query them all (except secrets, nodemetrics and nodepods).
 	DefaultResources = []string(nil)
 
+	// DefaultInventoryResources are the resources compared before and after the plugins run since
+	// plugins commonly leave them behind.
+	DefaultInventoryResources = []string{
+		"namespaces",
+		"customresourcedefinitions",
+		"validatingwebhookconfigurations",
+		"mutatingwebhookconfigurations",
+	}
+
+	// DefaultNodeDataMaxBytes is the default maximum size of each response gathered from the kubelets.
+	DefaultNodeDataMaxBytes = int64(10 * 1024 * 1024)
+
 	// DefaultDNSPodLabels are the label selectors that are used to locate the DNS pods in the cluster.
 	DefaultDNSPodLabels = []string{
 		"k8s-app=kube-dns",
@@ -134,6 +151,27 @@ type Config struct {
 	QPS       float32       `json:"QPS,omitempty" mapstructure:"QPS"`
 	Burst     int           `json:"Burst,omitempty" mapstructure:"Burst"`
 
+	// QueryConcurrency is the number of resource queries run at once. The queries share the QPS/Burst
+	// limits. Defaults to DefaultQueryConcurrency if unset.
+	QueryConcurrency int `json:"QueryConcurrency,omitempty" mapstructure:"QueryConcurrency"`
+
+	// QueryPageSize is the maximum number of objects requested in each list call so that large lists are
+	// gathered in pieces. Defaults to DefaultQueryPageSize if unset.
+	QueryPageSize int64 `json:"QueryPageSize,omitempty" mapstructure:"QueryPageSize"`
+
+	// Redaction is the set of rules for removing sensitive data from the queried resources and pod logs
+	// before they are written. Nothing is redacted if nil.
+	Redaction *RedactionConfig `json:"Redaction,omitempty" mapstructure:"Redaction"`
+
+	// Inventory selects the resources compared before and after the plugins run in order to find
+	// those left behind. DefaultInventoryResources are compared if nil.
+	Inventory *InventoryConfig `json:"Inventory,omitempty" mapstructure:"Inventory"`
+
+	// LogSummaryPatterns are sets of regular expressions counted in the pod logs for the health
+	// summary in addition to the default Errors and Warnings sets. A set with the same name as a
+	// default replaces it.
+	LogSummaryPatterns []LogPatternSet `json:"LogSummaryPatterns,omitempty" mapstructure:"LogSummaryPatterns"`
+
 	///////////////////////////////////////////////
 	// Plugin configurations settings
 	///////////////////////////////////////////////
@@ -159,6 +197,16 @@ type Config struct {
 	E2EDockerConfigFile      string              `json:"E2EDockerConfigFile,omitempty" mapstructure:"E2EDockerConfigFile,omitempty"`
 	NamespacePSAEnforceLevel string              `json:"NamespacePSAEnforceLevel,omitempty" mapstructure:"NamespacePSAEnforceLevel,omitempty"`
 
+	// NamespaceScoped runs Sonobuoy entirely within an existing namespace without any cluster-scoped
+	// permissions. No namespace or cluster-scoped objects are created and queries which require cluster-scoped
+	// access (nodes, cluster resources, other namespaces) are skipped.
+	NamespaceScoped bool `json:"NamespaceScoped,omitempty" mapstructure:"NamespaceScoped"`
+
+	// ImageDigests maps the images of the run to the digests they were pinned to when the manifest was
+	// generated with `sonobuoy gen --pin-digests`. It includes the images pulled by the e2e tests, which
+	// can't be pinned, so that they can be checked against the registry later.
+	ImageDigests map[string]string `json:"ImageDigests,omitempty" mapstructure:"ImageDigests"`
+
 	// ProgressUpdatesPort is the port on which the Sonobuoy worker will listen for status updates from its plugin.
 	ProgressUpdatesPort string `json:"ProgressUpdatesPort,omitempty" mapstructure:"ProgressUpdatesPort"`
 
@@ -172,6 +220,83 @@ type Config struct {
 // LimitConfig is a configuration on the limits of various responses, such as limits of sizes
 type LimitConfig struct {
 	PodLogs PodLogLimits `json:"PodLogs" mapstructure:"PodLogs"`
+
+	// NodeData limits the data gathered from the kubelet of each node. Defaults are used if nil.
+	NodeData *NodeDataLimits `json:"NodeData,omitempty" mapstructure:"NodeData"`
+}
+
+// NodeDataLimits limits the size of the responses gathered from each kubelet, such as /metrics
+// and /stats/summary, which can be large on busy nodes.
+type NodeDataLimits struct {
+	// MaxBytes is the maximum size of each response saved. Larger responses are truncated.
+	MaxBytes int64 `json:"MaxBytes" mapstructure:"MaxBytes"`
+}
+
+// NodeDataMaxBytes returns the maximum size of each kubelet response to save.
+func (l LimitConfig) NodeDataMaxBytes() int64 {
+	if l.NodeData == nil || l.NodeData.MaxBytes <= 0 {
+		return DefaultNodeDataMaxBytes
+	}
+	return l.NodeData.MaxBytes
+}
+
+// RedactionConfig describes the data to remove from the results.
+type RedactionConfig struct {
+	// Fields are masked in the objects of the matching kinds.
+	Fields []FieldRedaction `json:"Fields,omitempty" mapstructure:"Fields"`
+
+	// LogPatterns are regular expressions which are masked in pod logs. If the expression has capture
+	// groups only the groups are masked (e.g. `password=(\S+)`), otherwise the entire match is.
+	LogPatterns []string `json:"LogPatterns,omitempty" mapstructure:"LogPatterns"`
+}
+
+// InventoryConfig selects the resources listed before and after the plugins run.
+type InventoryConfig struct {
+	// Resources are the names of the resources (e.g. namespaces) to list. DefaultInventoryResources
+	// are listed if empty.
+	Resources []string `json:"Resources,omitempty" mapstructure:"Resources"`
+
+	// Disabled skips the inventories entirely.
+	Disabled bool `json:"Disabled,omitempty" mapstructure:"Disabled"`
+}
+
+// InventoryResources returns the resources to inventory, or nil if disabled.
+func (cfg *Config) InventoryResources() []string {
+	switch {
+	case cfg.Inventory == nil:
+		return DefaultInventoryResources
+	case cfg.Inventory.Disabled:
+		return nil
+	case len(cfg.Inventory.Resources) == 0:
+		return DefaultInventoryResources
+	}
+	return cfg.Inventory.Resources
+}
+
+// LogPatternSet is a named set of regular expressions searched for in the files of the results.
+type LogPatternSet struct {
+	// Name identifies the set in the summary (e.g. Errors).
+	Name string `json:"Name" mapstructure:"Name"`
+
+	// Files are matched against the path of each file within the results (e.g. ^podlogs/). The pod
+	// logs are searched if empty.
+	Files []string `json:"Files,omitempty" mapstructure:"Files"`
+
+	// Lines are matched against each line of the files. A line is counted once per set regardless
+	// of how many of them match.
+	Lines []string `json:"Lines" mapstructure:"Lines"`
+}
+
+// FieldRedaction masks the values at the given paths in matching objects. Empty Group, Version and
+// Kind values match any object; use "core" to match the core group only.
+type FieldRedaction struct {
+	Group   string `json:"Group,omitempty" mapstructure:"Group"`
+	Version string `json:"Version,omitempty" mapstructure:"Version"`
+	Kind    string `json:"Kind,omitempty" mapstructure:"Kind"`
+
+	// Paths are JSONPath expressions such as `.data`, `.spec.containers[*].env[*].value` or
+	// `.metadata.annotations['example.com/token']`. Maps and lists at a path have all their values masked.
+	Paths []string `json:"Paths" mapstructure:"Paths"`
 }
 
 // PodLogLimits limits the scope of response when getting logs from pods.
@@ -276,6 +401,22 @@ func (cfg *Config) OutputDir() string {
 	return path.Join(AggregatorResultsPath, cfg.UUID)
 }
 
+// ResourceQueryConcurrency returns the number of resource queries to run at once.
+func (cfg *Config) ResourceQueryConcurrency() int {
+	if cfg.QueryConcurrency <= 0 {
+		return DefaultQueryConcurrency
+	}
+	return cfg.QueryConcurrency
+}
+
+// ResourceQueryPageSize returns the maximum number of objects to request in each list call.
+func (cfg *Config) ResourceQueryPageSize() int64 {
+	if cfg.QueryPageSize <= 0 {
+		return DefaultQueryPageSize
+	}
+	return cfg.QueryPageSize
+}
+
 // QueryOutputDir returns the QueryDir if set and falls back to the
 // AggregatorResultsPath/:UUID to work on the aggregator by default.
 func (cfg *Config) QueryOutputDir() string {
@@ -22,6 +22,7 @@ import (
 	"io"
 	"net"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -126,9 +127,31 @@ func (cfg *Config) Validate() (errorsList []error) {
 		errorsList = append(errorsList, errors.New("Only one of sinceSeconds or sinceTime may be specified."))
 	}
 
+	for _, set := range cfg.LogSummaryPatterns {
+		if err := set.Validate(); err != nil {
+			errorsList = append(errorsList, err)
+		}
+	}
+
 	return errorsList
 }
 
+// Validate checks that the set is named and that its expressions compile.
+func (s LogPatternSet) Validate() error {
+	if len(s.Name) == 0 {
+		return errors.New("log pattern sets must have a name")
+	}
+	if len(s.Lines) == 0 {
+		return errors.Errorf("log pattern set %v has no line patterns", s.Name)
+	}
+	for _, expr := range append(append([]string{}, s.Files...), s.Lines...) {
+		if _, err := regexp.Compile(expr); err != nil {
+			return errors.Wrapf(err, "invalid pattern in log pattern set %v", s.Name)
+		}
+	}
+	return nil
+}
+
 // loadAllPlugins takes the given sonobuoy configuration and gives back a
 // plugin.Interface for every plugin specified by the configuration.
 func loadAllPlugins(cfg *Config) error {
@@ -240,3 +240,25 @@ func TestLoadAllPlugins(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateLogSummaryPatterns(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		set       LogPatternSet
+		expectErr bool
+	}{
+		{desc: "Valid", set: LogPatternSet{Name: "OOM", Files: []string{"^podlogs/"}, Lines: []string{"OOMKilled"}}},
+		{desc: "Missing name", set: LogPatternSet{Lines: []string{"OOMKilled"}}, expectErr: true},
+		{desc: "Missing lines", set: LogPatternSet{Name: "OOM"}, expectErr: true},
+		{desc: "Invalid file pattern", set: LogPatternSet{Name: "OOM", Files: []string{"("}, Lines: []string{"OOMKilled"}}, expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := New()
+			cfg.LogSummaryPatterns = []LogPatternSet{tc.set}
+			if errs := cfg.Validate(); (len(errs) > 0) != tc.expectErr {
+				t.Errorf("Expected error %v but got %v", tc.expectErr, errs)
+			}
+		})
+	}
+}
@@ -18,10 +18,8 @@ package discovery
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -114,6 +112,17 @@ func Run(restConf *rest.Config, cfg *config.Config) (errCount int) {
 		}
 	}
 
+	// Record a timeline of the cluster activity for the duration of the run. Stopped after the
+	// queries so that it covers everything the plugins were doing.
+	stopTimeline := func() {}
+	timeline, err := NewTimelineRecorder(kubeClient, cfg.Namespace, !cfg.NamespaceScoped, filepath.Join(metapath, TimelineFile))
+	if err != nil {
+		errlog.LogError(errors.Wrap(err, "could not start recording the timeline"))
+	} else {
+		stopTimeline = timeline.Start()
+	}
+	defer stopTimeline()
+
 	// Set initial annotation stating the pod is running. Ensures the annotation
 	// exists sooner for user/polling consumption and prevents issues were we try
 	// to patch a non-existant status later.
@@ -142,6 +151,16 @@ func Run(restConf *rest.Config, cfg *config.Config) (errCount int) {
 
 	// 4. Run the plugin aggregator. Save this error for clear logging later.
 	avoidResultsDirIssue(cfg.LoadedPlugins, cfg.ResultsDir)
+	attachQueryRunners(cfg.LoadedPlugins, restConf, cfg, t)
+
+	// Take an inventory of the resources plugins commonly leave behind so that they can be compared
+	// after the plugins finish. Skipped in namespace-scoped runs which cannot list cluster-wide.
+	var inventory *Inventory
+	if !cfg.NamespaceScoped && len(cfg.InventoryResources()) > 0 {
+		inventory, err = TakeInventory(restConf, cfg)
+		trackErrorsFor("taking inventory before running plugins")(err)
+	}
+
 	runErr := pluginaggregation.Run(kubeClient, cfg.LoadedPlugins, cfg.Aggregation, cfg.ProgressUpdatesPort, cfg.ResultsDir, cfg.Namespace, outpath)
 	trackErrorsFor("running plugins")(runErr)
 
@@ -152,10 +171,21 @@ func Run(restConf *rest.Config, cfg *config.Config) (errCount int) {
 		logrus.Errorf("Timeout occurred when running plugins. Inspect logs further for details.")
 	}
 
+	if inventory != nil {
+		after, err := TakeInventory(restConf, cfg)
+		trackErrorsFor("taking inventory after running plugins")(err)
+		if err == nil {
+			trackErrorsFor("saving inventory report")(
+				SaveInventoryReport(inventory, after, filepath.Join(metapath, InventoryFile)),
+			)
+		}
+	}
+
 	// Run queries.
 	trackErrorsFor("running queries")(
-		QueryCluster(restConf, cfg),
+		queryCluster(restConf, cfg, t, NewQueryRecorder()),
 	)
+	stopTimeline()
 
 	logrus.Infof("Log lines after this point will not appear in the downloaded tarball.")
 
@@ -200,9 +230,14 @@ func Run(restConf *rest.Config, cfg *config.Config) (errCount int) {
 		trackErrorsFor("saving" + results.InfoFile)(err)
 	}
 
-	// Add health metadata
+	// Add health metadata. Invalid log patterns are reported but the defaults are still used.
+	logPatterns, err := LogPatternsFromConfig(cfg)
+	if err != nil {
+		trackErrorsFor("loading log summary patterns")(err)
+		logPatterns = GetDefaultLogPatterns()
+	}
 	trackErrorsFor("adding health metadata")(
-		SaveHealthSummary(outpath),
+		SaveHealthSummaryWithLogPatterns(outpath, logPatterns),
 	)
 
 	// 8. tarball up results YYYYMMDDHHMM_sonobuoy_UID.tar.gz
@@ -214,7 +249,7 @@ func Run(restConf *rest.Config, cfg *config.Config) (errCount int) {
 	}
 	trackErrorsFor("assembling results tarball")(err)
 
-	tarInfo, err := getFileInfo(tb)
+	tarInfo, err := pluginaggregation.NewTarInfo(tb)
 	trackErrorsFor("recording tarball info")(err)
 
 	// 9. Mark final annotation stating the results are available and status is completed.
@@ -274,31 +309,6 @@ func statusCounts(item *results.Item, startingCounts map[string]int) {
 	startingCounts[item.Status]++
 }
 
-func getFileInfo(path string) (pluginaggregation.TarInfo, error) {
-	fi, err := os.Stat(path)
-	if err != nil {
-		return pluginaggregation.TarInfo{}, err
-	}
-
-	f, err := os.Open(path)
-	if err != nil {
-		return pluginaggregation.TarInfo{}, err
-	}
-	defer f.Close()
-
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return pluginaggregation.TarInfo{}, err
-	}
-
-	return pluginaggregation.TarInfo{
-		Name:      filepath.Base(path),
-		Size:      fi.Size(),
-		SHA256:    fmt.Sprintf("%x", h.Sum(nil)),
-		CreatedAt: time.Now(),
-	}, nil
-}
-
 // dumpPlugin will marshal the plugin to the appropriate location in the outputDir:
 // plugins/<name>/definition.json. This makes the data more clear for any consumer
 // looking at the tarball about what was.
@@ -0,0 +1,175 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	v1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// EventsLocation is the place under which the events which occurred during the run are stored.
+	EventsLocation = "events"
+
+	// CoreEventsFile is the filename of the core/v1 events, relative to EventsLocation.
+	CoreEventsFile = "core_v1_events.json"
+
+	// EventsV1File is the filename of the events.k8s.io/v1 events, relative to EventsLocation.
+	EventsV1File = "events.k8s.io_v1_events.json"
+
+	// eventsResourceName is the value in Resources which enables the events query.
+	eventsResourceName = "events"
+
+	// eventsPageSize is the number of events requested at a time to avoid huge responses.
+	eventsPageSize = 500
+)
+
+// QueryEvents gathers the core/v1 and events.k8s.io/v1 events in the given namespace (all namespaces
+// if empty) which were last seen after since. If since is zero, all events are gathered.
+func QueryEvents(kubeClient kubernetes.Interface, recorder *QueryRecorder, ns string, since time.Time, cfg *config.Config) error {
+	if cfg.Resources != nil && !sliceContains(cfg.Resources, eventsResourceName) {
+		logrus.Info("events not specified in non-nil Resources. Skipping events query.")
+		return nil
+	}
+
+	outdir := filepath.Join(cfg.QueryOutputDir(), EventsLocation)
+
+	timedQuery(recorder, "CoreEvents", ns, func() (time.Duration, error) {
+		return timedObjectQuery(outdir, CoreEventsFile, recorder.redactor, func() (interface{}, error) {
+			return listCoreEvents(kubeClient, ns, since)
+		})
+	})
+
+	timedQuery(recorder, "Events", ns, func() (time.Duration, error) {
+		return timedObjectQuery(outdir, EventsV1File, recorder.redactor, func() (interface{}, error) {
+			return listEventsV1(kubeClient, ns, since)
+		})
+	})
+
+	return nil
+}
+
+// withoutEventResources removes the core/v1 and events.k8s.io/v1 events from the resources to query.
+// The events keyword in Resources enables QueryEvents, which only gathers the events from the run,
+// so they aren't also dumped in full with the other namespaced resources.
+func withoutEventResources(gvrs []schema.GroupVersionResource) []schema.GroupVersionResource {
+	out := make([]schema.GroupVersionResource, 0, len(gvrs))
+	for _, gvr := range gvrs {
+		if gvr.Resource == eventsResourceName && (gvr.Group == v1.GroupName || gvr.Group == eventsv1.GroupName) {
+			continue
+		}
+		out = append(out, gvr)
+	}
+	return out
+}
+
+// listCoreEvents pages through the core/v1 events, keeping only those seen after since.
+func listCoreEvents(kubeClient kubernetes.Interface, ns string, since time.Time) (*v1.EventList, error) {
+	out := &v1.EventList{}
+	opts := metav1.ListOptions{Limit: eventsPageSize}
+	for {
+		list, err := kubeClient.CoreV1().Events(ns).List(context.TODO(), opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing core/v1 events")
+		}
+		for _, e := range list.Items {
+			if !coreEventLastSeen(e).Before(since) {
+				e.ManagedFields = nil
+				out.Items = append(out.Items, e)
+			}
+		}
+		if len(list.Continue) == 0 {
+			return out, nil
+		}
+		opts.Continue = list.Continue
+	}
+}
+
+// listEventsV1 pages through the events.k8s.io/v1 events, keeping only those seen after since.
+func listEventsV1(kubeClient kubernetes.Interface, ns string, since time.Time) (*eventsv1.EventList, error) {
+	out := &eventsv1.EventList{}
+	opts := metav1.ListOptions{Limit: eventsPageSize}
+	for {
+		list, err := kubeClient.EventsV1().Events(ns).List(context.TODO(), opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing events.k8s.io/v1 events")
+		}
+		for _, e := range list.Items {
+			if !eventV1LastSeen(e).Before(since) {
+				e.ManagedFields = nil
+				out.Items = append(out.Items, e)
+			}
+		}
+		if len(list.Continue) == 0 {
+			return out, nil
+		}
+		opts.Continue = list.Continue
+	}
+}
+
+// coreEventLastSeen returns the most recent time the event was observed. Depending on the
+// reporting component, any of the timestamps may be unset.
+func coreEventLastSeen(e v1.Event) time.Time {
+	return latest(
+		e.CreationTimestamp.Time,
+		e.FirstTimestamp.Time,
+		e.LastTimestamp.Time,
+		e.EventTime.Time,
+		seriesLastObserved(e.Series),
+	)
+}
+
+// eventV1LastSeen returns the most recent time the event was observed.
+func eventV1LastSeen(e eventsv1.Event) time.Time {
+	t := latest(
+		e.CreationTimestamp.Time,
+		e.EventTime.Time,
+		e.DeprecatedFirstTimestamp.Time,
+		e.DeprecatedLastTimestamp.Time,
+	)
+	if e.Series != nil {
+		t = latest(t, e.Series.LastObservedTime.Time)
+	}
+	return t
+}
+
+func seriesLastObserved(s *v1.EventSeries) time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	return s.LastObservedTime.Time
+}
+
+func latest(times ...time.Time) time.Time {
+	var out time.Time
+	for _, t := range times {
+		if t.After(out) {
+			out = t
+		}
+	}
+	return out
+}
@@ -0,0 +1,170 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWithoutEventResources(t *testing.T) {
+	gvrs := []schema.GroupVersionResource{
+		{Version: "v1", Resource: "pods"},
+		{Version: "v1", Resource: "events"},
+		{Group: "events.k8s.io", Version: "v1", Resource: "events"},
+		{Group: "example.com", Version: "v1", Resource: "events"},
+	}
+	expect := []schema.GroupVersionResource{
+		{Version: "v1", Resource: "pods"},
+		{Group: "example.com", Version: "v1", Resource: "events"},
+	}
+	if got := withoutEventResources(gvrs); !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %v but got %v", expect, got)
+	}
+}
+
+func TestQueryEvents(t *testing.T) {
+	runStart := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	before := metav1.NewTime(runStart.Add(-time.Hour))
+	during := metav1.NewTime(runStart.Add(time.Minute))
+
+	client := fake.NewSimpleClientset(
+		&corev1.Event{
+			ObjectMeta:    metav1.ObjectMeta{Name: "old", Namespace: "a", CreationTimestamp: before},
+			LastTimestamp: before,
+		},
+		&corev1.Event{
+			// Created before the run but seen again during it.
+			ObjectMeta:    metav1.ObjectMeta{Name: "repeated", Namespace: "a", CreationTimestamp: before},
+			LastTimestamp: during,
+		},
+		&corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: "series", Namespace: "b", CreationTimestamp: before},
+			Series:     &corev1.EventSeries{LastObservedTime: metav1.NewMicroTime(during.Time)},
+		},
+		&eventsv1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: "old-v1", Namespace: "a", CreationTimestamp: before},
+		},
+		&eventsv1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: "new-v1", Namespace: "a", CreationTimestamp: before},
+			EventTime:  metav1.NewMicroTime(during.Time),
+		},
+	)
+
+	testCases := []struct {
+		desc         string
+		ns           string
+		since        time.Time
+		resources    []string
+		expectCore   []string
+		expectV1     []string
+		expectNoFile bool
+	}{
+		{
+			desc:       "Only events in the run window",
+			since:      runStart,
+			expectCore: []string{"repeated", "series"},
+			expectV1:   []string{"new-v1"},
+		}, {
+			desc:       "All events without a start",
+			expectCore: []string{"old", "repeated", "series"},
+			expectV1:   []string{"new-v1", "old-v1"},
+		}, {
+			desc:       "Single namespace",
+			ns:         "b",
+			since:      runStart,
+			expectCore: []string{"series"},
+			expectV1:   nil,
+		}, {
+			desc:         "Not in resources",
+			resources:    []string{"pods"},
+			expectNoFile: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := config.New()
+			cfg.QueryDir = t.TempDir()
+			cfg.Resources = tc.resources
+			recorder := NewQueryRecorder()
+
+			if err := QueryEvents(client, recorder, tc.ns, tc.since, cfg); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			coreFile := filepath.Join(cfg.QueryOutputDir(), EventsLocation, CoreEventsFile)
+			if tc.expectNoFile {
+				if _, err := os.Stat(coreFile); !os.IsNotExist(err) {
+					t.Errorf("Expected no events file but got %v", err)
+				}
+				if len(recorder.queries) != 0 {
+					t.Errorf("Expected no queries recorded but got %v", len(recorder.queries))
+				}
+				return
+			}
+
+			coreEvents := &corev1.EventList{}
+			readJSON(t, coreFile, coreEvents)
+			var coreNames []string
+			for _, e := range coreEvents.Items {
+				coreNames = append(coreNames, e.Name)
+			}
+			sort.Strings(coreNames)
+			if !reflect.DeepEqual(coreNames, tc.expectCore) {
+				t.Errorf("Expected core events %v but got %v", tc.expectCore, coreNames)
+			}
+
+			v1Events := &eventsv1.EventList{}
+			readJSON(t, filepath.Join(cfg.QueryOutputDir(), EventsLocation, EventsV1File), v1Events)
+			var v1Names []string
+			for _, e := range v1Events.Items {
+				v1Names = append(v1Names, e.Name)
+			}
+			sort.Strings(v1Names)
+			if !reflect.DeepEqual(v1Names, tc.expectV1) {
+				t.Errorf("Expected events.k8s.io events %v but got %v", tc.expectV1, v1Names)
+			}
+
+			if len(recorder.queries) != 2 {
+				t.Errorf("Expected 2 queries recorded but got %v", len(recorder.queries))
+			}
+		})
+	}
+}
+
+func readJSON(t *testing.T, file string, obj interface{}) {
+	t.Helper()
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read %v: %v", file, err)
+	}
+	if err := json.Unmarshal(b, obj); err != nil {
+		t.Fatalf("Failed to decode %v: %v", file, err)
+	}
+}
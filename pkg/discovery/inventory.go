@@ -0,0 +1,220 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"github.com/vmware-tanzu/sonobuoy/pkg/dynamic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// InventoryFile is the file in MetaLocation which compares the resources before and after the
+	// plugins ran.
+	InventoryFile = "inventory.json"
+)
+
+// InventoryItem identifies a single object in an inventory.
+type InventoryItem struct {
+	Namespace       string    `json:"namespace,omitempty"`
+	Name            string    `json:"name"`
+	UID             types.UID `json:"uid"`
+	ResourceVersion string    `json:"resourceVersion"`
+}
+
+// Inventory is the list of objects of each resource at a point in time. Resources are keyed by
+// their group and resource name (e.g. customresourcedefinitions.apiextensions.k8s.io).
+type Inventory struct {
+	Time      time.Time                  `json:"time"`
+	Resources map[string][]InventoryItem `json:"resources"`
+
+	// Errors are the failures to list each resource, which are excluded from the comparison.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// InventoryDiff lists the objects of a resource which changed while the plugins ran.
+type InventoryDiff struct {
+	Resource string          `json:"resource"`
+	Added    []InventoryItem `json:"added,omitempty"`
+	Removed  []InventoryItem `json:"removed,omitempty"`
+	Modified []InventoryItem `json:"modified,omitempty"`
+}
+
+// InventoryReport compares the inventories taken before and after the plugins ran. Objects which
+// were added are likely to have been leaked by the plugins.
+type InventoryReport struct {
+	Before    time.Time         `json:"before"`
+	After     time.Time         `json:"after"`
+	Resources []InventoryDiff   `json:"resources,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// TakeInventory lists the inventory resources in the config. Objects in the Sonobuoy namespace and
+// those being deleted are ignored since they are expected to change during the run.
+func TakeInventory(restConf *rest.Config, cfg *config.Config) (*Inventory, error) {
+	apiHelper, err := dynamic.NewAPIHelperFromRESTConfig(restConf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get APIHelper from REST config")
+	}
+	clusterResources, nsResources, err := getAllFilteredResources(apiHelper, cfg.InventoryResources())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to filter resources")
+	}
+	return takeInventory(apiHelper, append(clusterResources, nsResources...), cfg.Namespace, cfg.ResourceQueryPageSize()), nil
+}
+
+func takeInventory(client *dynamic.APIHelper, gvrs []schema.GroupVersionResource, ignoreNamespace string, pageSize int64) *Inventory {
+	inv := &Inventory{
+		Time:      time.Now(),
+		Resources: map[string][]InventoryItem{},
+		Errors:    map[string]string{},
+	}
+	for _, gvr := range gvrs {
+		resource := gvr.GroupResource().String()
+		items, err := listInventoryItems(client, gvr, ignoreNamespace, pageSize)
+		if err != nil {
+			logrus.Errorf("Failed to take inventory of %v: %v", resource, err)
+			inv.Errors[resource] = err.Error()
+			continue
+		}
+		inv.Resources[resource] = items
+	}
+	return inv
+}
+
+func listInventoryItems(client *dynamic.APIHelper, gvr schema.GroupVersionResource, ignoreNamespace string, pageSize int64) ([]InventoryItem, error) {
+	items := []InventoryItem{}
+	opts := metav1.ListOptions{Limit: pageSize}
+	for {
+		list, err := client.Client.Resource(gvr).List(context.TODO(), opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing resource %v", gvr)
+		}
+		for _, obj := range list.Items {
+			if obj.GetDeletionTimestamp() != nil {
+				continue
+			}
+			if len(ignoreNamespace) > 0 && (obj.GetNamespace() == ignoreNamespace || (gvr.Group == "" && gvr.Resource == "namespaces" && obj.GetName() == ignoreNamespace)) {
+				continue
+			}
+			items = append(items, InventoryItem{
+				Namespace:       obj.GetNamespace(),
+				Name:            obj.GetName(),
+				UID:             obj.GetUID(),
+				ResourceVersion: obj.GetResourceVersion(),
+			})
+		}
+		if len(list.GetContinue()) == 0 {
+			return items, nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+// CompareInventories reports the objects added, removed or modified between the two inventories.
+// Objects are matched by UID so that those which were recreated are reported as added and removed.
+// Resources which could not be listed in either inventory are reported as errors.
+func CompareInventories(before, after *Inventory) InventoryReport {
+	report := InventoryReport{
+		Before: before.Time,
+		After:  after.Time,
+		Errors: map[string]string{},
+	}
+	for resource, err := range before.Errors {
+		report.Errors[resource] = err
+	}
+	for resource, err := range after.Errors {
+		report.Errors[resource] = err
+	}
+
+	resources := []string{}
+	for resource := range after.Resources {
+		if _, ok := before.Resources[resource]; ok {
+			resources = append(resources, resource)
+		}
+	}
+	sort.Strings(resources)
+
+	for _, resource := range resources {
+		diff := InventoryDiff{Resource: resource}
+		beforeItems := map[types.UID]InventoryItem{}
+		for _, item := range before.Resources[resource] {
+			beforeItems[item.UID] = item
+		}
+		for _, item := range after.Resources[resource] {
+			prev, ok := beforeItems[item.UID]
+			switch {
+			case !ok:
+				diff.Added = append(diff.Added, item)
+			case prev.ResourceVersion != item.ResourceVersion:
+				diff.Modified = append(diff.Modified, item)
+			}
+			delete(beforeItems, item.UID)
+		}
+		for _, item := range before.Resources[resource] {
+			if _, ok := beforeItems[item.UID]; ok {
+				diff.Removed = append(diff.Removed, item)
+			}
+		}
+		if len(diff.Added)+len(diff.Removed)+len(diff.Modified) > 0 {
+			report.Resources = append(report.Resources, diff)
+		}
+	}
+	if len(report.Errors) == 0 {
+		report.Errors = nil
+	}
+	return report
+}
+
+// Leaked returns the objects which were added while the plugins ran.
+func (r InventoryReport) Leaked() []LeakedResource {
+	var leaked []LeakedResource
+	for _, diff := range r.Resources {
+		for _, item := range diff.Added {
+			leaked = append(leaked, LeakedResource{
+				Resource:  diff.Resource,
+				Namespace: item.Namespace,
+				Name:      item.Name,
+			})
+		}
+	}
+	return leaked
+}
+
+// SaveInventoryReport compares the inventories and writes the report to the given file.
+func SaveInventoryReport(before, after *Inventory, filename string) error {
+	report := CompareInventories(before, after)
+	if leaked := report.Leaked(); len(leaked) > 0 {
+		logrus.Warningf("%v resources were created while the plugins ran and still exist; see %v", len(leaked), InventoryFile)
+	}
+	b, err := json.Marshal(report)
+	if err != nil {
+		return errors.Wrap(err, "encoding inventory report")
+	}
+	return errors.Wrap(os.WriteFile(filename, b, 0644), "saving inventory report")
+}
@@ -0,0 +1,162 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/dynamic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestTakeInventory(t *testing.T) {
+	namespaces := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	crds := schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	obj := func(apiVersion, kind, name, uid string, deleting bool) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		u.SetUID(types.UID(uid))
+		u.SetResourceVersion("1")
+		if deleting {
+			u.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+		}
+		return u
+	}
+	dynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{namespaces: "NamespaceList", crds: "CustomResourceDefinitionList"},
+		obj("v1", "Namespace", "default", "n1", false),
+		obj("v1", "Namespace", "sonobuoy", "n2", false),
+		obj("v1", "Namespace", "e2e-terminating", "n3", true),
+		obj("apiextensions.k8s.io/v1", "CustomResourceDefinition", "widgets.example.com", "c1", false),
+	)
+	helper := &dynamic.APIHelper{Client: dynClient}
+
+	inv := takeInventory(helper, []schema.GroupVersionResource{namespaces, crds}, "sonobuoy", 500)
+	expect := map[string][]InventoryItem{
+		"namespaces": {
+			{Name: "default", UID: "n1", ResourceVersion: "1"},
+		},
+		"customresourcedefinitions.apiextensions.k8s.io": {
+			{Name: "widgets.example.com", UID: "c1", ResourceVersion: "1"},
+		},
+	}
+	if !reflect.DeepEqual(inv.Resources, expect) {
+		t.Errorf("Expected %+v but got %+v", expect, inv.Resources)
+	}
+	if len(inv.Errors) > 0 {
+		t.Errorf("Expected no errors but got %v", inv.Errors)
+	}
+}
+
+func TestCompareInventories(t *testing.T) {
+	item := func(ns, name, uid, rv string) InventoryItem {
+		return InventoryItem{Namespace: ns, Name: name, UID: types.UID(uid), ResourceVersion: rv}
+	}
+	testCases := []struct {
+		desc         string
+		before       *Inventory
+		after        *Inventory
+		expect       []InventoryDiff
+		expectErrs   map[string]string
+		expectLeaked []LeakedResource
+	}{
+		{
+			desc: "No changes",
+			before: &Inventory{Resources: map[string][]InventoryItem{
+				"namespaces": {item("", "default", "n1", "1")},
+			}},
+			after: &Inventory{Resources: map[string][]InventoryItem{
+				"namespaces": {item("", "default", "n1", "1")},
+			}},
+		}, {
+			desc: "Added, removed and modified objects",
+			before: &Inventory{Resources: map[string][]InventoryItem{
+				"namespaces": {item("", "default", "n1", "1"), item("", "old", "n2", "1")},
+				"validatingwebhookconfigurations.admissionregistration.k8s.io": {},
+			}},
+			after: &Inventory{Resources: map[string][]InventoryItem{
+				"namespaces": {item("", "default", "n1", "2"), item("", "e2e-leftover", "n3", "5")},
+				"validatingwebhookconfigurations.admissionregistration.k8s.io": {item("", "webhook", "w1", "3")},
+			}},
+			expect: []InventoryDiff{
+				{
+					Resource: "namespaces",
+					Added:    []InventoryItem{item("", "e2e-leftover", "n3", "5")},
+					Removed:  []InventoryItem{item("", "old", "n2", "1")},
+					Modified: []InventoryItem{item("", "default", "n1", "2")},
+				}, {
+					Resource: "validatingwebhookconfigurations.admissionregistration.k8s.io",
+					Added:    []InventoryItem{item("", "webhook", "w1", "3")},
+				},
+			},
+			expectLeaked: []LeakedResource{
+				{Resource: "namespaces", Name: "e2e-leftover"},
+				{Resource: "validatingwebhookconfigurations.admissionregistration.k8s.io", Name: "webhook"},
+			},
+		}, {
+			desc: "Recreated objects are added and removed",
+			before: &Inventory{Resources: map[string][]InventoryItem{
+				"namespaces": {item("", "test", "n1", "1")},
+			}},
+			after: &Inventory{Resources: map[string][]InventoryItem{
+				"namespaces": {item("", "test", "n2", "4")},
+			}},
+			expect: []InventoryDiff{
+				{
+					Resource: "namespaces",
+					Added:    []InventoryItem{item("", "test", "n2", "4")},
+					Removed:  []InventoryItem{item("", "test", "n1", "1")},
+				},
+			},
+			expectLeaked: []LeakedResource{{Resource: "namespaces", Name: "test"}},
+		}, {
+			desc: "Resources which failed to list are not compared",
+			before: &Inventory{
+				Resources: map[string][]InventoryItem{},
+				Errors:    map[string]string{"namespaces": "forbidden"},
+			},
+			after: &Inventory{Resources: map[string][]InventoryItem{
+				"namespaces": {item("", "test", "n2", "4")},
+			}},
+			expectErrs: map[string]string{"namespaces": "forbidden"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			report := CompareInventories(tc.before, tc.after)
+			if !reflect.DeepEqual(report.Resources, tc.expect) {
+				t.Errorf("Expected %+v but got %+v", tc.expect, report.Resources)
+			}
+			if !reflect.DeepEqual(report.Errors, tc.expectErrs) {
+				t.Errorf("Expected errors %v but got %v", tc.expectErrs, report.Errors)
+			}
+			if got := report.Leaked(); !reflect.DeepEqual(got, tc.expectLeaked) {
+				t.Errorf("Expected leaked %+v but got %+v", tc.expectLeaked, got)
+			}
+		})
+	}
+}
@@ -18,12 +18,15 @@ package discovery
 
 import (
 	"bufio"
+	"io"
 	"io/fs"
 	"os"
 	"regexp"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
 )
 
 const (
@@ -40,21 +43,27 @@ const (
 	logPatternWarningString      = `[wW]arn`
 	logPatternWarningCodeString  = `^W[0-9]+`
 	logPatternLevelWarningString = `level=warn`
+
+	// MaxLogSamples is the number of matching lines kept for each set of patterns.
+	MaxLogSamples = 5
+
+	// maxLogSampleLength is the number of bytes kept from each matching line.
+	maxLogSampleLength = 512
 )
 
 // LogPattern is a struct that defines a class of log patterns,
 // a LogPatterns instance will contain one or more file name patterns, stored in filePathPattern
 // and another list of patterns that are meant to be matched against the content of files whose name matches the filePathPattern.
-// both filePathPattern and matchPatterns are one or more compiled regular expressions
+// both FilePathPatterns and MatchPatterns are one or more compiled regular expressions
 //
 // A match for a pattern defined in LogPatterns will happen if:
-// a certain file has a path that matches at least one of the regex in filePathPattern
+// a certain file has a path that matches at least one of the regex in FilePathPatterns
 // and
 // at least one of the lines in the content of this file matches at least one
-// of the regex patterns in matchPattern
+// of the regex patterns in MatchPatterns
 type LogPattern struct {
-	filePathPattern []*regexp.Regexp
-	matchPatterns   []*regexp.Regexp
+	FilePathPatterns []*regexp.Regexp
+	MatchPatterns    []*regexp.Regexp
 }
 
 // LogPatterns maps the name of a set of patterns to its components
@@ -65,6 +74,17 @@ type LogSummary map[string]LogHitCounter
 
 type LogHitCounter map[string]int
 
+// LogSamples maps the name of a set of patterns to the first lines which matched it.
+type LogSamples map[string][]LogSample
+
+// LogSample is a line which matched a set of patterns.
+type LogSample struct {
+	File    string `json:"file" yaml:"file"`
+	Line    int    `json:"line" yaml:"line"`
+	Text    string `json:"text" yaml:"text"`
+	Pattern string `json:"pattern" yaml:"pattern"`
+}
+
 // GetDefaultLogPatterns returns the default set of log patterns that can be used with ReadLogSummary
 func GetDefaultLogPatterns() LogPatterns {
 	return LogPatterns{
@@ -92,13 +112,37 @@ func GetDefaultLogPatterns() LogPatterns {
 	}
 }
 
+// Add compiles the given sets and adds them to the patterns, replacing any with the same name. Sets
+// without file patterns are matched against the pod logs.
+func (p LogPatterns) Add(sets ...config.LogPatternSet) error {
+	for _, set := range sets {
+		if err := set.Validate(); err != nil {
+			return err
+		}
+		pattern := LogPattern{}
+		files := set.Files
+		if len(files) == 0 {
+			files = []string{logFilePatternPodlogsString}
+		}
+		for _, expr := range files {
+			pattern.FilePathPatterns = append(pattern.FilePathPatterns, regexp.MustCompile(expr))
+		}
+		for _, expr := range set.Lines {
+			pattern.MatchPatterns = append(pattern.MatchPatterns, regexp.MustCompile(expr))
+		}
+		p[set.Name] = pattern
+	}
+	return nil
+}
+
 func getPatternNamesForfile(relFilePath string, patterns LogPatterns) []string {
 	result := make([]string, 0)
 
 	for patternName, pattern := range patterns {
-		for _, fileNameRegex := range pattern.filePathPattern {
+		for _, fileNameRegex := range pattern.FilePathPatterns {
 			if fileNameRegex.MatchString(relFilePath) {
 				result = append(result, patternName)
+				break
 			}
 		}
 	}
@@ -115,7 +159,15 @@ func getPatternNamesForfile(relFilePath string, patterns LogPatterns) []string {
 // The GetDefaultLogPatterns can be used to obtain such list.
 // Errors encountered while scanning the directory are logged but no error will be returned.
 func ReadLogSummary(r *results.Reader, patterns LogPatterns) (LogSummary, error) {
+	summary, _, err := ReadLogSummaryWithSamples(r, patterns)
+	return summary, err
+}
+
+// ReadLogSummaryWithSamples is the same as ReadLogSummary but also returns the first MaxLogSamples
+// lines which matched each type of condition.
+func ReadLogSummaryWithSamples(r *results.Reader, patterns LogPatterns) (LogSummary, LogSamples, error) {
 	logSummary := make(LogSummary)
+	logSamples := make(LogSamples)
 
 	findAndScanLogFiles := func(filePath string, info fs.FileInfo, err error) error {
 		if err != nil {
@@ -128,30 +180,50 @@ func ReadLogSummary(r *results.Reader, patterns LogPatterns) (LogSummary, error)
 				return nil
 			}
 
-			file, err := os.Open(filePath)
-			if err != nil {
-				logrus.Errorf("findAndScanLogFiles: ignoring file '%s' because scanning it failed: %s", filePath, err)
-				return nil
+			// Files in archives are read from the archive's reader so they can be scanned without
+			// being extracted; files in directories are opened as the walk is in the directory.
+			var file io.Reader
+			if tr, ok := info.Sys().(io.Reader); ok {
+				file = tr
+			} else {
+				f, err := os.Open(filePath)
+				if err != nil {
+					logrus.Errorf("findAndScanLogFiles: ignoring file '%s' because scanning it failed: %s", filePath, err)
+					return nil
+				}
+				defer f.Close()
+				file = f
 			}
-			defer file.Close()
 
 			scanner := bufio.NewScanner(file)
+			lineNumber := 0
 			for scanner.Scan() {
 				line := scanner.Text()
+				lineNumber++
 				for _, patternName := range patternsForFile {
-					for _, pattern := range patterns[patternName].matchPatterns {
+					for _, pattern := range patterns[patternName].MatchPatterns {
 						if pattern.MatchString(line) {
 							if _, ok := logSummary[patternName]; !ok {
 								logSummary[patternName] = make(LogHitCounter)
 							}
 							logSummary[patternName][filePath]++
+							if len(logSamples[patternName]) < MaxLogSamples {
+								logSamples[patternName] = append(logSamples[patternName], LogSample{
+									File:    filePath,
+									Line:    lineNumber,
+									Text:    truncateLogSample(line),
+									Pattern: pattern.String(),
+								})
+							}
 							//We can stop looping through patterns from this patternName for this line
 							break
 						}
 					}
 				}
 			}
-
+			if err := scanner.Err(); err != nil {
+				logrus.Warningf("findAndScanLogFiles: stopped scanning file '%s' after %d lines: %s", filePath, lineNumber, err)
+			}
 		}
 		return nil
 	}
@@ -159,10 +231,26 @@ func ReadLogSummary(r *results.Reader, patterns LogPatterns) (LogSummary, error)
 	if err != nil {
 		logrus.Errorf("Failed to scan log files: %s", err)
 	}
-	return logSummary, nil
+	return logSummary, logSamples, nil
+}
+
+func truncateLogSample(line string) string {
+	if len(line) <= maxLogSampleLength {
+		return line
+	}
+	return line[:maxLogSampleLength] + "..."
 }
 
 // ReadLogSummaryWithDefaultPatterns is a wrapper to ReadLogSummary + GetDefaultLogPatterns
 func ReadLogSummaryWithDefaultPatterns(r *results.Reader) (LogSummary, error) {
 	return ReadLogSummary(r, GetDefaultLogPatterns())
 }
+
+// LogPatternsFromConfig returns the default patterns along with any configured sets.
+func LogPatternsFromConfig(cfg *config.Config) (LogPatterns, error) {
+	patterns := GetDefaultLogPatterns()
+	if err := patterns.Add(cfg.LogSummaryPatterns...); err != nil {
+		return nil, errors.Wrap(err, "invalid log summary patterns")
+	}
+	return patterns, nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"github.com/vmware-tanzu/sonobuoy/pkg/tarball"
+)
+
+func TestReadLogSummaryCustomPatterns(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"podlogs/default/app/logs/app.txt":     "starting\nOOMKilled: container app\nready\nOut of memory\n",
+		"podlogs/default/db/logs/db.txt":       "Out of memory: killed process 1\n",
+		"plugins/e2e/results/global/e2e.log":   "OOMKilled in e2e\n",
+		"plugins/e2e/results/global/junit.xml": "<testsuite/>\n",
+	}
+	for name, contents := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tb := filepath.Join(t.TempDir(), "results.tar.gz")
+	if err := tarball.DirToTarball(dir, tb, true); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns := GetDefaultLogPatterns()
+	err := patterns.Add(
+		config.LogPatternSet{Name: "OOM", Lines: []string{"OOMKilled", "Out of memory"}},
+		config.LogPatternSet{Name: "PluginOOM", Files: []string{`^plugins/.*\.log$`}, Lines: []string{"OOMKilled"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectSummary := LogHitCounter{
+		"podlogs/default/app/logs/app.txt": 2,
+		"podlogs/default/db/logs/db.txt":   1,
+	}
+	expectSamples := []LogSample{
+		{File: "podlogs/default/app/logs/app.txt", Line: 2, Text: "OOMKilled: container app", Pattern: "OOMKilled"},
+		{File: "podlogs/default/app/logs/app.txt", Line: 4, Text: "Out of memory", Pattern: "Out of memory"},
+		{File: "podlogs/default/db/logs/db.txt", Line: 1, Text: "Out of memory: killed process 1", Pattern: "Out of memory"},
+	}
+
+	readers := map[string]func() (*results.Reader, func()){
+		"directory": func() (*results.Reader, func()) {
+			return results.NewReaderFromDir(dir), func() {}
+		},
+		"archive": func() (*results.Reader, func()) {
+			f, err := os.Open(tb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gzr, err := gzip.NewReader(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return results.NewReaderWithVersion(gzr, results.VersionTen), func() { gzr.Close(); f.Close() }
+		},
+	}
+	for desc, newReader := range readers {
+		t.Run(desc, func(t *testing.T) {
+			r, cleanup := newReader()
+			defer cleanup()
+
+			summary, samples, err := ReadLogSummaryWithSamples(r, patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(summary["OOM"], expectSummary) {
+				t.Errorf("Expected %v but got %v", expectSummary, summary["OOM"])
+			}
+			if !reflect.DeepEqual(samples["OOM"], expectSamples) {
+				t.Errorf("Expected samples %+v but got %+v", expectSamples, samples["OOM"])
+			}
+			if summary["PluginOOM"]["plugins/e2e/results/global/e2e.log"] != 1 || len(summary["PluginOOM"]) != 1 {
+				t.Errorf("Expected a single hit in the plugin logs but got %v", summary["PluginOOM"])
+			}
+			if _, ok := summary["Errors"]; ok {
+				t.Errorf("Expected no hits for the default patterns but got %v", summary["Errors"])
+			}
+		})
+	}
+}
+
+func TestLogPatternsAdd(t *testing.T) {
+	patterns := GetDefaultLogPatterns()
+	if err := patterns.Add(config.LogPatternSet{Name: "Errors", Lines: []string{"panic"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 2 || len(patterns["Errors"].MatchPatterns) != 1 || patterns["Errors"].FilePathPatterns[0].String() != logFilePatternPodlogsString {
+		t.Errorf("Expected the Errors set to be replaced but got %+v", patterns["Errors"])
+	}
+
+	err := patterns.Add(config.LogPatternSet{Name: "Bad", Lines: []string{"("}})
+	if err == nil || !strings.Contains(err.Error(), "Bad") {
+		t.Errorf("Expected an error naming the set but got %v", err)
+	}
+	if _, ok := patterns["Bad"]; ok {
+		t.Error("Expected invalid set not to be added")
+	}
+}
+
+func TestTruncateLogSample(t *testing.T) {
+	long := strings.Repeat("a", maxLogSampleLength+10)
+	if got := truncateLogSample(long); got != long[:maxLogSampleLength]+"..." {
+		t.Errorf("Expected line to be truncated but got %d bytes", len(got))
+	}
+	if got := truncateLogSample("short"); got != "short" {
+		t.Errorf("Expected short line to be unchanged but got %q", got)
+	}
+}
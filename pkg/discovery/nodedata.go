@@ -0,0 +1,181 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"github.com/vmware-tanzu/sonobuoy/pkg/redact"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// StatsSummaryFile is the filename of the kubelet /stats/summary response, relative to the node's
+	// directory under HostsLocation.
+	StatsSummaryFile = "stats_summary.json"
+
+	// nodeDataTimeout is how long to wait for each kubelet endpoint.
+	nodeDataTimeout = 30 * time.Second
+)
+
+// kubeletEndpoint is a kubelet endpoint which is saved for each node when its keyword is in Resources.
+type kubeletEndpoint struct {
+	keyword string
+	path    string
+	file    string
+
+	// explicit endpoints are only saved when their keyword is in Resources, not when it is nil.
+	explicit bool
+}
+
+var kubeletEndpoints = []kubeletEndpoint{
+	// The full metrics can be very large on busy nodes and the pods include the env of every
+	// container, which may hold secrets, so they aren't gathered by default.
+	{keyword: "nodemetrics", path: "metrics", file: "metrics.txt", explicit: true},
+	{keyword: "noderesourcemetrics", path: "metrics/resource", file: "metrics_resource.txt"},
+	{keyword: "nodestats", path: "stats/summary", file: StatsSummaryFile},
+	{keyword: "nodepods", path: "pods", file: "pods.json", explicit: true},
+}
+
+// enabledKubeletEndpoints returns the kubelet endpoints enabled by the Resources in the config. If
+// Resources is nil, all of them are enabled except the explicit ones.
+func enabledKubeletEndpoints(cfg *config.Config) []kubeletEndpoint {
+	var out []kubeletEndpoint
+	for _, e := range kubeletEndpoints {
+		switch {
+		case cfg.Resources != nil && !sliceContains(cfg.Resources, e.keyword):
+		case cfg.Resources == nil && e.explicit:
+			logrus.Infof("Resources is not set explicitly implying query all resources, but skipping %v by default. Specify the value explicitly in Resources to gather this data.", e.keyword)
+		default:
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// gatherKubeletData saves the given kubelet endpoints of each node, proxied through the API server.
+// Each response is truncated to the configured limit since /metrics in particular can be very large
+// on busy nodes. The requests are made by the same bounded number of workers as resource queries so
+// that slow or unreachable kubelets don't hold up the others; each is recorded separately so that
+// they are visible in the query times.
+func gatherKubeletData(nodeNames []string, restclient rest.Interface, recorder *QueryRecorder, endpoints []kubeletEndpoint, cfg *config.Config) {
+	type nodeQuery struct {
+		node     string
+		endpoint kubeletEndpoint
+	}
+	queries := make([]nodeQuery, 0, len(nodeNames)*len(endpoints))
+	for _, name := range nodeNames {
+		for _, e := range endpoints {
+			queries = append(queries, nodeQuery{node: name, endpoint: e})
+		}
+	}
+
+	maxBytes := cfg.Limits.NodeDataMaxBytes()
+	work := make(chan nodeQuery)
+	var wg sync.WaitGroup
+	workers := cfg.ResourceQueryConcurrency()
+	if workers > len(queries) {
+		workers = len(queries)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for q := range work {
+				file := filepath.Join(cfg.QueryOutputDir(), HostsLocation, q.node, q.endpoint.file)
+				start := time.Now()
+				n, truncated, err := saveNodeEndpoint(restclient, q.node, q.endpoint.path, maxBytes, file)
+				if err == nil && strings.HasSuffix(q.endpoint.file, ".json") && recorder.redactor.HasFieldRules() {
+					err = redactJSONFile(file, recorder.redactor)
+				}
+				recorder.RecordSizedQuery(q.node+"/"+q.endpoint.path, "", time.Since(start), n, truncated, err)
+			}
+		}()
+	}
+	for _, q := range queries {
+		work <- q
+	}
+	close(work)
+	wg.Wait()
+}
+
+// redactJSONFile applies the field redaction rules to the file. If it cannot be parsed (e.g. it was
+// truncated) it is removed rather than being left unredacted.
+func redactJSONFile(file string, redactor *redact.Redactor) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	redacted, err := redactor.JSON(b, file)
+	if err != nil {
+		os.Remove(file)
+		return errors.Wrap(err, "removed file which could not be redacted")
+	}
+	return errors.WithStack(os.WriteFile(file, redacted, 0644))
+}
+
+// saveNodeEndpoint writes up to maxBytes of the response from the node endpoint to the file. It
+// returns the number of bytes written and whether the response was truncated.
+func saveNodeEndpoint(client rest.Interface, nodeName, endpoint string, maxBytes int64, file string) (int64, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), nodeDataTimeout)
+	defer cancel()
+	body, err := client.
+		Get().
+		Resource("nodes").
+		Name(nodeName).
+		SubResource("proxy").
+		Suffix(endpoint).
+		Stream(ctx)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "getting %v endpoint for node %v", endpoint, nodeName)
+	}
+	defer body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	n, err := io.Copy(f, io.LimitReader(body, maxBytes))
+	if err != nil {
+		return n, false, errors.Wrapf(err, "reading %v endpoint for node %v", endpoint, nodeName)
+	}
+
+	// Check for any remaining data to know if the response was cut off.
+	truncated := false
+	if n == maxBytes {
+		var extra [1]byte
+		if m, _ := io.ReadFull(body, extra[:]); m > 0 {
+			truncated = true
+			logrus.Warningf("Response from %v endpoint for node %v truncated to %v bytes", endpoint, nodeName, maxBytes)
+		}
+	}
+	return n, truncated, nil
+}
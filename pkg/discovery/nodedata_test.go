@@ -0,0 +1,164 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestEnabledKubeletEndpoints(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		resources []string
+		expect    []string
+	}{
+		{
+			desc:   "Nil resources skip the explicit endpoints",
+			expect: []string{"noderesourcemetrics", "nodestats"},
+		}, {
+			desc:      "Explicit endpoints are enabled when listed",
+			resources: []string{"nodepods", "nodemetrics", "pods"},
+			expect:    []string{"nodemetrics", "nodepods"},
+		}, {
+			desc:      "Empty resources enable nothing",
+			resources: []string{},
+			expect:    []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := config.New()
+			cfg.Resources = tc.resources
+			got := []string{}
+			for _, e := range enabledKubeletEndpoints(cfg) {
+				got = append(got, e.keyword)
+			}
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("Expected endpoints %v but got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestGatherKubeletData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/nodes/n1/proxy/metrics":
+			w.Write([]byte(strings.Repeat("m", 100)))
+		case "/api/v1/nodes/n1/proxy/stats/summary":
+			w.Write([]byte(`{"node":{}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		desc         string
+		resources    []string
+		maxBytes     int64
+		expectFiles  map[string]int
+		expectErrors int
+		expectTrunc  bool
+	}{
+		{
+			desc:        "Only enabled endpoints",
+			resources:   []string{"nodestats"},
+			expectFiles: map[string]int{StatsSummaryFile: 11},
+		}, {
+			desc:        "Responses are truncated",
+			resources:   []string{"nodemetrics"},
+			maxBytes:    10,
+			expectFiles: map[string]int{"metrics.txt": 10},
+			expectTrunc: true,
+		}, {
+			desc:         "Response exactly at the limit is not truncated",
+			resources:    []string{"nodemetrics", "nodepods"},
+			maxBytes:     100,
+			expectFiles:  map[string]int{"metrics.txt": 100},
+			expectErrors: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := config.New()
+			cfg.QueryDir = t.TempDir()
+			cfg.Resources = tc.resources
+			if tc.maxBytes > 0 {
+				cfg.Limits.NodeData = &config.NodeDataLimits{MaxBytes: tc.maxBytes}
+			}
+			recorder := NewQueryRecorder()
+
+			gatherKubeletData([]string{"n1"}, client.CoreV1().RESTClient(), recorder, enabledKubeletEndpoints(cfg), cfg)
+
+			for file, size := range tc.expectFiles {
+				info, err := os.Stat(filepath.Join(cfg.QueryOutputDir(), HostsLocation, "n1", file))
+				if err != nil {
+					t.Fatalf("Expected file %v: %v", file, err)
+				}
+				if info.Size() != int64(size) {
+					t.Errorf("Expected %v to be %v bytes but got %v", file, size, info.Size())
+				}
+			}
+
+			if len(recorder.queries) != len(tc.resources) {
+				t.Fatalf("Expected %v queries recorded but got %v", len(tc.resources), len(recorder.queries))
+			}
+			errs := 0
+			for _, q := range recorder.queries {
+				if q.Error != nil {
+					errs++
+					continue
+				}
+				if q.Truncated != tc.expectTrunc {
+					t.Errorf("Expected truncated %v for %v but got %v", tc.expectTrunc, q.QueryObj, q.Truncated)
+				}
+				if q.Bytes != int64(tc.expectFiles[fileForQuery(q.QueryObj)]) {
+					t.Errorf("Expected %v bytes recorded for %v but got %v", tc.expectFiles[fileForQuery(q.QueryObj)], q.QueryObj, q.Bytes)
+				}
+			}
+			if errs != tc.expectErrors {
+				t.Errorf("Expected %v errors but got %v", tc.expectErrors, errs)
+			}
+		})
+	}
+}
+
+// fileForQuery returns the file the kubelet endpoint query was saved to.
+func fileForQuery(query string) string {
+	for _, e := range kubeletEndpoints {
+		if strings.HasSuffix(query, "/"+e.path) {
+			return e.file
+		}
+	}
+	return ""
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package discovery
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -24,6 +25,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -31,21 +33,40 @@ import (
 	"github.com/vmware-tanzu/sonobuoy/pkg/config"
 	"github.com/vmware-tanzu/sonobuoy/pkg/dynamic"
 	"github.com/vmware-tanzu/sonobuoy/pkg/errlog"
+	"github.com/vmware-tanzu/sonobuoy/pkg/redact"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
 )
 
 // Query cluster runs multiple queries against the cluster in order to obtain debug
 // information.
 func QueryCluster(restConf *rest.Config, cfg *config.Config) error {
+	return queryCluster(restConf, cfg, time.Time{}, NewQueryRecorder())
+}
+
+// queryCluster runs the cluster queries, only gathering the events seen after runStart. Each query
+// is recorded by the recorder.
+func queryCluster(restConf *rest.Config, cfg *config.Config, runStart time.Time, recorder *QueryRecorder) error {
 	// Adjust QPS/Burst so that the queries execute as quickly as possible.
 	restConf.QPS = float32(cfg.QPS)
 	restConf.Burst = cfg.Burst
+	if cfg.QPS > 0 {
+		// Share a single limiter between the clients so that concurrent queries respect the overall limits.
+		restConf.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(restConf.QPS, restConf.Burst)
+	}
+
+	// Fail early rather than risk writing data which was meant to be redacted.
+	redactor, err := redact.New(cfg.Redaction)
+	if err != nil {
+		return errors.Wrap(err, "invalid redaction rules")
+	}
 
 	apiHelper, err := dynamic.NewAPIHelperFromRESTConfig(restConf)
 	if err != nil {
@@ -68,39 +89,68 @@ func QueryCluster(restConf *rest.Config, cfg *config.Config) error {
 	}
 
 	// Run the queries
-	recorder := NewQueryRecorder()
+	recorder.redactor = redactor
 	clusterResources, nsResources, err := getAllFilteredResources(apiHelper, cfg.Resources)
 	if err != nil {
 		return errors.Wrap(err, "unable to filter resources")
 	}
+	nsResources = withoutEventResources(nsResources)
 
-	if err := QueryHostData(kubeClient, recorder, cfg); err != nil {
-		logrus.Errorf("Failed to query host data: %v", err)
-	}
+	if cfg.NamespaceScoped {
+		// Record the skipped queries so it is clear from the results why the data is missing.
+		recorder.RecordSkip("Nodes", "", namespaceScopedSkipReason)
+		recorder.RecordSkip("ClusterResources", "", namespaceScopedSkipReason)
+	} else {
+		if err := QueryHostData(kubeClient, recorder, cfg); err != nil {
+			logrus.Errorf("Failed to query host data: %v", err)
+		}
 
-	if err := QueryResources(apiHelper, recorder, clusterResources, nil, cfg); err != nil {
-		logrus.Errorf("Failed to query cluster resources: %v", err)
+		if err := QueryResources(apiHelper, recorder, clusterResources, nil, cfg); err != nil {
+			logrus.Errorf("Failed to query cluster resources: %v", err)
+		}
 	}
 
 	if err := QueryServerData(kubeClient, recorder, cfg); err != nil {
 		logrus.Errorf("Failed to query server data: %v", err)
 	}
 
-	// Get the list of namespaces and apply the regex filter on the namespace
-	logrus.Infof("Filtering namespaces based on the following regex:%s", cfg.Filters.Namespaces)
-	nslist, err := FilterNamespaces(kubeClient, cfg.Filters.Namespaces, cfg.Namespace)
-	if err != nil {
-		logrus.Errorf("could not filter namespaces, will not query every namespace: %v", err)
+	eventsNS := ""
+	if cfg.NamespaceScoped {
+		eventsNS = cfg.Namespace
+	}
+	if err := QueryEvents(kubeClient, recorder, eventsNS, runStart, cfg); err != nil {
+		logrus.Errorf("Failed to query events: %v", err)
 	}
 
-	for _, ns := range nslist {
-		if err := QueryResources(apiHelper, recorder, nsResources, &ns, cfg); err != nil {
-			logrus.Errorf("Failed to query resources for namespace %q: %v", ns, err)
+	// Get the list of namespaces and apply the regex filter on the namespace
+	var nslist []string
+	if cfg.NamespaceScoped {
+		logrus.Infof("Namespace-scoped; only querying namespace %v", cfg.Namespace)
+		recorder.RecordSkip("Namespaces", "", namespaceScopedSkipReason)
+		nslist = []string{cfg.Namespace}
+	} else {
+		logrus.Infof("Filtering namespaces based on the following regex:%s", cfg.Filters.Namespaces)
+		nslist, err = FilterNamespaces(kubeClient, cfg.Filters.Namespaces, cfg.Namespace)
+		if err != nil {
+			logrus.Errorf("could not filter namespaces, will not query every namespace: %v", err)
 		}
 	}
 
+	if err := QueryNamespacedResources(apiHelper, recorder, nsResources, nslist, cfg); err != nil {
+		logrus.Errorf("Failed to query namespaced resources: %v", err)
+	}
+
 	// Query pod logs
-	if cfg.Resources == nil || sliceContains(cfg.Resources, "podlogs") {
+	switch {
+	case (cfg.Resources == nil || sliceContains(cfg.Resources, "podlogs")) && cfg.NamespaceScoped:
+		if err := QueryPodLogs(kubeClient, recorder, cfg.Namespace, cfg, map[string]struct{}{}); err != nil {
+			logrus.Errorf("Failed to query pod logs for namespace %q: %v", cfg.Namespace, err)
+		}
+		// Only the cluster-wide query by field selectors is skipped.
+		if len(cfg.Limits.PodLogs.FieldSelectors) > 0 {
+			recorder.RecordSkip("PodLogs", "", namespaceScopedSkipReason)
+		}
+	case cfg.Resources == nil || sliceContains(cfg.Resources, "podlogs"):
 		// Eliminate duplicate pods when query by namespaces and query by fieldSelectors
 		visitedPods := make(map[string]struct{})
 
@@ -126,7 +176,7 @@ func QueryCluster(restConf *rest.Config, cfg *config.Config) error {
 		if err := QueryPodLogs(kubeClient, recorder, "", cfg, visitedPods); err != nil {
 			logrus.Errorf("Failed to query pod logs: %v", err)
 		}
-	} else {
+	default:
 		logrus.Infof("podlogs not specified in non-nil Resources, skipping getting podlogs")
 	}
 
@@ -136,6 +186,13 @@ func QueryCluster(restConf *rest.Config, cfg *config.Config) error {
 		logrus.Errorf("Failed to write query times: %v", err)
 	}
 
+	if redactor != nil {
+		logrus.Infof("recording redactions at %v", filepath.Join(metapath, redact.RecordsFile))
+		if err := redactor.DumpRecords(filepath.Join(metapath, redact.RecordsFile), outpath); err != nil {
+			logrus.Errorf("Failed to write redactions: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -168,34 +225,21 @@ const (
 	NSResourceLocation = "resources/ns"
 	// ClusterResourceLocation is the place under which non-namespaced API resources (nodes, etc) are stored
 	ClusterResourceLocation = "resources/cluster"
-	// HostsLocation is the place under which host information (configz, healthz) is stored
+	// HostsLocation is the place under which host information (configz, healthz, kubelet metrics, etc) is stored
 	HostsLocation = "hosts"
 	// listVerb is the API verb we ensure resources respond to in order to try and call List()
 	listVerb = "list"
+	// namespaceScopedSkipReason is recorded for queries skipped because they need cluster-scoped permissions.
+	namespaceScopedSkipReason = "skipped: requires cluster-scoped permissions which namespace-scoped runs do not have"
 	// secretResourceName is the value of the Name field on Secrets. We will implicitly filter those if the user
 	// tries to just query everything by not specifying a Resource list.
 	secretResourceName = "secrets"
 )
 
-type listQuery func() (*unstructured.UnstructuredList, error)
+type listQuery func(opts metav1.ListOptions) (*unstructured.UnstructuredList, error)
 type objQuery func() (interface{}, error)
 
-// timedListQuery performs a list query and serialize the results
-func timedListQuery(outpath string, file string, f listQuery) (time.Duration, error) {
-	start := time.Now()
-	list, err := f()
-	duration := time.Since(start)
-	if err != nil {
-		return duration, err
-	}
-
-	if len(list.Items) > 0 {
-		err = errors.WithStack(SerializeObj(list, outpath, file))
-	}
-	return duration, err
-}
-
-func timedObjectQuery(outpath string, file string, f objQuery) (time.Duration, error) {
+func timedObjectQuery(outpath string, file string, redactor *redact.Redactor, f objQuery) (time.Duration, error) {
 	start := time.Now()
 	obj, err := f()
 	duration := time.Since(start)
@@ -203,7 +247,7 @@ func timedObjectQuery(outpath string, file string, f objQuery) (time.Duration, e
 		return duration, err
 	}
 
-	return duration, errors.WithStack(SerializeObj(obj, outpath, file))
+	return duration, errors.WithStack(SerializeObjWithRedactor(obj, outpath, file, redactor))
 }
 
 // timedQuery Wraps the execution of the function with a recorded timed snapshot
@@ -271,22 +315,62 @@ func QueryResources(
 
 	if ns != nil {
 		logrus.Infof("Running ns query (%v)", *ns)
-	} else {
-		logrus.Info("Running cluster queries")
+		return QueryNamespacedResources(client, recorder, resources, []string{*ns}, cfg)
 	}
 
-	// Create the parent directory we will use to store the results
+	logrus.Info("Running cluster queries")
 	outdir := filepath.Join(cfg.QueryOutputDir(), ClusterResourceLocation)
-	if ns != nil {
-		outdir = filepath.Join(cfg.QueryOutputDir(), NSResourceLocation, *ns)
-	}
-
 	if err := os.MkdirAll(outdir, 0755); err != nil {
 		return errors.WithStack(err)
 	}
+	queries := make([]resourceQuery, 0, len(resources))
+	for _, gvr := range resources {
+		queries = append(queries, resourceQuery{gvr: gvr, outdir: outdir})
+	}
+	runResourceQueries(client, recorder, queries, cfg)
+	return nil
+}
+
+// QueryNamespacedResources queries the namespaced resources in each of the namespaces, writing them out
+// to <outputDir>/resources/ns/<ns>/*.json. The queries for all the namespaces are run together so that
+// clusters with many namespaces are not limited by a few slow ones.
+func QueryNamespacedResources(
+	client *dynamic.APIHelper,
+	recorder *QueryRecorder,
+	resources []schema.GroupVersionResource,
+	namespaces []string,
+	cfg *config.Config) error {
+
+	if len(resources) == 0 {
+		return nil
+	}
+
+	queries := make([]resourceQuery, 0, len(resources)*len(namespaces))
+	for _, ns := range namespaces {
+		outdir := filepath.Join(cfg.QueryOutputDir(), NSResourceLocation, ns)
+		if err := os.MkdirAll(outdir, 0755); err != nil {
+			return errors.WithStack(err)
+		}
+		for _, gvr := range resources {
+			queries = append(queries, resourceQuery{gvr: gvr, ns: ns, outdir: outdir})
+		}
+	}
+	runResourceQueries(client, recorder, queries, cfg)
+	return nil
+}
 
+// resourceQuery is a list of a single resource, in a single namespace if ns is set.
+type resourceQuery struct {
+	gvr    schema.GroupVersionResource
+	ns     string
+	outdir string
+}
+
+// runResourceQueries runs the queries with a bounded number of workers. Each list is paged to bound
+// the memory used, and the time and number of pages for each is recorded.
+func runResourceQueries(client *dynamic.APIHelper, recorder *QueryRecorder, queries []resourceQuery, cfg *config.Config) {
 	// Setup label filter if there is one.
-	opts := metav1.ListOptions{}
+	opts := metav1.ListOptions{Limit: cfg.ResourceQueryPageSize()}
 	if len(cfg.Filters.LabelSelector) > 0 {
 		if _, err := labels.Parse(cfg.Filters.LabelSelector); err != nil {
 			logrus.Warningf("Labelselector %v failed to parse with error %v", cfg.Filters.LabelSelector, err)
@@ -295,47 +379,182 @@ func QueryResources(
 		}
 	}
 
-	// Execute the query
-	for _, gvr := range resources {
-		lister := func() (*unstructured.UnstructuredList, error) {
-			resourceClient := client.Client.Resource(gvr)
-			if ns != nil && len(*ns) > 0 {
-				// Use a namespaced query rather than using a metadata.namespace filter to
-				// avoid permissions issues.
-				objs, err := resourceClient.Namespace(*ns).List(context.TODO(), opts)
-				return objs, errors.Wrapf(err, "listing resource %v", gvr)
-			} else {
-				objs, err := resourceClient.List(context.TODO(), opts)
-				return objs, errors.Wrapf(err, "listing resource %v", gvr)
+	work := make(chan resourceQuery)
+	var wg sync.WaitGroup
+	workers := cfg.ResourceQueryConcurrency()
+	if workers > len(queries) {
+		workers = len(queries)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for q := range work {
+				runResourceQuery(client, recorder, q, opts)
 			}
+		}()
+	}
+	for _, q := range queries {
+		work <- q
+	}
+	close(work)
+	wg.Wait()
+}
+
+func runResourceQuery(client *dynamic.APIHelper, recorder *QueryRecorder, q resourceQuery, opts metav1.ListOptions) {
+	resourceClient := client.Client.Resource(q.gvr)
+	lister := func(opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+		if len(q.ns) > 0 {
+			// Use a namespaced query rather than using a metadata.namespace filter to
+			// avoid permissions issues.
+			objs, err := resourceClient.Namespace(q.ns).List(context.TODO(), opts)
+			return objs, errors.Wrapf(err, "listing resource %v", q.gvr)
 		}
+		objs, err := resourceClient.List(context.TODO(), opts)
+		return objs, errors.Wrapf(err, "listing resource %v", q.gvr)
+	}
+
+	// The core group is just the empty string but for clarity and consistency, refer to it as core.
+	groupText := q.gvr.Group
+	if groupText == "" {
+		groupText = "core"
+	}
 
-		// The core group is just the empty string but for clarity and consistency, refer to it as core.
-		groupText := gvr.Group
-		if groupText == "" {
-			groupText = "core"
+	start := time.Now()
+	pages, err := pagedListQuery(q.outdir, groupText+"_"+q.gvr.Version+"_"+q.gvr.Resource+".json", opts, recorder.redactor, lister)
+	recorder.RecordPagedQuery(q.gvr.Resource, q.gvr.GroupVersion().String(), q.ns, time.Since(start), pages, err)
+}
+
+// pagedListQuery lists the resource a page at a time, writing each page to the file as it is received
+// so that large lists are never held in memory all at once. The file is only written if there are items.
+// It returns the number of pages requested.
+func pagedListQuery(outpath string, file string, opts metav1.ListOptions, redactor *redact.Redactor, f listQuery) (int, error) {
+	var w *listWriter
+	pages := 0
+	for {
+		list, err := f(opts)
+		if err != nil {
+			if w != nil {
+				w.abort()
+			}
+			return pages, err
 		}
+		pages++
 
-		query := func() (time.Duration, error) {
-			return timedListQuery(
-				outdir,
-				groupText+"_"+gvr.Version+"_"+gvr.Resource+".json",
-				lister,
-			)
+		if len(list.Items) > 0 {
+			if w == nil {
+				w, err = newListWriter(filepath.Join(outpath, file), list, redactor)
+				if err != nil {
+					return pages, err
+				}
+			}
+			if err := w.write(list.Items); err != nil {
+				w.abort()
+				return pages, err
+			}
 		}
 
-		// Get the pretty-print namespace and avoid dereference issues.
-		nsVal := ""
-		if ns != nil {
-			nsVal = *ns
+		if len(list.GetContinue()) == 0 {
+			break
 		}
+		opts.Continue = list.GetContinue()
+	}
 
-		timedQuery(recorder, gvr.Resource, nsVal, query)
+	if w == nil {
+		return pages, nil
 	}
+	return pages, w.close()
+}
 
+// listWriter incrementally writes a list in the same form as UnstructuredList.MarshalJSON.
+type listWriter struct {
+	f        *os.File
+	w        *bufio.Writer
+	fields   map[string]interface{}
+	items    int
+	redactor *redact.Redactor
+}
+
+// newListWriter creates the file and writes the start of the list, using the kind and version of the
+// first page.
+func newListWriter(file string, first *unstructured.UnstructuredList, redactor *redact.Redactor) (*listWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The items are written as they arrive and the paging fields are not useful in the results.
+	fields := runtime.DeepCopyJSON(first.Object)
+	delete(fields, "items")
+	unstructured.RemoveNestedField(fields, "metadata", "continue")
+	unstructured.RemoveNestedField(fields, "metadata", "remainingItemCount")
+	lw := &listWriter{f: f, w: bufio.NewWriter(f), fields: fields, redactor: redactor}
+	if _, err := lw.w.WriteString(`{"items":[`); err != nil {
+		lw.abort()
+		return nil, errors.WithStack(err)
+	}
+	return lw, nil
+}
+
+func (lw *listWriter) write(items []unstructured.Unstructured) error {
+	for _, item := range items {
+		item.SetManagedFields(nil)
+		lw.redactor.Object(item.Object, lw.f.Name())
+		b, err := item.MarshalJSON()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if lw.items > 0 {
+			if err := lw.w.WriteByte(','); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if _, err := lw.w.Write(b); err != nil {
+			return errors.WithStack(err)
+		}
+		lw.items++
+	}
 	return nil
 }
 
+// close writes the remaining list fields (apiVersion, kind, metadata) and closes the file.
+func (lw *listWriter) close() error {
+	b, err := json.Marshal(lw.fields)
+	if err != nil {
+		lw.abort()
+		return errors.WithStack(err)
+	}
+	if _, err := lw.w.WriteString("]"); err != nil {
+		lw.abort()
+		return errors.WithStack(err)
+	}
+	// Splice the remaining fields into the object after the items.
+	if len(b) > 2 {
+		if _, err := lw.w.WriteString("," + string(b[1:len(b)-1])); err != nil {
+			lw.abort()
+			return errors.WithStack(err)
+		}
+	}
+	if _, err := lw.w.WriteString("}"); err != nil {
+		lw.abort()
+		return errors.WithStack(err)
+	}
+	if err := lw.w.Flush(); err != nil {
+		lw.abort()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(lw.f.Close())
+}
+
+// abort closes and removes the partially written file.
+func (lw *listWriter) abort() {
+	lw.f.Close()
+	os.Remove(lw.f.Name())
+}
+
 // getAllFilteredResources figure out which resources we want to query for based on the filter list and whether
 // or not we are considering namespaced objects or not.
 func getAllFilteredResources(client *dynamic.APIHelper, wantResources []string) (clusterResources, nsResources []schema.GroupVersionResource, retErr error) {
@@ -409,16 +628,18 @@ func QueryPodLogs(kubeClient kubernetes.Interface, recorder *QueryRecorder, ns s
 
 	if len(ns) > 0 {
 		logrus.Infof("Collecting Pod Logs by namespace (%v)", ns)
-		err := gatherPodLogs(kubeClient, ns, opts, cfg, visitedPods)
+		err := gatherPodLogs(kubeClient, ns, opts, cfg, visitedPods, recorder.redactor)
 		if err != nil {
+			recorder.RecordQuery("PodLogs", ns, time.Since(start), err)
 			return err
 		}
 	} else {
 		logrus.Infof("Collecting Pod Logs by FieldSelectors: %q", cfg.Limits.PodLogs.FieldSelectors)
 		for _, fieldSelector := range cfg.Limits.PodLogs.FieldSelectors {
 			opts.FieldSelector = fieldSelector
-			err := gatherPodLogs(kubeClient, ns, opts, cfg, visitedPods)
+			err := gatherPodLogs(kubeClient, ns, opts, cfg, visitedPods, recorder.redactor)
 			if err != nil {
+				recorder.RecordQuery("PodLogs", ns, time.Since(start), err)
 				return err
 			}
 		}
@@ -429,9 +650,12 @@ func QueryPodLogs(kubeClient kubernetes.Interface, recorder *QueryRecorder, ns s
 	return nil
 }
 
-// QueryHostData gets the host data and records it.
+// QueryHostData gets the host data and records it. The configz and healthz endpoints are gathered if
+// nodes is in Resources and the other kubelet endpoints if their own keyword is.
 func QueryHostData(kubeClient kubernetes.Interface, recorder *QueryRecorder, cfg *config.Config) error {
-	if cfg.Resources != nil && !sliceContains(cfg.Resources, "nodes") {
+	hostData := cfg.Resources == nil || sliceContains(cfg.Resources, "nodes")
+	endpoints := enabledKubeletEndpoints(cfg)
+	if !hostData && len(endpoints) == 0 {
 		logrus.Info("nodes not specified in non-nil Resources. Skipping host data gathering.")
 		return nil
 	}
@@ -446,9 +670,12 @@ func QueryHostData(kubeClient kubernetes.Interface, recorder *QueryRecorder, cfg
 	for i, node := range nodeList.Items {
 		nodeNames[i] = node.Name
 	}
-	err = gatherNodeData(nodeNames, kubeClient.CoreV1().RESTClient(), cfg)
-	duration := time.Since(start)
-	recorder.RecordQuery("Nodes", "", duration, err)
+	if hostData {
+		err = gatherNodeData(nodeNames, kubeClient.CoreV1().RESTClient(), cfg, recorder.redactor)
+		duration := time.Since(start)
+		recorder.RecordQuery("Nodes", "", duration, err)
+	}
+	gatherKubeletData(nodeNames, kubeClient.CoreV1().RESTClient(), recorder, endpoints, cfg)
 
 	return nil
 }
@@ -475,7 +702,7 @@ func queryServerVersion(kubeClient kubernetes.Interface, recorder *QueryRecorder
 
 	objqry := func() (interface{}, error) { return kubeClient.Discovery().ServerVersion() }
 	query := func() (time.Duration, error) {
-		return timedObjectQuery(cfg.QueryOutputDir(), "serverversion.json", objqry)
+		return timedObjectQuery(cfg.QueryOutputDir(), "serverversion.json", recorder.redactor, objqry)
 	}
 	timedQuery(recorder, "serverversion", "", query)
 
@@ -489,16 +716,20 @@ func queryServerGroups(kubeClient kubernetes.Interface, recorder *QueryRecorder,
 	}
 	objqry := func() (interface{}, error) { return kubeClient.Discovery().ServerGroups() }
 	query := func() (time.Duration, error) {
-		return timedObjectQuery(cfg.QueryOutputDir(), "servergroups.json", objqry)
+		return timedObjectQuery(cfg.QueryOutputDir(), "servergroups.json", recorder.redactor, objqry)
 	}
 	timedQuery(recorder, "servergroups", "", query)
 
 	return nil
 }
 
-// QueryRecorder records a sequence of queries
+// QueryRecorder records a sequence of queries. It is safe for concurrent use.
 type QueryRecorder struct {
+	mu      sync.Mutex
 	queries []*QueryData
+
+	// redactor is applied to the query results before they are written. Nothing is redacted if nil.
+	redactor *redact.Redactor
 }
 
 // NewQueryRecorder returns a new empty QueryRecorder
@@ -514,6 +745,19 @@ type QueryData struct {
 	Namespace   string `json:"namespace,omitempty"`
 	ElapsedTime string `json:"time,omitempty"`
 	Error       error  `json:"error,omitempty"`
+	SkipReason  string `json:"skipReason,omitempty"`
+
+	// GroupVersion is the API group and version of resource queries.
+	GroupVersion string `json:"groupVersion,omitempty"`
+
+	// Pages is the number of list calls made for paged queries.
+	Pages int `json:"pages,omitempty"`
+
+	// Bytes is the size of the saved response for queries which limit it.
+	Bytes int64 `json:"bytes,omitempty"`
+
+	// Truncated is set if the response was larger than the limit and was cut off.
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // RecordQuery transcribes a query by name, namespace, duration and error
@@ -521,20 +765,66 @@ func (q *QueryRecorder) RecordQuery(name string, namespace string, duration time
 	if recerr != nil {
 		errlog.LogError(errors.Wrapf(recerr, "error querying %v", name))
 	}
-	summary := &QueryData{
+	q.record(&QueryData{
 		QueryObj:    name,
 		Namespace:   namespace,
 		ElapsedTime: duration.String(),
 		Error:       recerr,
+	})
+}
+
+// RecordPagedQuery records a resource query along with its group/version and the number of pages listed
+func (q *QueryRecorder) RecordPagedQuery(name string, groupVersion string, namespace string, duration time.Duration, pages int, recerr error) {
+	if recerr != nil {
+		errlog.LogError(errors.Wrapf(recerr, "error querying %v", name))
 	}
+	q.record(&QueryData{
+		QueryObj:     name,
+		GroupVersion: groupVersion,
+		Namespace:    namespace,
+		ElapsedTime:  duration.String(),
+		Error:        recerr,
+		Pages:        pages,
+	})
+}
 
-	q.queries = append(q.queries, summary)
+// RecordSizedQuery records a query along with the size of its saved response and whether it was truncated
+func (q *QueryRecorder) RecordSizedQuery(name string, namespace string, duration time.Duration, bytes int64, truncated bool, recerr error) {
+	if recerr != nil {
+		errlog.LogError(errors.Wrapf(recerr, "error querying %v", name))
+	}
+	q.record(&QueryData{
+		QueryObj:    name,
+		Namespace:   namespace,
+		ElapsedTime: duration.String(),
+		Error:       recerr,
+		Bytes:       bytes,
+		Truncated:   truncated,
+	})
+}
+
+// RecordSkip records that a query was intentionally not run and why
+func (q *QueryRecorder) RecordSkip(name string, namespace string, reason string) {
+	logrus.Infof("Not querying %v: %v", name, reason)
+	q.record(&QueryData{
+		QueryObj:   name,
+		Namespace:  namespace,
+		SkipReason: reason,
+	})
+}
+
+func (q *QueryRecorder) record(data *QueryData) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queries = append(q.queries, data)
 }
 
 // DumpQueryData writes query information out to a file at the give filepath
 func (q *QueryRecorder) DumpQueryData(filepath string) error {
 	// Format the query data as JSON
+	q.mu.Lock()
 	data, err := json.Marshal(q.queries)
+	q.mu.Unlock()
 	if err != nil {
 		return err
 	}
@@ -574,7 +864,7 @@ func getPodLogOptions(cfg *config.Config) *v1.PodLogOptions {
 // visitedPods will eliminate duplicate pods when execute overlapping queries,
 // e.g. query by namespaces and query by fieldSelectors
 func gatherPodLogs(kubeClient kubernetes.Interface, ns string, opts metav1.ListOptions, cfg *config.Config,
-	visitedPods map[string]struct{}) error {
+	visitedPods map[string]struct{}, redactor *redact.Redactor) error {
 
 	// 1 - Collect the list of pods
 	podlist, err := kubeClient.CoreV1().Pods(ns).List(context.TODO(), opts)
@@ -611,6 +901,7 @@ func gatherPodLogs(kubeClient kubernetes.Interface, ns string, opts metav1.ListO
 			}
 
 			outfile := path.Join(outdir, container.Name) + ".txt"
+			body = redactor.Log(body, outfile)
 			if err = os.WriteFile(outfile, body, 0644); err != nil {
 				return errors.WithStack(err)
 			}
@@ -642,7 +933,7 @@ func getNodeEndpoint(client rest.Interface, nodeName, endpoint string) (rest.Res
 // gatherNodeData collects non-resource information about a node through the
 // kubernetes API.  That is, its `healthz` and `configz` endpoints, which are
 // not "resources" per se, although they are accessible through the apiserver.
-func gatherNodeData(nodeNames []string, restclient rest.Interface, cfg *config.Config) error {
+func gatherNodeData(nodeNames []string, restclient rest.Interface, cfg *config.Config, redactor *redact.Redactor) error {
 	logrus.Info("Collecting Node Configuration and Health...")
 
 	for _, name := range nodeNames {
@@ -653,7 +944,7 @@ func gatherNodeData(nodeNames []string, restclient rest.Interface, cfg *config.C
 			return err
 		}
 
-		_, err := timedObjectQuery(out, "configz.json", func() (interface{}, error) {
+		_, err := timedObjectQuery(out, "configz.json", redactor, func() (interface{}, error) {
 			data := make(map[string]interface{})
 			result, err := getNodeEndpoint(restclient, name, "configz")
 			if err != nil {
@@ -671,7 +962,7 @@ func gatherNodeData(nodeNames []string, restclient rest.Interface, cfg *config.C
 			return err
 		}
 
-		_, err = timedObjectQuery(out, "healthz.json", func() (interface{}, error) {
+		_, err = timedObjectQuery(out, "healthz.json", redactor, func() (interface{}, error) {
 			data := make(map[string]interface{})
 			result, err := getNodeEndpoint(restclient, name, "healthz")
 			if err != nil {
@@ -706,6 +997,11 @@ func getPodLogNamespaceFilter(cfg *config.Config) string {
 
 // SerializeObj will write out an object
 func SerializeObj(obj interface{}, outpath string, file string) error {
+	return SerializeObjWithRedactor(obj, outpath, file, nil)
+}
+
+// SerializeObjWithRedactor will write out an object, applying the field redaction rules if given a redactor.
+func SerializeObjWithRedactor(obj interface{}, outpath string, file string, redactor *redact.Redactor) error {
 	var err error
 	if err = os.MkdirAll(outpath, 0755); err != nil {
 		return errors.WithStack(err)
@@ -716,10 +1012,14 @@ func SerializeObj(obj interface{}, outpath string, file string) error {
 	case *unstructured.UnstructuredList:
 		for _, v := range t.Items {
 			v.SetManagedFields(nil)
+			redactor.Object(v.Object, filepath.Join(outpath, file))
 		}
 		b, err = t.MarshalJSON()
 	default:
 		b, err = json.Marshal(obj)
+		if err == nil && redactor.HasFieldRules() {
+			b, err = redactor.JSON(b, filepath.Join(outpath, file))
+		}
 	}
 	if err != nil {
 		return errors.WithStack(err)
@@ -18,11 +18,22 @@ package discovery
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kylelemons/godebug/pretty"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"github.com/vmware-tanzu/sonobuoy/pkg/dynamic"
+	"github.com/vmware-tanzu/sonobuoy/pkg/redact"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 )
 
 func TestFilterResources(t *testing.T) {
@@ -134,3 +145,221 @@ func TestFilterResources(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryRecorderSkip(t *testing.T) {
+	q := NewQueryRecorder()
+	q.RecordQuery("pods", "default", time.Second, nil)
+	q.RecordSkip("Nodes", "", namespaceScopedSkipReason)
+
+	out := filepath.Join(t.TempDir(), "meta", "query-time.json")
+	if err := q.DumpQueryData(out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"queryobj":"pods","namespace":"default","time":"1s"},{"queryobj":"Nodes","skipReason":"` + namespaceScopedSkipReason + `"}]`
+	if string(b) != expected {
+		t.Errorf("Expected %v but got %v", expected, string(b))
+	}
+}
+
+func TestPagedListQuery(t *testing.T) {
+	page := func(cont string, names ...string) *unstructured.UnstructuredList {
+		list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "PodList"}}
+		list.SetResourceVersion("10")
+		list.SetContinue(cont)
+		for _, name := range names {
+			item := unstructured.Unstructured{}
+			item.SetAPIVersion("v1")
+			item.SetKind("Pod")
+			item.SetName(name)
+			item.SetManagedFields([]v1.ManagedFieldsEntry{{Manager: "test"}})
+			list.Items = append(list.Items, item)
+		}
+		return list
+	}
+
+	testCases := []struct {
+		desc        string
+		pages       []*unstructured.UnstructuredList
+		failOnPage  int
+		expectPages int
+		expectNames []string
+		expectErr   bool
+	}{
+		{
+			desc:        "Single page",
+			pages:       []*unstructured.UnstructuredList{page("", "a", "b")},
+			expectPages: 1,
+			expectNames: []string{"a", "b"},
+		}, {
+			desc:        "Multiple pages",
+			pages:       []*unstructured.UnstructuredList{page("1", "a"), page("2"), page("", "b", "c")},
+			expectPages: 3,
+			expectNames: []string{"a", "b", "c"},
+		}, {
+			desc:        "No items writes no file",
+			pages:       []*unstructured.UnstructuredList{page("")},
+			expectPages: 1,
+		}, {
+			desc:        "Failed page removes partial file",
+			pages:       []*unstructured.UnstructuredList{page("1", "a"), nil},
+			failOnPage:  2,
+			expectPages: 1,
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			calls := 0
+			lister := func(opts v1.ListOptions) (*unstructured.UnstructuredList, error) {
+				if opts.Limit != 2 {
+					t.Errorf("Expected limit 2 but got %v", opts.Limit)
+				}
+				if calls > 0 && opts.Continue != tc.pages[calls-1].GetContinue() {
+					t.Errorf("Expected continue %q but got %q", tc.pages[calls-1].GetContinue(), opts.Continue)
+				}
+				calls++
+				if calls == tc.failOnPage {
+					return nil, fmt.Errorf("expired")
+				}
+				return tc.pages[calls-1], nil
+			}
+
+			pages, err := pagedListQuery(dir, "core_v1_pods.json", v1.ListOptions{Limit: 2}, nil, lister)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("Expected error %v but got %v", tc.expectErr, err)
+			}
+			if pages != tc.expectPages {
+				t.Errorf("Expected %v pages but got %v", tc.expectPages, pages)
+			}
+
+			b, err := os.ReadFile(filepath.Join(dir, "core_v1_pods.json"))
+			if len(tc.expectNames) == 0 {
+				if !os.IsNotExist(err) {
+					t.Errorf("Expected no file but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := &unstructured.UnstructuredList{}
+			if err := got.UnmarshalJSON(b); err != nil {
+				t.Fatalf("Failed to decode list %s: %v", b, err)
+			}
+			var names []string
+			for _, item := range got.Items {
+				names = append(names, item.GetName())
+				if item.GetManagedFields() != nil {
+					t.Errorf("Expected managed fields to be removed from %v", item.GetName())
+				}
+			}
+			if diff := pretty.Compare(tc.expectNames, names); diff != "" {
+				t.Errorf("Unexpected items (-want +got): %v", diff)
+			}
+			if got.GetKind() != "PodList" || got.GetResourceVersion() != "10" || len(got.GetContinue()) != 0 {
+				t.Errorf("Unexpected list fields %v", got.Object)
+			}
+		})
+	}
+}
+
+func TestRunResourceQueries(t *testing.T) {
+	pods := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	obj := func(kind, ns, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind(kind)
+		u.SetNamespace(ns)
+		u.SetName(name)
+		return u
+	}
+	dynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{pods: "PodList", configMaps: "ConfigMapList"},
+		obj("Pod", "a", "p1"), obj("Pod", "b", "p2"), obj("ConfigMap", "a", "c1"),
+	)
+	helper := &dynamic.APIHelper{Client: dynClient}
+
+	var namespaces []string
+	for i := 0; i < 20; i++ {
+		namespaces = append(namespaces, fmt.Sprintf("ns%v", i))
+	}
+	namespaces = append(namespaces, "a", "b")
+
+	cfg := config.New()
+	cfg.QueryDir = t.TempDir()
+	cfg.QueryConcurrency = 4
+	recorder := NewQueryRecorder()
+	if err := QueryNamespacedResources(helper, recorder, []schema.GroupVersionResource{pods, configMaps}, namespaces, cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(recorder.queries) != 2*len(namespaces) {
+		t.Fatalf("Expected %v queries recorded but got %v", 2*len(namespaces), len(recorder.queries))
+	}
+	for _, q := range recorder.queries {
+		if q.Error != nil || q.Pages != 1 || q.GroupVersion != "v1" {
+			t.Errorf("Unexpected query data %+v", q)
+		}
+	}
+
+	for _, file := range []string{"a/core_v1_pods.json", "b/core_v1_pods.json", "a/core_v1_configmaps.json"} {
+		if _, err := os.Stat(filepath.Join(cfg.QueryOutputDir(), NSResourceLocation, file)); err != nil {
+			t.Errorf("Expected %v to be written: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cfg.QueryOutputDir(), NSResourceLocation, "b", "core_v1_configmaps.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file for empty list but got %v", err)
+	}
+}
+
+func TestSerializeObjRedaction(t *testing.T) {
+	redactor, err := redact.New(&config.RedactionConfig{
+		Fields: []config.FieldRedaction{{Kind: "ConfigMap", Paths: []string{".data"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cm := &unstructured.Unstructured{}
+	cm.SetAPIVersion("v1")
+	cm.SetKind("ConfigMap")
+	cm.SetName("c")
+	cm.Object["data"] = map[string]interface{}{"password": "hunter2"}
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMapList"}}
+	list.Items = []unstructured.Unstructured{*cm}
+
+	testCases := []struct {
+		desc string
+		obj  interface{}
+	}{
+		{desc: "Unstructured list", obj: list},
+		{desc: "Typed object", obj: &corev1.ConfigMap{
+			TypeMeta:   v1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: v1.ObjectMeta{Name: "c"},
+			Data:       map[string]string{"password": "hunter2"},
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := SerializeObjWithRedactor(tc.obj, dir, "out.json", redactor); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			b, err := os.ReadFile(filepath.Join(dir, "out.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(b), "hunter2") || !strings.Contains(string(b), redact.Mask) {
+				t.Errorf("Expected data to be redacted but got %s", b)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/query"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/rest"
+)
+
+// attachQueryRunners sets the runner on each Query plugin so that they snapshot the cluster the same
+// way as the queries run at the end of the run, but with their own filters.
+func attachQueryRunners(plugins []plugin.Interface, restConf *rest.Config, cfg *config.Config, runStart time.Time) {
+	for _, p := range plugins {
+		if q, ok := p.(*query.Plugin); ok {
+			q.Runner = queryRunner(restConf, cfg, runStart, q.GetName())
+		}
+	}
+}
+
+func queryRunner(restConf *rest.Config, cfg *config.Config, runStart time.Time, name string) query.Runner {
+	return func(spec manifest.QuerySpec, outdir string) error {
+		pluginCfg := queryPluginConfig(cfg, spec, outdir)
+
+		// Each plugin gets its own copy since the queries adjust the rate limits.
+		recorder := NewQueryRecorder()
+		if err := queryCluster(rest.CopyConfig(restConf), pluginCfg, runStart, recorder); err != nil {
+			return err
+		}
+		b, err := yaml.Marshal(recorder.ResultsItem(name))
+		if err != nil {
+			return errors.Wrap(err, "encoding query results")
+		}
+		return errors.Wrap(
+			os.WriteFile(filepath.Join(outdir, query.ResultsFile), b, 0644),
+			"saving query results",
+		)
+	}
+}
+
+// queryPluginConfig returns a copy of the config with the filters of the plugin applied.
+func queryPluginConfig(cfg *config.Config, spec manifest.QuerySpec, outdir string) *config.Config {
+	pluginCfg := *cfg
+	pluginCfg.QueryDir = outdir
+	if spec.Resources != nil {
+		pluginCfg.Resources = spec.Resources
+	}
+	if len(spec.Namespaces) > 0 {
+		pluginCfg.Filters.Namespaces = spec.Namespaces
+	}
+	if len(spec.LabelSelector) > 0 {
+		pluginCfg.Filters.LabelSelector = spec.LabelSelector
+	}
+	return &pluginCfg
+}
+
+// ResultsItem summarizes the recorded queries as a results tree: queries which failed are failures
+// and those which were skipped are skipped.
+func (q *QueryRecorder) ResultsItem(name string) results.Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item := results.Item{Name: name}
+	for _, data := range q.queries {
+		queryItem := results.Item{
+			Name:   data.QueryObj,
+			Status: results.StatusPassed,
+		}
+		if len(data.Namespace) > 0 {
+			queryItem.Name = data.Namespace + "/" + data.QueryObj
+		}
+		switch {
+		case data.Error != nil:
+			queryItem.Status = results.StatusFailed
+			queryItem.Details = map[string]interface{}{"error": data.Error.Error()}
+		case len(data.SkipReason) > 0:
+			queryItem.Status = results.StatusSkipped
+			queryItem.Details = map[string]interface{}{"reason": data.SkipReason}
+		case data.Truncated:
+			queryItem.Details = map[string]interface{}{"truncated": true}
+		}
+		item.Items = append(item.Items, queryItem)
+	}
+	item.Status = results.AggregateStatus(false, item.Items...)
+	return item
+}
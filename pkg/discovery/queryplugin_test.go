@@ -0,0 +1,66 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+)
+
+func TestQueryPluginConfig(t *testing.T) {
+	cfg := config.New()
+	cfg.Filters.LabelSelector = "a=b"
+
+	got := queryPluginConfig(cfg, manifest.QuerySpec{Resources: []string{"pods"}, Namespaces: "^kube-"}, "/tmp/out")
+	if got.QueryOutputDir() != "/tmp/out" {
+		t.Errorf("Expected output dir /tmp/out but got %v", got.QueryOutputDir())
+	}
+	if !reflect.DeepEqual(got.Resources, []string{"pods"}) || got.Filters.Namespaces != "^kube-" || got.Filters.LabelSelector != "a=b" {
+		t.Errorf("Unexpected filters %v %+v", got.Resources, got.Filters)
+	}
+
+	// The original config is untouched.
+	if cfg.Filters.Namespaces != ".*" || len(cfg.QueryDir) > 0 || reflect.DeepEqual(cfg.Resources, got.Resources) {
+		t.Errorf("Expected config to be unchanged but got %+v", cfg.Filters)
+	}
+}
+
+func TestResultsItem(t *testing.T) {
+	recorder := NewQueryRecorder()
+	recorder.RecordQuery("ServerVersion", "", time.Second, nil)
+	recorder.RecordPagedQuery("pods", "v1", "default", time.Second, 1, errors.New("forbidden"))
+	recorder.RecordSkip("Nodes", "", namespaceScopedSkipReason)
+
+	expect := results.Item{
+		Name:   "snapshot",
+		Status: results.StatusFailed,
+		Items: []results.Item{
+			{Name: "ServerVersion", Status: results.StatusPassed},
+			{Name: "default/pods", Status: results.StatusFailed, Details: map[string]interface{}{"error": "forbidden"}},
+			{Name: "Nodes", Status: results.StatusSkipped, Details: map[string]interface{}{"reason": namespaceScopedSkipReason}},
+		},
+	}
+	if got := recorder.ResultsItem("snapshot"); !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %+v but got %+v", expect, got)
+	}
+}
@@ -26,13 +26,34 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
 	v1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type ClusterSummary struct {
-	NodeHealth HealthInfo `json:"node_health" yaml:"node_health"`
-	PodHealth  HealthInfo `json:"pod_health" yaml:"pod_health"`
-	APIVersion string     `json:"api_version" yaml:"api_version"`
-	ErrorInfo  LogSummary `json:"error_summary" yaml:"error_summary"`
+	NodeHealth HealthInfo   `json:"node_health" yaml:"node_health"`
+	PodHealth  HealthInfo   `json:"pod_health" yaml:"pod_health"`
+	APIVersion string       `json:"api_version" yaml:"api_version"`
+	ErrorInfo  LogSummary   `json:"error_summary" yaml:"error_summary"`
+	LogSamples LogSamples   `json:"log_samples,omitempty" yaml:"log_samples,omitempty"`
+	Events     EventSummary `json:"event_summary" yaml:"event_summary"`
+
+	// LeakedResources are the objects created while the plugins ran which still existed afterwards.
+	LeakedResources []LeakedResource `json:"leaked_resources,omitempty" yaml:"leaked_resources,omitempty"`
+}
+
+// LeakedResource identifies an object left behind by the plugins.
+type LeakedResource struct {
+	Resource  string `json:"resource" yaml:"resource"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Name      string `json:"name" yaml:"name"`
+}
+
+// EventSummary counts the events which occurred during the run, with the warnings broken down by reason.
+type EventSummary struct {
+	Total            int            `json:"total_events" yaml:"total_events"`
+	Warnings         int            `json:"warning_events" yaml:"warning_events"`
+	WarningsByReason map[string]int `json:"warnings_by_reason,omitempty" yaml:"warnings_by_reason,omitempty"`
 }
 
 type HealthInfo struct {
@@ -48,8 +69,38 @@ type HealthInfoDetails struct {
 	Reason    string `json:"reason,omitempty" yaml:"reason,omitempty"`
 	Message   string `json:"message,omitempty" yaml:"message,omitempty"`
 	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Pressure lists the pressure conditions (e.g. MemoryPressure) which are true. Only set for nodes.
+	Pressure []string `json:"pressure,omitempty" yaml:"pressure,omitempty"`
+
+	// Resources compares the allocatable resources to those used. Only set for nodes.
+	Resources *NodeResourceUsage `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+// NodeResourceUsage compares the allocatable CPU and memory of a node to the amount used according to
+// the kubelet /stats/summary endpoint. The used values are unset if the stats were not gathered.
+type NodeResourceUsage struct {
+	CPUAllocatableMillis   int64  `json:"cpu_allocatable_millis" yaml:"cpu_allocatable_millis"`
+	CPUUsedMillis          *int64 `json:"cpu_used_millis,omitempty" yaml:"cpu_used_millis,omitempty"`
+	MemoryAllocatableBytes int64  `json:"memory_allocatable_bytes" yaml:"memory_allocatable_bytes"`
+	MemoryUsedBytes        *int64 `json:"memory_used_bytes,omitempty" yaml:"memory_used_bytes,omitempty"`
+}
+
+// nodeStatsSummary is the subset of the kubelet /stats/summary response used in the health summary.
+type nodeStatsSummary struct {
+	Node struct {
+		CPU *struct {
+			UsageNanoCores *uint64 `json:"usageNanoCores"`
+		} `json:"cpu"`
+		Memory *struct {
+			WorkingSetBytes *uint64 `json:"workingSetBytes"`
+		} `json:"memory"`
+	} `json:"node"`
 }
 
+// nodePressureConditions are the node conditions reported in the health summary when true.
+var nodePressureConditions = []v1.NodeConditionType{v1.NodeMemoryPressure, v1.NodeDiskPressure, v1.NodePIDPressure}
+
 // ReadPodHealth lists all the directories in path.Join(tarballRootDir, NSResourceLocation)
 // In each, check if it contains the file CorePodFile, if so, read each as v1.PodList,
 // And loop through _, pod := range podList.Items,
@@ -112,10 +163,119 @@ func ReadPodHealth(r *results.Reader) (HealthInfo, error) {
 	return health, nil
 }
 
+// ReadEventSummary reads the events gathered by QueryEvents and counts them. The same event is
+// usually returned by both the core/v1 and events.k8s.io/v1 APIs so they are deduplicated by UID.
+func ReadEventSummary(r *results.Reader) (EventSummary, error) {
+	summary := EventSummary{WarningsByReason: map[string]int{}}
+	coreEvents := &v1.EventList{}
+	v1Events := &eventsv1.EventList{}
+	coreFile := path.Join(EventsLocation, CoreEventsFile)
+	v1File := path.Join(EventsLocation, EventsV1File)
+	err := r.WalkFiles(func(path string, info os.FileInfo, err error) error {
+		if err := results.ExtractFileIntoStruct(coreFile, path, info, coreEvents); err != nil {
+			return err
+		}
+		return results.ExtractFileIntoStruct(v1File, path, info, v1Events)
+	})
+	if err != nil {
+		logrus.Errorf("Failed to read event summary: %s", err)
+		return summary, err
+	}
+
+	seen := map[types.UID]bool{}
+	count := func(uid types.UID, eventType, reason string) {
+		if len(uid) > 0 && seen[uid] {
+			return
+		}
+		seen[uid] = true
+		summary.Total++
+		if eventType == v1.EventTypeWarning {
+			summary.Warnings++
+			summary.WarningsByReason[reason]++
+		}
+	}
+	for _, e := range coreEvents.Items {
+		count(e.UID, e.Type, e.Reason)
+	}
+	for _, e := range v1Events.Items {
+		count(e.UID, e.Type, e.Reason)
+	}
+	return summary, nil
+}
+
+// ReadLeakedResources reads the inventory report saved by the aggregator, if any, and returns the
+// objects which were added while the plugins ran.
+func ReadLeakedResources(r *results.Reader) ([]LeakedResource, error) {
+	report := InventoryReport{}
+	reportFile := path.Join(MetaLocation, InventoryFile)
+	err := r.WalkFiles(func(path string, info os.FileInfo, err error) error {
+		return results.ExtractFileIntoStruct(reportFile, path, info, &report)
+	})
+	if err != nil {
+		logrus.Errorf("Failed to read inventory report: %s", err)
+		return nil, err
+	}
+	return report.Leaked(), nil
+}
+
+// readNodeStats reads the stats gathered from the kubelet of each node, keyed by the node name. The
+// files are all read in a single walk of the results.
+func readNodeStats(r *results.Reader) map[string]nodeStatsSummary {
+	out := map[string]nodeStatsSummary{}
+	err := r.WalkFiles(func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		dir, file := path.Split(filePath)
+		if file != StatsSummaryFile || path.Dir(path.Clean(dir)) != HostsLocation {
+			return nil
+		}
+		stats := nodeStatsSummary{}
+		if err := results.ExtractFileIntoStruct(filePath, filePath, info, &stats); err != nil {
+			// The stats may have been truncated; the allocatable values are still useful.
+			logrus.Warningf("Failed to read node stats from '%s': %s", filePath, err)
+			return nil
+		}
+		out[path.Base(dir)] = stats
+		return nil
+	})
+	if err != nil {
+		logrus.Warningf("Failed to read node stats: %s", err)
+	}
+	return out
+}
+
+// readNodeResourceUsage returns the allocatable resources of the node along with the usage from the
+// stats gathered from its kubelet, if any.
+func readNodeResourceUsage(node v1.Node, nodeStats map[string]nodeStatsSummary) *NodeResourceUsage {
+	usage := &NodeResourceUsage{
+		CPUAllocatableMillis:   node.Status.Allocatable.Cpu().MilliValue(),
+		MemoryAllocatableBytes: node.Status.Allocatable.Memory().Value(),
+	}
+
+	stats, ok := nodeStats[node.Name]
+	if !ok {
+		return usage
+	}
+	if stats.Node.CPU != nil && stats.Node.CPU.UsageNanoCores != nil {
+		usage.CPUUsedMillis = new(int64(*stats.Node.CPU.UsageNanoCores / 1e6))
+	}
+	if stats.Node.Memory != nil && stats.Node.Memory.WorkingSetBytes != nil {
+		usage.MemoryUsedBytes = new(int64(*stats.Node.Memory.WorkingSetBytes))
+	}
+	return usage
+}
+
 // ReadHealthSummary reads the core_v1_nodes.json file from ClusterResourceLocation
 // and returns a summary of the health fo the cluster, ready to be saved
 // tarballRootDir is the directory that will be used to provide the contents of the tarball
 func ReadHealthSummary(tarballRootDir string) (ClusterSummary, error) {
+	return ReadHealthSummaryWithLogPatterns(tarballRootDir, GetDefaultLogPatterns())
+}
+
+// ReadHealthSummaryWithLogPatterns is the same as ReadHealthSummary but summarizes the logs using
+// the given patterns.
+func ReadHealthSummaryWithLogPatterns(tarballRootDir string, patterns LogPatterns) (ClusterSummary, error) {
 	summary := ClusterSummary{}
 	nodes := &v1.NodeList{}
 	r := results.NewReaderFromDir(tarballRootDir)
@@ -129,6 +289,7 @@ func ReadHealthSummary(tarballRootDir string) (ClusterSummary, error) {
 	}
 	summary.NodeHealth.Total = len(nodes.Items)
 	summary.NodeHealth.Details = make([]HealthInfoDetails, summary.NodeHealth.Total)
+	nodeStats := readNodeStats(r)
 
 	for nodeIdx, node := range nodes.Items {
 		summary.NodeHealth.Details[nodeIdx].Name = node.ObjectMeta.Name
@@ -143,7 +304,13 @@ func ReadHealthSummary(tarballRootDir string) (ClusterSummary, error) {
 					summary.NodeHealth.Healthy++
 				}
 			}
+			for _, pressure := range nodePressureConditions {
+				if condition.Type == pressure && condition.Status == v1.ConditionTrue {
+					summary.NodeHealth.Details[nodeIdx].Pressure = append(summary.NodeHealth.Details[nodeIdx].Pressure, string(pressure))
+				}
+			}
 		}
+		summary.NodeHealth.Details[nodeIdx].Resources = readNodeResourceUsage(node, nodeStats)
 	}
 	summary.APIVersion, _ = r.ReadVersion()
 	//ReadVersion already logged this error, and we can continue with the rest of the information
@@ -151,8 +318,14 @@ func ReadHealthSummary(tarballRootDir string) (ClusterSummary, error) {
 	summary.PodHealth, _ = ReadPodHealth(r)
 	//ReadPodHealth already logged this error, and we can continue with the rest of the information
 
-	summary.ErrorInfo, _ = ReadLogSummaryWithDefaultPatterns(r)
-	//ReadLogSummary already logged this error, and we can continue with the rest of the information
+	summary.ErrorInfo, summary.LogSamples, _ = ReadLogSummaryWithSamples(r, patterns)
+	//ReadLogSummaryWithSamples already logged this error, and we can continue with the rest of the information
+
+	summary.Events, _ = ReadEventSummary(r)
+	//ReadEventSummary already logged this error, and we can continue with the rest of the information
+
+	summary.LeakedResources, _ = ReadLeakedResources(r)
+	//ReadLeakedResources already logged this error, and we can continue with the rest of the information
 
 	return summary, nil
 }
@@ -165,8 +338,14 @@ func ReadHealthSummary(tarballRootDir string) (ClusterSummary, error) {
 // results.ClusterHealthFilePath() in tarballRootDir
 // SaveHealthSummary assumes that all the directories including MetaLocation have already been created
 func SaveHealthSummary(tarballRootDir string) error {
+	return SaveHealthSummaryWithLogPatterns(tarballRootDir, GetDefaultLogPatterns())
+}
+
+// SaveHealthSummaryWithLogPatterns is the same as SaveHealthSummary but summarizes the logs using
+// the given patterns.
+func SaveHealthSummaryWithLogPatterns(tarballRootDir string, patterns LogPatterns) error {
 	outputFileName := path.Join(tarballRootDir, results.ClusterHealthFilePath())
-	healthSummary, err := ReadHealthSummary(tarballRootDir)
+	healthSummary, err := ReadHealthSummaryWithLogPatterns(tarballRootDir, patterns)
 	if err != nil {
 		logrus.Errorf("Failed to read cluster health information from '%s': %s", tarballRootDir, err)
 		logrus.Errorf("File '%s' will not be included in '%s'.", outputFileName, tarballRootDir)
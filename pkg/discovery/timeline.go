@@ -0,0 +1,352 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// TimelineFile is the filename of the run timeline, relative to MetaLocation.
+	TimelineFile = "timeline.jsonl"
+
+	// Kinds of entries in the timeline.
+	timelineKindEvent = "Event"
+	timelineKindPod   = "Pod"
+	timelineKindNode  = "Node"
+
+	// timelinePhase is the condition type used to record pod phase changes.
+	timelinePhase = "Phase"
+)
+
+// TimelineEntry is a single line of the timeline; either an event or a change in the condition of a pod or node.
+type TimelineEntry struct {
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+
+	// Type is the type of the event (e.g. Warning) or the condition (e.g. Ready).
+	Type string `json:"type"`
+
+	// Status is the new status of the condition. Unset for events.
+	Status string `json:"status,omitempty"`
+
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Object is the kind/name of the object an event is about. Unset for conditions.
+	Object string `json:"object,omitempty"`
+
+	// Count is the number of times an event has occurred. Unset for conditions.
+	Count int32 `json:"count,omitempty"`
+}
+
+// TimelineRecorder watches the cluster for the duration of the run and writes events along with
+// pod and node condition changes to a JSONL file. Unlike QueryEvents, this captures events
+// which expire before the end of long runs.
+type TimelineRecorder struct {
+	client    kubernetes.Interface
+	namespace string
+	nodes     bool
+	start     time.Time
+
+	// namespaces lists the namespaces of the cluster if pods are watched across it, so that only
+	// the pods in namespaces created during the run (e.g. by plugins) are recorded.
+	namespaces listersv1.NamespaceLister
+
+	mu  sync.Mutex
+	out *os.File
+	enc *json.Encoder
+}
+
+// NewTimelineRecorder returns a recorder which writes to the given file. Events are watched across
+// the cluster if permitted, otherwise only in the namespace. Pods are watched in the namespace and
+// any namespaces created during the run. Nodes are only watched if watchNodes is true.
+func NewTimelineRecorder(client kubernetes.Interface, namespace string, watchNodes bool, file string) (*TimelineRecorder, error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating timeline file")
+	}
+	return &TimelineRecorder{
+		client:    client,
+		namespace: namespace,
+		nodes:     watchNodes,
+		out:       f,
+		enc:       json.NewEncoder(f),
+	}, nil
+}
+
+// Start starts the informers and returns a function which stops them and closes the file.
+// It waits for the informers to sync; events last seen before Start are ignored, as are the pods
+// and nodes which already exist until their conditions change.
+func (t *TimelineRecorder) Start() (stop func()) {
+	t.start = time.Now()
+	stopCh := make(chan struct{})
+
+	watchNamespace := t.watchNamespace()
+	nsFactory := informers.NewSharedInformerFactoryWithOptions(t.client, 0, informers.WithNamespace(watchNamespace))
+	if watchNamespace == metav1.NamespaceAll && t.canListNamespaces() {
+		t.namespaces = nsFactory.Core().V1().Namespaces().Lister()
+	}
+	nsFactory.Core().V1().Events().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { t.recordEvent(obj) },
+		UpdateFunc: func(_, obj interface{}) { t.recordEvent(obj) },
+	})
+	nsFactory.Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if !isInInitialList {
+				t.recordPod(nil, obj)
+			}
+		},
+		UpdateFunc: func(old, obj interface{}) { t.recordPod(old, obj) },
+	})
+	nsFactory.Start(stopCh)
+	nsFactory.WaitForCacheSync(stopCh)
+
+	if t.nodes && t.canListNodes() {
+		clusterFactory := informers.NewSharedInformerFactory(t.client, 0)
+		clusterFactory.Core().V1().Nodes().Informer().AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+			AddFunc: func(obj interface{}, isInInitialList bool) {
+				if !isInInitialList {
+					t.recordNode(nil, obj)
+				}
+			},
+			UpdateFunc: func(old, obj interface{}) { t.recordNode(old, obj) },
+		})
+		clusterFactory.Start(stopCh)
+		clusterFactory.WaitForCacheSync(stopCh)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stopCh)
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err := t.out.Close(); err != nil {
+				logrus.Errorf("Failed to close timeline file: %v", err)
+			}
+			t.enc = nil
+		})
+	}
+}
+
+// watchNamespace returns the namespace to watch events and pods in: all namespaces if they can
+// be listed cluster-wide so that plugin namespaces and node events are included, otherwise the
+// recorder's namespace.
+func (t *TimelineRecorder) watchNamespace() string {
+	opts := metav1.ListOptions{Limit: 1}
+	if _, err := t.client.CoreV1().Events("").List(context.TODO(), opts); err != nil {
+		logrus.Infof("Only recording events and pods in namespace %v in the timeline: %v", t.namespace, err)
+		return t.namespace
+	}
+	if _, err := t.client.CoreV1().Pods("").List(context.TODO(), opts); err != nil {
+		logrus.Infof("Only recording events and pods in namespace %v in the timeline: %v", t.namespace, err)
+		return t.namespace
+	}
+	return metav1.NamespaceAll
+}
+
+// canListNamespaces checks whether the namespaces created during the run can be found, otherwise
+// only the pods in the recorder's namespace are recorded.
+func (t *TimelineRecorder) canListNamespaces() bool {
+	_, err := t.client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		logrus.Infof("Only recording pods in namespace %v in the timeline: %v", t.namespace, err)
+		return false
+	}
+	return true
+}
+
+// recordedNamespace returns true if the pods in the namespace should be recorded: those in the
+// recorder's namespace and in namespaces created during the run, such as those of the e2e tests.
+func (t *TimelineRecorder) recordedNamespace(namespace string) bool {
+	if namespace == t.namespace {
+		return true
+	}
+	if t.namespaces == nil {
+		return false
+	}
+	ns, err := t.namespaces.Get(namespace)
+	if err != nil {
+		// The namespace informer may not have seen a namespace which was just created.
+		if ns, err = t.client.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{}); err != nil {
+			return false
+		}
+	}
+	// Creation timestamps only have a precision of seconds.
+	return !ns.CreationTimestamp.Time.Before(t.start.Truncate(time.Second))
+}
+
+// canListNodes avoids starting a node informer which would fail (and log) continually due to
+// missing permissions.
+func (t *TimelineRecorder) canListNodes() bool {
+	_, err := t.client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		logrus.Warningf("Not recording node conditions in the timeline: %v", err)
+		return false
+	}
+	return true
+}
+
+func (t *TimelineRecorder) write(entries ...TimelineEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.enc == nil {
+		return
+	}
+	for _, e := range entries {
+		if err := t.enc.Encode(e); err != nil {
+			logrus.Errorf("Failed to write to timeline: %v", err)
+			return
+		}
+	}
+}
+
+func (t *TimelineRecorder) recordEvent(obj interface{}) {
+	e, ok := obj.(*corev1.Event)
+	if !ok {
+		return
+	}
+	lastSeen := coreEventLastSeen(*e)
+	if lastSeen.Before(t.start) {
+		return
+	}
+	t.write(TimelineEntry{
+		Time:      lastSeen,
+		Kind:      timelineKindEvent,
+		Namespace: e.Namespace,
+		Name:      e.Name,
+		Type:      e.Type,
+		Reason:    e.Reason,
+		Message:   e.Message,
+		Object:    e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name,
+		Count:     e.Count,
+	})
+}
+
+func (t *TimelineRecorder) recordPod(oldObj, obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	if !t.recordedNamespace(pod.Namespace) {
+		return
+	}
+	old, _ := oldObj.(*corev1.Pod)
+	t.write(podConditionChanges(old, pod, time.Now())...)
+}
+
+func (t *TimelineRecorder) recordNode(oldObj, obj interface{}) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return
+	}
+	old, _ := oldObj.(*corev1.Node)
+	t.write(nodeConditionChanges(old, node, time.Now())...)
+}
+
+// condition is the common subset of pod and node conditions.
+type condition struct {
+	Type, Status, Reason, Message string
+	LastTransition                time.Time
+}
+
+// podConditionChanges returns an entry for each pod condition (and the phase) which differs from
+// the old pod. If old is nil, all of the conditions are returned.
+func podConditionChanges(old, pod *corev1.Pod, now time.Time) []TimelineEntry {
+	toConditions := func(p *corev1.Pod) map[string]condition {
+		out := map[string]condition{}
+		if p == nil {
+			return out
+		}
+		out[timelinePhase] = condition{Type: timelinePhase, Status: string(p.Status.Phase), Reason: p.Status.Reason, Message: p.Status.Message}
+		for _, c := range p.Status.Conditions {
+			out[string(c.Type)] = condition{Type: string(c.Type), Status: string(c.Status), Reason: c.Reason, Message: c.Message, LastTransition: c.LastTransitionTime.Time}
+		}
+		return out
+	}
+	return conditionChanges(timelineKindPod, pod.Namespace, pod.Name, toConditions(old), toConditions(pod), now)
+}
+
+// nodeConditionChanges returns an entry for each node condition which differs from the old node.
+// If old is nil, all of the conditions are returned.
+func nodeConditionChanges(old, node *corev1.Node, now time.Time) []TimelineEntry {
+	toConditions := func(n *corev1.Node) map[string]condition {
+		out := map[string]condition{}
+		if n == nil {
+			return out
+		}
+		for _, c := range n.Status.Conditions {
+			out[string(c.Type)] = condition{Type: string(c.Type), Status: string(c.Status), Reason: c.Reason, Message: c.Message, LastTransition: c.LastTransitionTime.Time}
+		}
+		return out
+	}
+	return conditionChanges(timelineKindNode, "", node.Name, toConditions(old), toConditions(node), now)
+}
+
+func conditionChanges(kind, namespace, name string, old, current map[string]condition, now time.Time) []TimelineEntry {
+	entries := []TimelineEntry{}
+	for _, typ := range sortedConditionTypes(current) {
+		c := current[typ]
+		if len(c.Status) == 0 {
+			continue
+		}
+		if prev, ok := old[typ]; ok && prev.Status == c.Status && prev.Reason == c.Reason {
+			continue
+		}
+		ts := c.LastTransition
+		if ts.IsZero() {
+			ts = now
+		}
+		entries = append(entries, TimelineEntry{
+			Time:      ts,
+			Kind:      kind,
+			Namespace: namespace,
+			Name:      name,
+			Type:      c.Type,
+			Status:    c.Status,
+			Reason:    c.Reason,
+			Message:   c.Message,
+		})
+	}
+	return entries
+}
+
+func sortedConditionTypes(conditions map[string]condition) []string {
+	out := make([]string, 0, len(conditions))
+	for typ := range conditions {
+		out = append(out, typ)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,269 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kuberuntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPodConditionChanges(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	transition := metav1.NewTime(now.Add(-time.Second))
+	pod := func(phase corev1.PodPhase, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "sonobuoy"},
+			Status: corev1.PodStatus{
+				Phase: phase,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: transition},
+					{Type: corev1.PodReady, Status: ready, Reason: "ContainersNotReady", LastTransitionTime: transition},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc   string
+		old    *corev1.Pod
+		pod    *corev1.Pod
+		expect []string
+	}{
+		{
+			desc:   "New pod records everything",
+			pod:    pod(corev1.PodPending, corev1.ConditionFalse),
+			expect: []string{"Phase=Pending", "PodScheduled=True", "Ready=False"},
+		}, {
+			desc:   "Unchanged pod records nothing",
+			old:    pod(corev1.PodRunning, corev1.ConditionTrue),
+			pod:    pod(corev1.PodRunning, corev1.ConditionTrue),
+			expect: []string{},
+		}, {
+			desc:   "Only changes are recorded",
+			old:    pod(corev1.PodRunning, corev1.ConditionTrue),
+			pod:    pod(corev1.PodFailed, corev1.ConditionFalse),
+			expect: []string{"Phase=Failed", "Ready=False"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			entries := podConditionChanges(tc.old, tc.pod, now)
+			got := []string{}
+			for _, e := range entries {
+				got = append(got, e.Type+"="+e.Status)
+				if e.Kind != timelineKindPod || e.Namespace != "sonobuoy" || e.Name != "p" {
+					t.Errorf("Unexpected entry source %v %v/%v", e.Kind, e.Namespace, e.Name)
+				}
+				expectTime := transition.Time
+				if e.Type == timelinePhase {
+					expectTime = now
+				}
+				if !e.Time.Equal(expectTime) {
+					t.Errorf("Expected time %v for %v but got %v", expectTime, e.Type, e.Time)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("Expected %v but got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestNodeConditionChanges(t *testing.T) {
+	node := func(memoryPressure corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "n"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+					{Type: corev1.NodeMemoryPressure, Status: memoryPressure},
+				},
+			},
+		}
+	}
+	entries := nodeConditionChanges(node(corev1.ConditionFalse), node(corev1.ConditionTrue), time.Now())
+	if len(entries) != 1 || entries[0].Type != string(corev1.NodeMemoryPressure) || entries[0].Status != "True" {
+		t.Errorf("Expected a single MemoryPressure=True entry but got %+v", entries)
+	}
+}
+
+func TestTimelineRecorder(t *testing.T) {
+	old := metav1.NewTime(time.Now().Add(-time.Hour))
+	client := fake.NewSimpleClientset(
+		// The conditions of existing nodes and pods are only recorded when they change.
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "n"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastTransitionTime: old},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "sonobuoy"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", CreationTimestamp: old}},
+		&corev1.Event{
+			// Stale events from before the run are ignored.
+			ObjectMeta:    metav1.ObjectMeta{Name: "old", Namespace: "sonobuoy"},
+			LastTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+	)
+	file := filepath.Join(t.TempDir(), TimelineFile)
+	recorder, err := NewTimelineRecorder(client, "sonobuoy", true, file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stop := recorder.Start()
+	defer stop()
+
+	ctx := context.TODO()
+	_, err = client.CoreV1().Pods("sonobuoy").Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "plugin", Namespace: "sonobuoy"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CoreV1().Events("sonobuoy").Create(ctx, &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "new", Namespace: "sonobuoy"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "plugin"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		LastTimestamp:  metav1.Now(),
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CoreV1().Nodes().Update(ctx, &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Reason: "KubeletNotReady"},
+		}},
+	}, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pods in namespaces created during the run, e.g. by plugins, are recorded too but not those
+	// in the other namespaces.
+	_, err = client.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "plugin-ns", CreationTimestamp: metav1.Now()},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ns := range []string{"plugin-ns", "kube-system"} {
+		_, err = client.CoreV1().Pods(ns).Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-" + ns, Namespace: ns},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expect := map[string]bool{
+		"Pod/plugin/Phase=Pending":          true,
+		"Pod/other-plugin-ns/Phase=Running": true,
+		"Event/new/Warning=":                true,
+		"Node/n/Ready=False":                true,
+	}
+	var got map[string]bool
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		got = readTimeline(t, file)
+		if reflect.DeepEqual(got, expect) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected timeline %v but got %v", expect, got)
+	}
+
+	// Nothing is written after stopping.
+	stop()
+	if _, err := client.CoreV1().Pods("sonobuoy").Create(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "late", Namespace: "sonobuoy"}}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if final := readTimeline(t, file); !reflect.DeepEqual(final, expect) {
+		t.Errorf("Expected no entries after stopping but got %v", final)
+	}
+}
+
+func readTimeline(t *testing.T, file string) map[string]bool {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	out := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e TimelineEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Invalid timeline line %q: %v", scanner.Text(), err)
+		}
+		out[e.Kind+"/"+e.Name+"/"+e.Type+"="+e.Status] = true
+	}
+	return out
+}
+
+func TestTimelineWatchNamespace(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		forbidden string
+		expect    string
+	}{
+		{desc: "Cluster-wide if permitted", expect: ""},
+		{desc: "Namespaced if events can't be listed cluster-wide", forbidden: "events", expect: "sonobuoy"},
+		{desc: "Namespaced if pods can't be listed cluster-wide", forbidden: "pods", expect: "sonobuoy"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.PrependReactor("list", tc.forbidden, func(action k8stesting.Action) (bool, kuberuntime.Object, error) {
+				if action.GetNamespace() != "" {
+					return false, nil, nil
+				}
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: tc.forbidden}, "", errors.New("no"))
+			})
+			recorder := &TimelineRecorder{client: client, namespace: "sonobuoy"}
+			if got := recorder.watchNamespace(); got != tc.expect {
+				t.Errorf("Expected namespace %q but got %q", tc.expect, got)
+			}
+		})
+	}
+}
@@ -8,12 +8,15 @@ const (
 	PluginInstallation = "SONOBUOY_PLUGIN_INSTALLATION"
 
 	WaitOutputProgressByDefault = "SONOBUOY_WAIT_PROGRESS"
+
+	PluginLinting = "SONOBUOY_PLUGIN_LINTING"
 )
 
 var (
 	featureDefaultMap = map[string]bool{
 		PluginInstallation:          true,
 		WaitOutputProgressByDefault: true,
+		PluginLinting:               true,
 	}
 )
 
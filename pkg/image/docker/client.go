@@ -67,7 +67,16 @@ type inspectResponse struct {
 }
 
 func (l LocalDocker) Run(image string, entryPoint string, env map[string]string, args ...string) ([]string, error) {
+	return l.RunWithVolumes(image, entryPoint, env, nil, args...)
+}
+
+// RunWithVolumes runs the image like Run, also mounting each host path in volumes at the
+// container path it maps to.
+func (l LocalDocker) RunWithVolumes(image string, entryPoint string, env map[string]string, volumes map[string]string, args ...string) ([]string, error) {
 	dockerArgs := []string{"run", "--rm"}
+	for hostPath, containerPath := range volumes {
+		dockerArgs = append(dockerArgs, fmt.Sprintf("--volume=%v:%v", hostPath, containerPath))
+	}
 	if len(entryPoint) > 0 {
 		dockerArgs = append(dockerArgs, fmt.Sprintf("--entrypoint=%v", entryPoint))
 	}
@@ -147,6 +147,26 @@ func (i DockerClient) RunImage(image string, entryPoint string, env map[string]s
 	return output, nil
 }
 
+// volumeRunner is implemented by the docker clients which can mount host directories into the
+// containers they run, such as docker.LocalDocker.
+type volumeRunner interface {
+	RunWithVolumes(image string, entryPoint string, env map[string]string, volumes map[string]string, args ...string) ([]string, error)
+}
+
+// RunImageWithVolumes runs the image like RunImage, also mounting each host path in volumes at
+// the container path it maps to.
+func (i DockerClient) RunImageWithVolumes(image string, entryPoint string, env map[string]string, volumes map[string]string, args ...string) ([]string, error) {
+	runner, ok := i.dockerClient.(volumeRunner)
+	if !ok {
+		return []string{}, errors.Errorf("docker client %T does not support mounting volumes", i.dockerClient)
+	}
+	output, err := runner.RunWithVolumes(image, entryPoint, env, volumes, args...)
+	if err != nil {
+		return output, err
+	}
+	return output, nil
+}
+
 // getTarFileName returns a filename matching the version of Kubernetes images are exported
 func getTarFileName(version string) string {
 	return fmt.Sprintf("kubernetes_e2e_images_%s.tar", version)
@@ -279,3 +279,13 @@ func TestDeleteImages(t *testing.T) {
 		})
 	}
 }
+
+func TestRunImageWithVolumes(t *testing.T) {
+	// Implementations of docker.Docker don't have to support mounting volumes.
+	imgClient := DockerClient{
+		dockerClient: FakeDockerClient{},
+	}
+	if _, err := imgClient.RunImageWithVolumes("plugin:v1", "", nil, map[string]string{"/tmp/results": "/tmp/sonobuoy/results"}); err == nil {
+		t.Fatal("Expected an error for a docker client which can't mount volumes")
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+)
+
+const (
+	// attestationReferenceTypeAnnotation and attestationReferenceDigestAnnotation mark the
+	// attestation manifests which buildkit adds to an index, along with the image they describe.
+	attestationReferenceTypeAnnotation   = "vnd.docker.reference.type"
+	attestationReferenceDigestAnnotation = "vnd.docker.reference.digest"
+	attestationManifestType              = "attestation-manifest"
+
+	// predicateTypeAnnotation is set on each layer of an attestation manifest to the type of its
+	// in-toto statement.
+	predicateTypeAnnotation = "in-toto.io/predicate-type"
+)
+
+// sbomPredicateTypes are the in-toto predicate types of SBOM attestations.
+var sbomPredicateTypes = map[string]bool{
+	"https://spdx.dev/Document":    true,
+	"https://cyclonedx.org/bom":    true,
+	"https://cyclonedx.org/schema": true,
+}
+
+// InventoryItem describes a single platform of an image.
+type InventoryItem struct {
+	Image string `json:"image"`
+
+	// Digest is the digest the image reference resolves to. For multi-platform images this is the
+	// digest of the index; PlatformDigest is the digest of this platform's image.
+	Digest         string    `json:"digest"`
+	Platform       string    `json:"platform"`
+	PlatformDigest string    `json:"platformDigest"`
+	Size           int64     `json:"size"`
+	Created        time.Time `json:"created"`
+
+	// SBOMType is the predicate type of the SBOM attested for the image, if any. SBOMDigest is the
+	// digest of the attestation layer holding it and SBOM is the SBOM document itself.
+	SBOMType   string          `json:"sbomType,omitempty"`
+	SBOMDigest string          `json:"sbomDigest,omitempty"`
+	SBOM       json.RawMessage `json:"sbom,omitempty"`
+}
+
+// inTotoStatement is the part of an in-toto attestation needed to extract its predicate.
+type inTotoStatement struct {
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// Inventory fetches the manifests of the images and describes each of their platforms, including
+// the SBOM if one is attached to the image as an attestation. Only the platforms in Platforms are
+// described if it is set.
+func (i RegistryClient) Inventory(images []string) ([]InventoryItem, []error) {
+	items := []InventoryItem{}
+	errs := []error{}
+	for _, image := range images {
+		imageItems, err := i.inventory(image)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "couldn't inventory image %v", image))
+			continue
+		}
+		items = append(items, imageItems...)
+	}
+	return items, errs
+}
+
+func (i RegistryClient) inventory(image string) ([]InventoryItem, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid image reference %q", image)
+	}
+	desc, err := remote.Get(ref, i.options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !desc.MediaType.IsIndex() {
+		img, err := desc.Image()
+		if err != nil {
+			return nil, err
+		}
+		item, err := inventoryItem(image, desc.Digest, img)
+		if err != nil {
+			return nil, err
+		}
+		return []InventoryItem{item}, nil
+	}
+
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	attestations := map[string]v1.Hash{}
+	for _, m := range manifest.Manifests {
+		if m.Annotations[attestationReferenceTypeAnnotation] == attestationManifestType {
+			attestations[m.Annotations[attestationReferenceDigestAnnotation]] = m.Digest
+		}
+	}
+
+	items := []InventoryItem{}
+	for _, m := range manifest.Manifests {
+		if m.Platform == nil || m.Platform.OS == "unknown" {
+			continue
+		}
+		if len(i.Platforms) > 0 && !matchesPlatforms(*m.Platform, i.Platforms) {
+			continue
+		}
+		img, err := idx.Image(m.Digest)
+		if err != nil {
+			return nil, err
+		}
+		item, err := inventoryItem(image, desc.Digest, img)
+		if err != nil {
+			return nil, err
+		}
+		item.Platform = m.Platform.String()
+
+		if att, ok := attestations[m.Digest.String()]; ok {
+			attImg, err := idx.Image(att)
+			if err != nil {
+				return nil, err
+			}
+			if err := addSBOM(&item, attImg); err != nil {
+				return nil, errors.Wrapf(err, "reading attestations of platform %v", item.Platform)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func inventoryItem(image string, digest v1.Hash, img v1.Image) (InventoryItem, error) {
+	item := InventoryItem{Image: image, Digest: digest.String()}
+	d, err := img.Digest()
+	if err != nil {
+		return item, err
+	}
+	item.PlatformDigest = d.String()
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return item, err
+	}
+	item.Size = manifest.Config.Size
+	for _, l := range manifest.Layers {
+		item.Size += l.Size
+	}
+
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return item, err
+	}
+	item.Created = cfg.Created.Time
+	if p := cfg.Platform(); p != nil {
+		item.Platform = p.String()
+	}
+	return item, nil
+}
+
+// addSBOM sets the SBOM of the item from the first SBOM statement in the attestation manifest.
+func addSBOM(item *InventoryItem, attestation v1.Image) error {
+	manifest, err := attestation.Manifest()
+	if err != nil {
+		return err
+	}
+	for _, l := range manifest.Layers {
+		if !sbomPredicateTypes[l.Annotations[predicateTypeAnnotation]] {
+			continue
+		}
+		layer, err := attestation.LayerByDigest(l.Digest)
+		if err != nil {
+			return err
+		}
+		rc, err := layer.Uncompressed()
+		if err != nil {
+			return err
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		var statement inTotoStatement
+		if err := json.Unmarshal(b, &statement); err != nil {
+			return errors.Wrap(err, "invalid in-toto statement")
+		}
+		item.SBOMType = statement.PredicateType
+		item.SBOMDigest = l.Digest.String()
+		item.SBOM = statement.Predicate
+		return nil
+	}
+	return nil
+}
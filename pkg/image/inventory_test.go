@@ -0,0 +1,112 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// pushAttestedIndex pushes an index with a linux/amd64 image and an SBOM attestation for it,
+// laid out the way buildkit does.
+func pushAttestedIndex(t *testing.T, image string, created time.Time) v1.Image {
+	t.Helper()
+	img, err := random.Image(64, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img = withConfig(t, img, func(cfg *v1.ConfigFile) {
+		cfg.OS, cfg.Architecture = "linux", "amd64"
+		cfg.Created = v1.Time{Time: created}
+	})
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statement := `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://spdx.dev/Document","predicate":{"spdxVersion":"SPDX-2.3"}}`
+	att, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer([]byte(statement), "application/vnd.in-toto+json"),
+		Annotations: map[string]string{predicateTypeAnnotation: "https://spdx.dev/Document"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex),
+		mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: att, Descriptor: v1.Descriptor{
+			Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"},
+			Annotations: map[string]string{
+				attestationReferenceTypeAnnotation:   attestationManifestType,
+				attestationReferenceDigestAnnotation: d.String(),
+			},
+		}},
+	)
+	if err := remote.WriteIndex(mustParseReference(t, image), idx); err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestRegistryClientInventory(t *testing.T) {
+	host := newTestRegistry(t)
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	img := pushAttestedIndex(t, host+"/attested:v1", created)
+	single := pushRandomImage(t, host+"/single:v1")
+
+	items, errs := newTestRegistryClient(t, "").Inventory([]string{host + "/attested:v1", host + "/single:v1", host + "/missing:v1"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "missing:v1") {
+		t.Errorf("Expected an error for the missing image but got %v", errs)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items but got %+v", items)
+	}
+
+	attested := items[0]
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := manifest.Config.Size + manifest.Layers[0].Size + manifest.Layers[1].Size
+	if attested.PlatformDigest != d.String() || attested.Digest == d.String() {
+		t.Errorf("Expected platform digest %v and the index digest but got %+v", d, attested)
+	}
+	if attested.Platform != "linux/amd64" || attested.Size != size || !attested.Created.Equal(created) {
+		t.Errorf("Expected linux/amd64 of size %v created at %v but got %+v", size, created, attested)
+	}
+	if attested.SBOMType != "https://spdx.dev/Document" || string(attested.SBOM) != `{"spdxVersion":"SPDX-2.3"}` || len(attested.SBOMDigest) == 0 {
+		t.Errorf("Expected the SPDX SBOM but got %v %v %s", attested.SBOMType, attested.SBOMDigest, attested.SBOM)
+	}
+
+	if items[1].Digest != single.String() || items[1].PlatformDigest != single.String() || len(items[1].SBOMType) > 0 {
+		t.Errorf("Expected single image with digest %v and no SBOM but got %+v", single, items[1])
+	}
+}
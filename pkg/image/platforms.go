@@ -0,0 +1,156 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+)
+
+// PlatformDigest is the digest of the image for a single platform.
+type PlatformDigest struct {
+	Platform string `json:"platform"`
+	Digest   string `json:"digest"`
+}
+
+// PlatformDigests lists the platforms an image is available for. For multi-platform images Digest
+// is the digest of the index; otherwise it is the same as the digest of its only platform.
+type PlatformDigests struct {
+	Image     string           `json:"image"`
+	Digest    string           `json:"digest"`
+	Platforms []PlatformDigest `json:"platforms"`
+}
+
+// ParsePlatforms parses platforms in the form os/arch[/variant] (e.g. linux/arm64).
+func ParsePlatforms(platforms []string) ([]v1.Platform, error) {
+	ret := []v1.Platform{}
+	for _, s := range platforms {
+		p, err := v1.ParsePlatform(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid platform %q", s)
+		}
+		if len(p.OS) == 0 || len(p.Architecture) == 0 {
+			return nil, errors.Errorf("invalid platform %q, expected the form os/arch[/variant]", s)
+		}
+		ret = append(ret, *p)
+	}
+	return ret, nil
+}
+
+// GetPlatformDigests fetches the manifest of the image and returns the digest of each of its
+// platforms, sorted by platform.
+func (i RegistryClient) GetPlatformDigests(image string) (PlatformDigests, error) {
+	ret := PlatformDigests{Image: image}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return ret, errors.Wrapf(err, "invalid image reference %q", image)
+	}
+	desc, err := remote.Get(ref, i.options...)
+	if err != nil {
+		return ret, errors.Wrapf(err, "couldn't get manifest of image %v", image)
+	}
+	ret.Digest = desc.Digest.String()
+
+	if !desc.MediaType.IsIndex() {
+		img, err := desc.Image()
+		if err != nil {
+			return ret, err
+		}
+		platform, err := imagePlatform(img)
+		if err != nil {
+			return ret, errors.Wrapf(err, "couldn't get platform of image %v", image)
+		}
+		ret.Platforms = []PlatformDigest{{Platform: platform.String(), Digest: ret.Digest}}
+		return ret, nil
+	}
+
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return ret, err
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return ret, errors.Wrapf(err, "couldn't get index of image %v", image)
+	}
+	for _, m := range manifest.Manifests {
+		// Attestations and other artifacts are stored with an unknown platform.
+		if m.Platform == nil || m.Platform.OS == "unknown" {
+			continue
+		}
+		ret.Platforms = append(ret.Platforms, PlatformDigest{Platform: m.Platform.String(), Digest: m.Digest.String()})
+	}
+	sort.Slice(ret.Platforms, func(a, b int) bool { return ret.Platforms[a].Platform < ret.Platforms[b].Platform })
+	return ret, nil
+}
+
+// Missing returns the platforms (in the form os/arch[/variant]) which the image isn't available for.
+func (p PlatformDigests) Missing(platforms []string) []string {
+	missing := []string{}
+	for _, want := range platforms {
+		spec, err := v1.ParsePlatform(want)
+		if err != nil {
+			missing = append(missing, want)
+			continue
+		}
+		found := false
+		for _, have := range p.Platforms {
+			platform, err := v1.ParsePlatform(have.Platform)
+			if err == nil && platform.Satisfies(*spec) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, want)
+		}
+	}
+	return missing
+}
+
+func imagePlatform(img v1.Image) (v1.Platform, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return v1.Platform{}, err
+	}
+	p := cfg.Platform()
+	if p == nil {
+		return v1.Platform{}, errors.New("image config has no platform")
+	}
+	return *p, nil
+}
+
+func matchesPlatforms(platform v1.Platform, platforms []v1.Platform) bool {
+	for _, p := range platforms {
+		if platform.Satisfies(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func platformsString(platforms []v1.Platform) string {
+	s := make([]string, len(platforms))
+	for i, p := range platforms {
+		s[i] = p.String()
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,170 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// pushPlatformIndex pushes an index with an image for each platform and returns the digest of each
+// platform. An attestation manifest with an unknown platform is added too, as buildkit does.
+func pushPlatformIndex(t *testing.T, image string, platforms ...string) map[string]string {
+	t.Helper()
+	digests := map[string]string{}
+	var idx v1.ImageIndex = empty.Index
+	for _, s := range append(platforms, "unknown/unknown") {
+		p, err := v1.ParsePlatform(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := random.Image(64, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.OS != "unknown" {
+			digests[s] = d.String()
+		}
+		idx = mutate.AppendManifests(idx, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: p}})
+	}
+	if err := remote.WriteIndex(mustParseReference(t, image), idx); err != nil {
+		t.Fatal(err)
+	}
+	return digests
+}
+
+func TestParsePlatforms(t *testing.T) {
+	got, err := ParsePlatforms([]string{"linux/amd64", "linux/arm/v7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []v1.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm", Variant: "v7"}}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %v but got %v", expect, got)
+	}
+	for _, invalid := range []string{"linux", "linux/arm/v7/extra"} {
+		if _, err := ParsePlatforms([]string{invalid}); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestRegistryClientPushPlatforms(t *testing.T) {
+	src, dst := newTestRegistry(t), newTestRegistry(t)
+	digests := pushPlatformIndex(t, src+"/conformance:v1", "linux/amd64", "linux/arm64", "linux/s390x")
+	pushRandomImage(t, src+"/single:v1")
+
+	testCases := []struct {
+		desc         string
+		platforms    []string
+		image        string
+		expect       []string
+		expectErrMsg string
+	}{
+		{
+			desc:   "All platforms are copied by default",
+			image:  "conformance:v1",
+			expect: []string{"linux/amd64", "linux/arm64", "linux/s390x"},
+		}, {
+			desc:      "Only the requested platforms are copied",
+			platforms: []string{"linux/arm64", "linux/amd64"},
+			image:     "conformance:v1",
+			expect:    []string{"linux/amd64", "linux/arm64"},
+		}, {
+			desc:         "Index without the requested platforms",
+			platforms:    []string{"windows/amd64"},
+			image:        "conformance:v1",
+			expectErrMsg: "none of the platforms windows/amd64",
+		}, {
+			desc:         "Single platform image for another platform",
+			platforms:    []string{"linux/mips64le"},
+			image:        "single:v1",
+			expectErrMsg: "not linux/mips64le",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			platforms, err := ParsePlatforms(tc.platforms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := NewRegistryClient("", platforms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstImage := dst + "/" + strings.ToLower(strings.ReplaceAll(tc.desc, " ", "-")) + "/" + tc.image
+			errs := c.PushImages([]TagPair{{Src: src + "/" + tc.image, Dst: dstImage}}, 0)
+			if len(tc.expectErrMsg) > 0 {
+				if len(errs) != 1 || !strings.Contains(errs[0].Error(), tc.expectErrMsg) {
+					t.Errorf("Expected error containing %q but got %v", tc.expectErrMsg, errs)
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("Unexpected errors: %v", errs)
+			}
+
+			got, err := c.(RegistryClient).GetPlatformDigests(dstImage)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expect := []PlatformDigest{}
+			for _, p := range tc.expect {
+				expect = append(expect, PlatformDigest{Platform: p, Digest: digests[p]})
+			}
+			if !reflect.DeepEqual(got.Platforms, expect) {
+				t.Errorf("Expected platforms %v but got %v", expect, got.Platforms)
+			}
+		})
+	}
+}
+
+func TestGetPlatformDigestsSingleImage(t *testing.T) {
+	host := newTestRegistry(t)
+	d := pushRandomImage(t, host+"/single:v1")
+
+	got, err := newTestRegistryClient(t, "").GetPlatformDigests(host + "/single:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Digest != d.String() || len(got.Platforms) != 1 || got.Platforms[0].Digest != d.String() {
+		t.Errorf("Expected a single platform with digest %v but got %+v", d, got)
+	}
+}
+
+func TestPlatformDigestsMissing(t *testing.T) {
+	p := PlatformDigests{Platforms: []PlatformDigest{
+		{Platform: "linux/amd64"},
+		{Platform: "linux/arm64/v8"},
+		{Platform: "windows/amd64:10.0.17763.1"},
+	}}
+	got := p.Missing([]string{"linux/amd64", "linux/arm64", "windows/amd64", "linux/s390x"})
+	if expect := []string{"linux/s390x"}; !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %v but got %v", expect, got)
+	}
+}
@@ -0,0 +1,549 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	v1tarball "github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/sonobuoy/pkg/image/exec"
+	"github.com/vmware-tanzu/sonobuoy/pkg/tarball"
+)
+
+const (
+	// ArchiveFormatDocker saves images in the format used by `docker save`. Only the image for the
+	// platform of this machine is saved.
+	ArchiveFormatDocker = "docker-archive"
+
+	// ArchiveFormatOCI saves images as a tarball of an OCI image layout. Multi-platform images are
+	// saved with all of their platforms.
+	ArchiveFormatOCI = "oci"
+
+	// refNameAnnotation is the annotation of images in an OCI image layout which holds their reference.
+	refNameAnnotation = "org.opencontainers.image.ref.name"
+
+	// defaultImagePath is searched for the entrypoint of an image if its config does not set PATH.
+	defaultImagePath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+)
+
+// RegistryClient is an implementation of Client that talks to the registries directly rather
+// than relying on a local docker installation. Credentials are read from the docker config file.
+// Since there is no local image store, pulling and inspecting images only checks that their
+// manifests are available and deleting images does nothing.
+type RegistryClient struct {
+	// ArchiveFormat is the format of the tarball written by DownloadImages.
+	ArchiveFormat string
+
+	// Platforms limits the platforms of multi-platform images copied by PushImages. Every platform
+	// is copied if it is empty.
+	Platforms []v1.Platform
+
+	// AllowHostExecution allows RunImage to run the entrypoint of images on this machine. Without
+	// it, RunImage fails since there is no container runtime to isolate the image.
+	AllowHostExecution bool
+
+	options []remote.Option
+}
+
+// NewRegistryClient returns a RegistryClient which writes tarballs in the given format (see
+// ArchiveFormatDocker and ArchiveFormatOCI) and only pushes the given platforms, if any.
+// Additional options are passed to each request.
+func NewRegistryClient(archiveFormat string, platforms []v1.Platform, opts ...remote.Option) (Client, error) {
+	switch archiveFormat {
+	case "":
+		archiveFormat = ArchiveFormatDocker
+	case ArchiveFormatDocker, ArchiveFormatOCI:
+	default:
+		return nil, errors.Errorf("unknown archive format %q, expected %v or %v", archiveFormat, ArchiveFormatDocker, ArchiveFormatOCI)
+	}
+	return RegistryClient{
+		ArchiveFormat: archiveFormat,
+		Platforms:     platforms,
+		options: append([]remote.Option{
+			remote.WithAuthFromKeychain(authn.DefaultKeychain),
+			remote.WithPlatform(v1.Platform{OS: "linux", Architecture: runtime.GOARCH}),
+		}, opts...),
+	}, nil
+}
+
+// PullImages checks that the manifest of each image can be fetched. There is nowhere to store the
+// images themselves; they are fetched when they are needed.
+// It will retry for the provided number of retries on failure.
+func (i RegistryClient) PullImages(images []string, retries int) []error {
+	errs := []error{}
+	for _, image := range images {
+		logrus.Infof("Resolving image: %s ...", image)
+		err := withRetries(retries, func() error {
+			_, err := i.head(image)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "couldn't pull image: %v", image))
+		}
+	}
+	return errs
+}
+
+// PushImages copies each of the source images to the destination, including every platform of
+// multi-platform images unless Platforms is set. It will skip the operation if the image source and destination are equal.
+// It will retry for the provided number of retries on failure.
+func (i RegistryClient) PushImages(images []TagPair, retries int) []error {
+	errs := []error{}
+	for _, image := range images {
+		// Skip if the source/dest are equal
+		if image.Src == image.Dst {
+			fmt.Printf("Skipping public image: %s\n", image.Src)
+			continue
+		}
+
+		logrus.Infof("Copying image: %s to %s ...", image.Src, image.Dst)
+		err := withRetries(retries, func() error {
+			return i.copyImage(image.Src, image.Dst)
+		})
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "couldn't copy image %q to %q", image.Src, image.Dst))
+		}
+	}
+	return errs
+}
+
+func (i RegistryClient) copyImage(src, dst string) error {
+	srcRef, err := name.ParseReference(src)
+	if err != nil {
+		return errors.Wrapf(err, "invalid image reference %q", src)
+	}
+	dstRef, err := name.ParseReference(dst)
+	if err != nil {
+		return errors.Wrapf(err, "invalid image reference %q", dst)
+	}
+	desc, err := remote.Get(srcRef, i.options...)
+	if err != nil {
+		return err
+	}
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return err
+		}
+		return i.writeIndex(dstRef, idx)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return err
+	}
+	return i.writeImage(dstRef, img)
+}
+
+func (i RegistryClient) writeIndex(dst name.Reference, idx v1.ImageIndex) error {
+	if len(i.Platforms) > 0 {
+		var err error
+		if idx, err = filterPlatforms(idx, i.Platforms); err != nil {
+			return err
+		}
+	}
+	return remote.WriteIndex(dst, idx, i.options...)
+}
+
+func (i RegistryClient) writeImage(dst name.Reference, img v1.Image) error {
+	if len(i.Platforms) > 0 {
+		platform, err := imagePlatform(img)
+		if err != nil {
+			return err
+		}
+		if !matchesPlatforms(platform, i.Platforms) {
+			return errors.Errorf("image is only available for %v, not %v", platform, platformsString(i.Platforms))
+		}
+	}
+	return remote.Write(dst, img, i.options...)
+}
+
+// filterPlatforms removes the manifests which don't match any of the platforms from the index. Note
+// that this changes the digest of the index, but not of the images for each platform.
+func filterPlatforms(idx v1.ImageIndex, platforms []v1.Platform) (v1.ImageIndex, error) {
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, m := range manifest.Manifests {
+		if m.Platform != nil && matchesPlatforms(*m.Platform, platforms) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("index has none of the platforms %v", platformsString(platforms))
+	}
+	return mutate.RemoveManifests(idx, func(desc v1.Descriptor) bool {
+		return desc.Platform == nil || !matchesPlatforms(*desc.Platform, platforms)
+	}), nil
+}
+
+// DownloadImages saves the list of images to a tar file in the configured format. The provided
+// version will be included in the resulting file name.
+func (i RegistryClient) DownloadImages(images []string, version string) (string, error) {
+	fileName := getTarFileName(version)
+	validImages := []string{}
+	refs := []name.Reference{}
+	for _, image := range images {
+		// Mirrors the docker client, which skips the placeholders for images which can't be pulled.
+		if strings.HasPrefix(image, "invalid") {
+			continue
+		}
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid image reference %q", image)
+		}
+		validImages = append(validImages, image)
+		refs = append(refs, ref)
+	}
+
+	logrus.Info("Saving images: ...")
+	var err error
+	if i.ArchiveFormat == ArchiveFormatOCI {
+		err = i.saveLayout(validImages, fileName)
+	} else {
+		err = i.saveDockerArchive(refs, fileName)
+	}
+	if err != nil {
+		os.Remove(fileName)
+		return "", errors.Wrap(err, "couldn't save images to tar")
+	}
+	return fileName, nil
+}
+
+func (i RegistryClient) saveDockerArchive(refs []name.Reference, fileName string) error {
+	refToImage := map[name.Reference]v1.Image{}
+	for _, ref := range refs {
+		img, err := remote.Image(ref, i.options...)
+		if err != nil {
+			return errors.Wrapf(err, "fetching image %v", ref)
+		}
+		refToImage[ref] = img
+	}
+	return v1tarball.MultiRefWriteToFile(fileName, refToImage)
+}
+
+// saveLayout writes the images to an OCI image layout and tars it.
+func (i RegistryClient) saveLayout(images []string, fileName string) error {
+	dir, err := os.MkdirTemp("", "sonobuoy-images-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := i.WriteLayout(dir, images); err != nil {
+		return err
+	}
+	return tarball.DirToTarball(dir, fileName, false)
+}
+
+// WriteLayout writes the images to an OCI image layout in the given directory, annotated with their
+// references. Every platform of multi-platform images is saved. Returns the digest of each image.
+func (i RegistryClient) WriteLayout(dir string, images []string) (map[string]v1.Hash, error) {
+	p, err := layout.Write(dir, empty.Index)
+	if err != nil {
+		return nil, err
+	}
+	digests := map[string]v1.Hash{}
+	for _, image := range images {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid image reference %q", image)
+		}
+		annotations := layout.WithAnnotations(map[string]string{
+			refNameAnnotation: ref.Name(),
+		})
+		desc, err := remote.Get(ref, i.options...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetching image %v", ref)
+		}
+		if err := appendToLayout(p, desc, annotations); err != nil {
+			return nil, errors.Wrapf(err, "saving image %v", ref)
+		}
+		digests[image] = desc.Digest
+	}
+	return digests, nil
+}
+
+// PushFromLayout pushes images from the OCI image layout in the given directory, such as one
+// written by WriteLayout. The source of each pair is the reference the image is annotated with.
+// It will retry for the provided number of retries on failure.
+func (i RegistryClient) PushFromLayout(dir string, images []TagPair, retries int) []error {
+	p, err := layout.FromPath(dir)
+	if err != nil {
+		return []error{errors.Wrapf(err, "couldn't read image layout %v", dir)}
+	}
+	idx, err := p.ImageIndex()
+	if err != nil {
+		return []error{errors.Wrapf(err, "couldn't read image layout %v", dir)}
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return []error{errors.Wrapf(err, "couldn't read image layout %v", dir)}
+	}
+	descs := map[string]v1.Descriptor{}
+	for _, m := range manifest.Manifests {
+		descs[m.Annotations[refNameAnnotation]] = m
+	}
+
+	errs := []error{}
+	for _, image := range images {
+		logrus.Infof("Pushing image: %s to %s ...", image.Src, image.Dst)
+		err := withRetries(retries, func() error {
+			return i.pushFromLayout(p, descs, image)
+		})
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "couldn't push image %q to %q", image.Src, image.Dst))
+		}
+	}
+	return errs
+}
+
+func (i RegistryClient) pushFromLayout(p layout.Path, descs map[string]v1.Descriptor, image TagPair) error {
+	srcRef, err := name.ParseReference(image.Src)
+	if err != nil {
+		return errors.Wrapf(err, "invalid image reference %q", image.Src)
+	}
+	dstRef, err := name.ParseReference(image.Dst)
+	if err != nil {
+		return errors.Wrapf(err, "invalid image reference %q", image.Dst)
+	}
+	desc, ok := descs[srcRef.Name()]
+	if !ok {
+		return errors.New("image not found in layout")
+	}
+	if desc.MediaType.IsIndex() {
+		idx, err := p.ImageIndex()
+		if err != nil {
+			return err
+		}
+		child, err := idx.ImageIndex(desc.Digest)
+		if err != nil {
+			return err
+		}
+		return i.writeIndex(dstRef, child)
+	}
+	img, err := p.Image(desc.Digest)
+	if err != nil {
+		return err
+	}
+	return i.writeImage(dstRef, img)
+}
+
+func appendToLayout(p layout.Path, desc *remote.Descriptor, options ...layout.Option) error {
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return err
+		}
+		return p.AppendIndex(idx, options...)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return err
+	}
+	return p.AppendImage(img, options...)
+}
+
+// InspectImages fetches the manifest of each image to check that it is available in the registry.
+func (i RegistryClient) InspectImages(images []string) []error {
+	errs := []error{}
+	for _, image := range images {
+		desc, err := i.head(image)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "couldn't inspect image: %v", image))
+			continue
+		}
+		logrus.Debugf("Image: %s found in registry @%s (%s)", image, desc.Digest, desc.MediaType)
+	}
+	return errs
+}
+
+// ResolveDigest returns the digest the image currently refers to. For multi-platform images this
+// is the digest of the index so that every platform is pinned.
+func (i RegistryClient) ResolveDigest(image string) (string, error) {
+	desc, err := i.head(image)
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't resolve digest of image %v", image)
+	}
+	return desc.Digest.String(), nil
+}
+
+func (i RegistryClient) head(image string) (*v1.Descriptor, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid image reference %q", image)
+	}
+	return remote.Head(ref, i.options...)
+}
+
+// DeleteImages does nothing since images are never stored locally.
+func (i RegistryClient) DeleteImages(images []string, retries int) []error {
+	logrus.Info("Images are not stored locally when using the registry client; nothing to delete")
+	return []error{}
+}
+
+// RunImage runs the entrypoint of the image on this machine rather than in a container, if
+// AllowHostExecution is set. The executable is extracted from the image for this machine's
+// architecture and run without any isolation so this is only suitable for trusted,
+// self-contained binaries (e.g. e2e.test) on Linux.
+func (i RegistryClient) RunImage(image string, entryPoint string, env map[string]string, args ...string) ([]string, error) {
+	if !i.AllowHostExecution {
+		return []string{}, errors.Errorf("running image %v requires a container runtime; use the docker image client, or allow the registry client to run the image's entrypoint on this machine without isolation", image)
+	}
+	if runtime.GOOS != "linux" {
+		return []string{}, errors.Errorf("running images without docker is only supported on linux, not %v", runtime.GOOS)
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return []string{}, errors.Wrapf(err, "invalid image reference %q", image)
+	}
+	img, err := remote.Image(ref, i.options...)
+	if err != nil {
+		return []string{}, errors.Wrapf(err, "fetching image %v", image)
+	}
+
+	dir, err := os.MkdirTemp("", "sonobuoy-run-")
+	if err != nil {
+		return []string{}, err
+	}
+	defer os.RemoveAll(dir)
+	binary, err := extractEntrypoint(img, entryPoint, dir)
+	if err != nil {
+		return []string{}, errors.Wrapf(err, "extracting entrypoint of image %v", image)
+	}
+
+	// Like a container, the command gets the environment of the image along with the given values.
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return []string{}, err
+	}
+	cmd := exec.Command(binary, args...)
+	cmdEnv := append([]string{}, cfg.Config.Env...)
+	for k, v := range env {
+		cmdEnv = append(cmdEnv, fmt.Sprintf("%v=%v", k, v))
+	}
+	cmd.SetEnv(cmdEnv...)
+	return exec.CombinedOutputLines(cmd)
+}
+
+// extractEntrypoint writes the executable for the entrypoint (or that of the image if empty) into
+// dir and returns its path. Names without a slash are looked up using the PATH of the image.
+func extractEntrypoint(img v1.Image, entryPoint string, dir string) (string, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return "", err
+	}
+	if len(entryPoint) == 0 {
+		if len(cfg.Config.Entrypoint) == 0 {
+			return "", errors.New("no entrypoint given or set in the image")
+		}
+		entryPoint = cfg.Config.Entrypoint[0]
+	}
+
+	candidates := []string{}
+	if strings.Contains(entryPoint, "/") {
+		candidates = append(candidates, path.Clean("/"+entryPoint))
+	} else {
+		imagePath := defaultImagePath
+		for _, e := range cfg.Config.Env {
+			if strings.HasPrefix(e, "PATH=") {
+				imagePath = strings.TrimPrefix(e, "PATH=")
+			}
+		}
+		for _, d := range strings.Split(imagePath, ":") {
+			candidates = append(candidates, path.Join("/", d, entryPoint))
+		}
+	}
+
+	rc := mutate.Extract(img)
+	defer rc.Close()
+	found := map[string]string{}
+	tr := tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", errors.Wrap(err, "reading image filesystem")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		file := path.Clean("/" + header.Name)
+		for _, candidate := range candidates {
+			if file != candidate {
+				continue
+			}
+			out := filepath.Join(dir, fmt.Sprintf("%d-%s", len(found), path.Base(file)))
+			if err := writeExecutable(out, tr); err != nil {
+				return "", err
+			}
+			found[candidate] = out
+		}
+	}
+
+	// Respect the order of the PATH if there are several matches.
+	for _, candidate := range candidates {
+		if out, ok := found[candidate]; ok {
+			return out, nil
+		}
+	}
+	return "", errors.Errorf("executable %q not found in the image", entryPoint)
+}
+
+func writeExecutable(file string, r io.Reader) error {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// withRetries calls fn until it succeeds, retrying up to retries times with an increasing delay.
+func withRetries(retries int, fn func() error) error {
+	err := fn()
+	for i := 0; err != nil && i < retries; i++ {
+		logrus.Debugf("Retrying after error: %v", err)
+		time.Sleep(time.Second * time.Duration(i+1))
+		err = fn()
+	}
+	return err
+}
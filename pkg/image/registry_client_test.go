@@ -0,0 +1,367 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	v1tarball "github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// newTestRegistry starts an in-process registry and returns its host.
+func newTestRegistry(t *testing.T) string {
+	t.Helper()
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(s.Close)
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func newTestRegistryClient(t *testing.T, format string) RegistryClient {
+	t.Helper()
+	c, err := NewRegistryClient(format, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.(RegistryClient)
+}
+
+// pushRandomImage pushes a random image to the reference and returns its digest.
+func pushRandomImage(t *testing.T, image string) v1.Hash {
+	t.Helper()
+	img, err := random.Image(256, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img = withConfig(t, img, func(cfg *v1.ConfigFile) {
+		cfg.OS, cfg.Architecture = "linux", runtime.GOARCH
+	})
+	if err := remote.Write(mustParseReference(t, image), img); err != nil {
+		t.Fatal(err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// pushRandomIndex pushes a random index to the reference and returns its digest.
+func pushRandomIndex(t *testing.T, image string) v1.Hash {
+	t.Helper()
+	idx, err := random.Index(256, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(mustParseReference(t, image), idx); err != nil {
+		t.Fatal(err)
+	}
+	d, err := idx.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// withConfig returns the image with its config modified; the layers are kept.
+func withConfig(t *testing.T, img v1.Image, modify func(*v1.ConfigFile)) v1.Image {
+	t.Helper()
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = cfg.DeepCopy()
+	modify(cfg)
+	img, err = mutate.ConfigFile(img, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func mustParseReference(t *testing.T, image string) name.Reference {
+	t.Helper()
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func remoteDigest(t *testing.T, image string) v1.Hash {
+	t.Helper()
+	desc, err := remote.Head(mustParseReference(t, image))
+	if err != nil {
+		t.Fatalf("Expected %v to exist: %v", image, err)
+	}
+	return desc.Digest
+}
+
+func TestNewRegistryClient(t *testing.T) {
+	if c := newTestRegistryClient(t, ""); c.ArchiveFormat != ArchiveFormatDocker {
+		t.Errorf("Expected default format %v but got %v", ArchiveFormatDocker, c.ArchiveFormat)
+	}
+	if _, err := NewRegistryClient("zip", nil); err == nil {
+		t.Error("Expected error for unknown archive format")
+	}
+}
+
+func TestRegistryClientPushImages(t *testing.T) {
+	src, dst := newTestRegistry(t), newTestRegistry(t)
+	imgDigest := pushRandomImage(t, src+"/sonobuoy/sonobuoy:v1")
+	idxDigest := pushRandomIndex(t, src+"/conformance:v1.27.1")
+
+	c := newTestRegistryClient(t, "")
+	errs := c.PushImages([]TagPair{
+		{Src: src + "/sonobuoy/sonobuoy:v1", Dst: dst + "/sonobuoy:v1"},
+		{Src: src + "/conformance:v1.27.1", Dst: dst + "/conformance:v1.27.1"},
+		{Src: src + "/same:v1", Dst: src + "/same:v1"},
+	}, 0)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if got := remoteDigest(t, dst+"/sonobuoy:v1"); got != imgDigest {
+		t.Errorf("Expected image digest %v but got %v", imgDigest, got)
+	}
+	if got := remoteDigest(t, dst+"/conformance:v1.27.1"); got != idxDigest {
+		t.Errorf("Expected the whole index to be copied with digest %v but got %v", idxDigest, got)
+	}
+
+	errs = c.PushImages([]TagPair{{Src: src + "/missing:v1", Dst: dst + "/missing:v1"}}, 0)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "missing") {
+		t.Errorf("Expected an error copying a missing image but got %v", errs)
+	}
+}
+
+func TestRegistryClientPullAndInspectImages(t *testing.T) {
+	host := newTestRegistry(t)
+	pushRandomImage(t, host+"/present:v1")
+	images := []string{host + "/present:v1", host + "/absent:v1"}
+
+	c := newTestRegistryClient(t, "")
+	for desc, errs := range map[string][]error{
+		"pull":    c.PullImages(images, 0),
+		"inspect": c.InspectImages(images),
+	} {
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), "absent") {
+			t.Errorf("Expected %v to only fail for the absent image but got %v", desc, errs)
+		}
+	}
+}
+
+func TestRegistryClientDownloadImages(t *testing.T) {
+	host := newTestRegistry(t)
+	imgDigest := pushRandomImage(t, host+"/sonobuoy:v1")
+	idxDigest := pushRandomIndex(t, host+"/conformance:v1")
+
+	t.Run("docker archive", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		c := newTestRegistryClient(t, ArchiveFormatDocker)
+		file, err := c.DownloadImages([]string{host + "/sonobuoy:v1", "invalid.registry/skipped:v1"}, "v1.27.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file != "kubernetes_e2e_images_v1.27.1.tar" {
+			t.Errorf("Unexpected file name %v", file)
+		}
+		tag := mustParseReference(t, host+"/sonobuoy:v1").(name.Tag)
+		img, err := v1tarball.ImageFromPath(file, &tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d, err := img.Digest(); err != nil || d != imgDigest {
+			t.Errorf("Expected image with digest %v but got %v (%v)", imgDigest, d, err)
+		}
+	})
+
+	t.Run("oci layout", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		c := newTestRegistryClient(t, ArchiveFormatOCI)
+		file, err := c.DownloadImages([]string{host + "/sonobuoy:v1", host + "/conformance:v1"}, "v1.27.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := readTarFile(t, file, "index.json")
+		index := v1.IndexManifest{}
+		if err := json.Unmarshal(b, &index); err != nil {
+			t.Fatal(err)
+		}
+		refs := map[string]v1.Hash{}
+		for _, m := range index.Manifests {
+			refs[m.Annotations["org.opencontainers.image.ref.name"]] = m.Digest
+		}
+		expect := map[string]v1.Hash{
+			host + "/sonobuoy:v1":    imgDigest,
+			host + "/conformance:v1": idxDigest,
+		}
+		for ref, d := range expect {
+			if refs[ref] != d {
+				t.Errorf("Expected %v with digest %v in the layout but got %v", ref, d, refs)
+			}
+		}
+	})
+
+	t.Run("failures remove the file", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		c := newTestRegistryClient(t, ArchiveFormatOCI)
+		file, err := c.DownloadImages([]string{host + "/absent:v1"}, "v1.27.1")
+		if err == nil {
+			t.Fatalf("Expected an error but got file %v", file)
+		}
+		if _, err := os.Stat(getTarFileName("v1.27.1")); !os.IsNotExist(err) {
+			t.Errorf("Expected no tarball to be left behind but got %v", err)
+		}
+	})
+}
+
+func TestRegistryClientRunImage(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("running images without docker is only supported on linux")
+	}
+	host := newTestRegistry(t)
+	script := "#!/bin/sh\necho \"$GREETING $IMAGE_VAR\"\nfor a in \"$@\"; do echo \"$a\"; done\n"
+	layer, err := v1tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, f := range []struct{ name, contents string }{
+			{"usr/local/bin/list-images", script},
+			{"usr/bin/list-images", "#!/bin/sh\necho wrong binary\n"},
+		} {
+			if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0755, Size: int64(len(f.contents)), Typeflag: tar.TypeReg}); err != nil {
+				return nil, err
+			}
+			if _, err := tw.Write([]byte(f.contents)); err != nil {
+				return nil, err
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(&buf), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img = withConfig(t, img, func(cfg *v1.ConfigFile) {
+		cfg.OS, cfg.Architecture = "linux", runtime.GOARCH
+		cfg.Config.Env = []string{"PATH=/usr/local/bin:/usr/bin", "IMAGE_VAR=from-image"}
+	})
+	if err := remote.Write(mustParseReference(t, host+"/conformance:v1"), img); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestRegistryClient(t, "")
+	if _, err := c.RunImage(host+"/conformance:v1", "list-images", nil); err == nil || !strings.Contains(err.Error(), "requires a container runtime") {
+		t.Fatalf("Expected running images on the host to require opting in but got %v", err)
+	}
+
+	c.AllowHostExecution = true
+	lines, err := c.RunImage(host+"/conformance:v1", "list-images", map[string]string{"GREETING": "hello"}, "--list-images")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v (output %v)", err, lines)
+	}
+	expect := []string{"hello from-image", "--list-images"}
+	if strings.Join(lines, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("Expected %q but got %q", expect, lines)
+	}
+
+	if _, err := c.RunImage(host+"/conformance:v1", "missing", nil); err == nil {
+		t.Error("Expected an error for a missing entrypoint")
+	}
+}
+
+func readTarFile(t *testing.T, file, want string) []byte {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimPrefix(h.Name, "./") == want {
+			b, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return b
+		}
+	}
+	t.Fatalf("%v not found in %v", want, file)
+	return nil
+}
+
+func TestRegistryClientLayout(t *testing.T) {
+	src, dst := newTestRegistry(t), newTestRegistry(t)
+	imgDigest := pushRandomImage(t, src+"/sonobuoy:v1")
+	idxDigest := pushRandomIndex(t, src+"/conformance:v1")
+	dir := t.TempDir()
+
+	c := newTestRegistryClient(t, "")
+	digests, err := c.WriteLayout(dir, []string{src + "/sonobuoy:v1", src + "/conformance:v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digests[src+"/sonobuoy:v1"] != imgDigest || digests[src+"/conformance:v1"] != idxDigest {
+		t.Errorf("Unexpected digests %v", digests)
+	}
+
+	errs := c.PushFromLayout(dir, []TagPair{
+		{Src: src + "/sonobuoy:v1", Dst: dst + "/mirror/sonobuoy:v1"},
+		{Src: src + "/conformance:v1", Dst: dst + "/mirror/conformance:v1"},
+	}, 0)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if got := remoteDigest(t, dst+"/mirror/sonobuoy:v1"); got != imgDigest {
+		t.Errorf("Expected image digest %v but got %v", imgDigest, got)
+	}
+	if got := remoteDigest(t, dst+"/mirror/conformance:v1"); got != idxDigest {
+		t.Errorf("Expected index digest %v but got %v", idxDigest, got)
+	}
+
+	errs = c.PushFromLayout(dir, []TagPair{{Src: src + "/missing:v1", Dst: dst + "/missing:v1"}}, 0)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "not found in layout") {
+		t.Errorf("Expected an error for an image missing from the layout but got %v", errs)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"net/http"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -79,16 +80,23 @@ func Run(client kubernetes.Interface, plugins []plugin.Interface, cfg plugin.Agg
 	}
 
 	// Get a list of nodes so the plugins can properly estimate what
-	// results they'll give.
+	// results they'll give. Only daemonsets depend on the nodes so avoid
+	// the cluster-scoped query otherwise (e.g. namespace-scoped runs).
 	// TODO: there are other places that iterate through the CoreV1.Nodes API
 	// call, we should only do this in one place and cache it.
-	nodes, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-	switch {
-	case apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err):
-		logrus.Warningf("Unauthorized to query cluster nodes; continuing with empty node list. Daemonset plugins will be ignored as a result.")
-		nodes = &corev1.NodeList{}
-	case err != nil:
-		return errors.WithStack(err)
+	nodes := &corev1.NodeList{}
+	if needsNodes(plugins) {
+		var err error
+		nodes, err = client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+		switch {
+		case apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err):
+			logrus.Warningf("Unauthorized to query cluster nodes; continuing with empty node list. Daemonset plugins will be ignored as a result.")
+			nodes = &corev1.NodeList{}
+		case err != nil:
+			return errors.WithStack(err)
+		}
+	} else {
+		logrus.Info("Skipping node list: no daemonset plugins defined")
 	}
 
 	// Find out what results we should expect for each of the plugins
@@ -272,6 +280,17 @@ func Cleanup(client kubernetes.Interface, plugins []plugin.Interface) {
 	}
 }
 
+// needsNodes returns true if any of the plugins run per-node and so need the list of nodes.
+func needsNodes(plugins []plugin.Interface) bool {
+	for _, p := range plugins {
+		d, ok := p.(interface{ GetDriver() string })
+		if !ok || strings.EqualFold(d.GetDriver(), "daemonset") {
+			return true
+		}
+	}
+	return false
+}
+
 // RunAndMonitorPlugin will start a plugin then monitor it for errors starting/running.
 // Errors detected will be handled by saving an error result in the aggregator.Results.
 func (a *Aggregator) RunAndMonitorPlugin(ctx context.Context, timeout time.Duration, p plugin.Interface, client kubernetes.Interface, nodes []corev1.Node, address string, cert *tls.Certificate, aggregatorPod *corev1.Pod, progressPort, pluginResultDir string) {
@@ -405,3 +405,38 @@ func TestCleanup(t *testing.T) {
 		})
 	}
 }
+
+func TestNeedsNodes(t *testing.T) {
+	withDriver := func(name, driverName string) plugin.Interface {
+		return &job.Plugin{
+			Base: driver.Base{
+				Definition: manifest.Manifest{
+					SonobuoyConfig: manifest.SonobuoyConfig{PluginName: name, Driver: driverName},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc    string
+		plugins []plugin.Interface
+		expect  bool
+	}{
+		{
+			desc:    "Only jobs",
+			plugins: []plugin.Interface{withDriver("a", "Job"), withDriver("b", "job")},
+			expect:  false,
+		}, {
+			desc:    "Any daemonset",
+			plugins: []plugin.Interface{withDriver("a", "Job"), withDriver("b", "DaemonSet")},
+			expect:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if out := needsNodes(tc.plugins); out != tc.expect {
+				t.Errorf("Expected %v but got %v", tc.expect, out)
+			}
+		})
+	}
+}
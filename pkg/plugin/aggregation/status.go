@@ -18,8 +18,12 @@ package aggregation
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin"
@@ -73,6 +77,32 @@ type TarInfo struct {
 	Size      int64     `json:"size"`
 }
 
+// NewTarInfo returns the TarInfo describing the tarball at the given path.
+func NewTarInfo(path string) (TarInfo, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return TarInfo{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return TarInfo{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return TarInfo{}, err
+	}
+
+	return TarInfo{
+		Name:      filepath.Base(path),
+		Size:      fi.Size(),
+		SHA256:    fmt.Sprintf("%x", h.Sum(nil)),
+		CreatedAt: time.Now(),
+	}, nil
+}
+
 // Key returns a unique identifier for the plugin that these status values
 // correspond to.
 func (p PluginStatus) Key() string {
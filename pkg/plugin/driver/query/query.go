@@ -0,0 +1,214 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/utils"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+	"github.com/vmware-tanzu/sonobuoy/pkg/tarball"
+)
+
+const (
+	// DriverName is the name of the driver used in plugin definitions.
+	DriverName = "Query"
+
+	// ResultsFile is the file written by the runner which summarizes the queries. It is post-processed
+	// using the manual result format unless the plugin specifies otherwise.
+	ResultsFile = "query_results.yaml"
+
+	gzipMimeType = "application/gzip"
+)
+
+// Runner queries the cluster using the given filters, writing the results into outdir.
+type Runner func(spec manifest.QuerySpec, outdir string) error
+
+// Plugin is a plugin driver which queries the cluster from within the aggregator rather than
+// dispatching pods. The results are submitted like those of any other plugin.
+type Plugin struct {
+	driver.Base
+
+	// Runner performs the queries. It is set by the aggregator since it depends on the config of
+	// the run; the plugin fails to run without it.
+	Runner Runner `json:"-"`
+
+	mu   sync.Mutex
+	dir  string
+	body *os.File
+	done chan *plugin.Result
+}
+
+// Ensure Plugin implements plugin.Interface
+var _ plugin.Interface = &Plugin{}
+
+// NewPlugin creates a new Query plugin from the given Plugin Definition. Unless the definition
+// says otherwise, the summary of the queries is post-processed as the results of the plugin.
+func NewPlugin(dfn manifest.Manifest, namespace string) *Plugin {
+	if len(dfn.SonobuoyConfig.ResultFormat) == 0 {
+		dfn.SonobuoyConfig.ResultFormat = "manual"
+		if len(dfn.SonobuoyConfig.ResultFiles) == 0 {
+			dfn.SonobuoyConfig.ResultFiles = []string{ResultsFile}
+		}
+	}
+	return &Plugin{
+		Base: driver.Base{
+			Definition: dfn,
+			SessionID:  utils.GetSessionID(),
+			Namespace:  namespace,
+			CleanedUp:  false, // be explicit
+		},
+	}
+}
+
+// ExpectedResults returns the list of results expected for this plugin. The cluster is queried
+// once so only a single, global result is expected.
+func (p *Plugin) ExpectedResults(nodes []v1.Node) []plugin.ExpectedResult {
+	return []plugin.ExpectedResult{
+		{
+			ResultType: p.GetName(),
+			NodeName:   plugin.GlobalResult,
+			Order:      p.Definition.SonobuoyConfig.Order,
+		},
+	}
+}
+
+// Spec returns the filters of the plugin.
+func (p *Plugin) Spec() manifest.QuerySpec {
+	if p.Definition.Query == nil {
+		return manifest.QuerySpec{}
+	}
+	return *p.Definition.Query.DeepCopy()
+}
+
+// Run starts querying the cluster in the background. The other arguments are only needed by
+// plugins which run in pods and are ignored.
+func (p *Plugin) Run(_ kubernetes.Interface, _ string, _ *tls.Certificate, _ *v1.Pod, _, _ string) error {
+	if p.Runner == nil {
+		return errors.Errorf("no query runner configured for plugin %v", p.GetName())
+	}
+
+	dir, err := os.MkdirTemp("", "sonobuoy-query-")
+	if err != nil {
+		return errors.Wrapf(err, "couldn't create directory for Query plugin %v", p.GetName())
+	}
+
+	p.mu.Lock()
+	p.dir = dir
+	p.done = make(chan *plugin.Result, 1)
+	p.mu.Unlock()
+
+	go func() {
+		p.done <- p.query(dir)
+	}()
+	return nil
+}
+
+// query runs the queries and returns the result to submit: either the gzipped results or an error.
+func (p *Plugin) query(dir string) *plugin.Result {
+	outdir := filepath.Join(dir, "results")
+	logrus.WithField("plugin", p.GetName()).Info("Querying cluster")
+	if err := p.Runner(p.Spec(), outdir); err != nil {
+		return utils.MakeErrorResult(p.GetName(), map[string]interface{}{"error": err.Error()}, plugin.GlobalResult)
+	}
+
+	tb := filepath.Join(dir, "results.tar.gz")
+	if err := tarball.DirToTarball(outdir, tb, true); err != nil {
+		return utils.MakeErrorResult(p.GetName(), map[string]interface{}{"error": err.Error()}, plugin.GlobalResult)
+	}
+	f, err := os.Open(tb)
+	if err != nil {
+		return utils.MakeErrorResult(p.GetName(), map[string]interface{}{"error": err.Error()}, plugin.GlobalResult)
+	}
+
+	// Closed during cleanup since the aggregator reads it asynchronously.
+	p.mu.Lock()
+	p.body = f
+	p.mu.Unlock()
+	return &plugin.Result{
+		ResultType: p.GetName(),
+		NodeName:   plugin.GlobalResult,
+		MimeType:   gzipMimeType,
+		Body:       f,
+	}
+}
+
+// Monitor adheres to plugin.Interface by waiting for the queries to complete and sending their
+// results. It closes the results channel when it is done.
+func (p *Plugin) Monitor(ctx context.Context, _ kubernetes.Interface, _ []v1.Node, resultsCh chan<- *plugin.Result) {
+	defer close(resultsCh)
+
+	p.mu.Lock()
+	done := p.done
+	p.mu.Unlock()
+	if done == nil {
+		// Never started; the error has already been reported.
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			logrus.Errorf("Timeout waiting for plugin %v. Try increasing the aggregation timeout or narrowing the queries.", p.GetName())
+			resultsCh <- utils.MakeErrorResult(
+				p.GetName(),
+				map[string]interface{}{"error": plugin.TimeoutErrMsg},
+				plugin.GlobalResult,
+			)
+		case ctx.Err() == context.Canceled:
+			// Do nothing, just stop.
+		case ctx.Err() != nil:
+			logrus.Errorf("Error seen while monitoring plugin %v: %v", p.GetName(), ctx.Err().Error())
+			resultsCh <- utils.MakeErrorResult(
+				p.GetName(),
+				map[string]interface{}{"error": ctx.Err().Error()},
+				plugin.GlobalResult,
+			)
+		}
+	case result := <-done:
+		resultsCh <- result
+	}
+}
+
+// Cleanup removes the temporary results of the queries. No cluster resources are created.
+func (p *Plugin) Cleanup(_ kubernetes.Interface) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.CleanedUp = true
+	if p.body != nil {
+		p.body.Close()
+		p.body = nil
+	}
+	if len(p.dir) > 0 {
+		if err := os.RemoveAll(p.dir); err != nil {
+			logrus.Errorf("Failed to remove results of Query plugin %v: %v", p.GetName(), err)
+		}
+		p.dir = ""
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+	"github.com/vmware-tanzu/sonobuoy/pkg/tarball"
+)
+
+func TestRunAndMonitor(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		runner       Runner
+		expectRunErr bool
+		expectErr    string
+		expectFile   string
+	}{
+		{
+			desc: "Results are submitted as an archive",
+			runner: func(spec manifest.QuerySpec, outdir string) error {
+				if err := os.MkdirAll(filepath.Join(outdir, "resources"), 0755); err != nil {
+					return err
+				}
+				return os.WriteFile(filepath.Join(outdir, "resources", "ns.json"), []byte(spec.Namespaces), 0644)
+			},
+			expectFile: "resources/ns.json",
+		}, {
+			desc: "Runner errors are reported",
+			runner: func(manifest.QuerySpec, string) error {
+				return errors.New("forbidden")
+			},
+			expectErr: "forbidden",
+		}, {
+			desc:         "Missing runner fails to run",
+			expectRunErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := NewPlugin(manifest.Manifest{
+				SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "snapshot", Driver: DriverName},
+				Query:          &manifest.QuerySpec{Namespaces: "^default$"},
+			}, "sonobuoy")
+			p.Runner = tc.runner
+			defer p.Cleanup(nil)
+
+			err := p.Run(nil, "", nil, nil, "", "")
+			if (err != nil) != tc.expectRunErr {
+				t.Fatalf("Expected run error %v but got %v", tc.expectRunErr, err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			resultsCh := make(chan *plugin.Result, 1)
+			go p.Monitor(ctx, nil, nil, resultsCh)
+
+			result, ok := <-resultsCh
+			if tc.expectRunErr {
+				if ok {
+					t.Fatalf("Expected no results but got %+v", result)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("Expected a result")
+			}
+			if result.ResultType != "snapshot" || result.NodeName != plugin.GlobalResult {
+				t.Errorf("Unexpected result %v/%v", result.ResultType, result.NodeName)
+			}
+			if result.Error != tc.expectErr {
+				t.Fatalf("Expected error %q but got %q", tc.expectErr, result.Error)
+			}
+			if len(tc.expectErr) > 0 {
+				return
+			}
+
+			dir := t.TempDir()
+			if err := tarball.DecodeTarball(result.Body, dir); err != nil {
+				t.Fatal(err)
+			}
+			b, err := os.ReadFile(filepath.Join(dir, tc.expectFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != "^default$" {
+				t.Errorf("Expected the runner to get the spec of the plugin but got %q", b)
+			}
+		})
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	p := NewPlugin(manifest.Manifest{SonobuoyConfig: manifest.SonobuoyConfig{PluginName: "snapshot"}}, "sonobuoy")
+	p.Runner = func(manifest.QuerySpec, string) error { return nil }
+	if err := p.Run(nil, "", nil, nil, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	dir := p.dir
+	<-p.done
+
+	p.Cleanup(nil)
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %v to be removed but got %v", dir, err)
+	}
+	if !p.CleanedUp {
+		t.Error("Expected plugin to be marked as cleaned up")
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lint checks plugin definitions for mistakes which would otherwise only surface once the
+// plugin is running in the cluster, or which would be silently ignored.
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	kuberuntime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// Severity is how serious a problem is. Errors make the plugin unusable; warnings are likely
+// mistakes.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+
+	// resultsVolume is the volume which Sonobuoy adds to plugin pods for the worker to read results from.
+	resultsVolume = "results"
+
+	// workerContainer is the name of the container Sonobuoy adds to plugin pods.
+	workerContainer = "sonobuoy-worker"
+)
+
+var (
+	// pluginNameRegexp matches the plugin names which are valid Kubernetes subdomain names.
+	pluginNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+	yamlLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+	drivers = []string{"Job", "DaemonSet", "Query"}
+)
+
+// Diagnostic is a single problem found in a plugin definition.
+type Diagnostic struct {
+	// Line and Column of the problem, starting at 1. They are 0 if the position is unknown.
+	Line   int
+	Column int
+
+	// Path of the field with the problem, e.g. sonobuoy-config.driver.
+	Path string
+
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	msg := d.Message
+	if len(d.Path) > 0 {
+		msg = d.Path + ": " + msg
+	}
+	return fmt.Sprintf("%v:%v: %v: %v", d.Line, d.Column, d.Severity, msg)
+}
+
+// HasErrors returns true if any of the diagnostics is an error.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks the plugin definition against the plugin schema and for problems which the schema
+// can't describe, such as unknown drivers or a missing results volume. Diagnostics are returned
+// in the order they appear in the definition.
+func Lint(data []byte) []Diagnostic {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Diagnostic{syntaxError(err)}
+	}
+	if len(doc.Content) == 0 {
+		return []Diagnostic{{Line: 1, Column: 1, Severity: SeverityError, Message: "plugin definition is empty"}}
+	}
+
+	v := &validator{root: PluginSchema(), positions: map[string]position{}}
+	v.validate(doc.Content[0], v.root, "")
+	if !HasErrors(v.diags) {
+		var m manifest.Manifest
+		if err := kuberuntime.DecodeInto(manifest.Decoder, data, &m); err != nil {
+			v.diags = append(v.diags, Diagnostic{Line: 1, Column: 1, Severity: SeverityError, Message: err.Error()})
+		} else {
+			c := &checker{validator: v}
+			c.check(&m)
+		}
+	}
+
+	sort.SliceStable(v.diags, func(i, j int) bool {
+		if v.diags[i].Line != v.diags[j].Line {
+			return v.diags[i].Line < v.diags[j].Line
+		}
+		return v.diags[i].Column < v.diags[j].Column
+	})
+	return v.diags
+}
+
+// syntaxError returns the diagnostic for YAML which couldn't be parsed, using the line number from
+// the parser's message if there is one.
+func syntaxError(err error) Diagnostic {
+	d := Diagnostic{Severity: SeverityError, Message: err.Error()}
+	if m := yamlLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+	}
+	return d
+}
+
+// checker finds problems in the decoded plugin, reporting them at the position of the field.
+type checker struct {
+	*validator
+}
+
+func (c *checker) report(severity Severity, path, format string, args ...interface{}) {
+	d := Diagnostic{Path: path, Severity: severity, Message: fmt.Sprintf(format, args...)}
+	// Problems with fields which aren't set are reported at the closest parent which is.
+	for p := path; ; {
+		if pos, ok := c.positions[p]; ok {
+			d.Line, d.Column = pos.line, pos.column
+			break
+		}
+		i := strings.LastIndexAny(p, ".[")
+		if i < 0 {
+			if len(p) == 0 {
+				break
+			}
+			p = ""
+			continue
+		}
+		p = p[:i]
+	}
+	c.diags = append(c.diags, d)
+}
+
+func (c *checker) check(m *manifest.Manifest) {
+	cfg := m.SonobuoyConfig
+	if !pluginNameRegexp.MatchString(cfg.PluginName) {
+		c.report(SeverityError, "sonobuoy-config.plugin-name", "invalid plugin name %q; name must only include lowercase alphanumeric values '.' or '-'", cfg.PluginName)
+	}
+	for i, f := range cfg.ResultFiles {
+		if len(f) == 0 || strings.ContainsAny(f, `/\`) {
+			c.report(SeverityError, fmt.Sprintf("sonobuoy-config.result-files[%v]", i), "result files are matched by file name so %q will never match; remove any directories", f)
+		}
+	}
+
+	driver := ""
+	for _, d := range drivers {
+		if strings.EqualFold(cfg.Driver, d) {
+			driver = d
+		}
+	}
+	switch driver {
+	case "":
+		c.report(SeverityError, "sonobuoy-config.driver", "unknown driver %q; must be one of %v", cfg.Driver, strings.Join(drivers, ", "))
+	case "Query":
+		return
+	}
+	if m.Query != nil {
+		c.report(SeverityWarning, "query", "query is ignored unless the driver is Query")
+	}
+	c.checkPod(m)
+}
+
+// checkPod checks the containers and volumes of the pods of Job and DaemonSet plugins.
+func (c *checker) checkPod(m *manifest.Manifest) {
+	if len(m.Spec.Image) == 0 {
+		c.report(SeverityError, "spec.image", "the plugin container must have an image")
+	}
+	if len(m.Spec.Name) == 0 {
+		c.report(SeverityError, "spec.name", "the plugin container must have a name")
+	}
+
+	names := map[string]string{workerContainer: "the Sonobuoy worker", m.Spec.Name: "spec.name"}
+	if m.PodSpec != nil {
+		containers := []struct {
+			field string
+			list  []corev1.Container
+		}{
+			{"podSpec.initContainers", m.PodSpec.InitContainers},
+			{"podSpec.containers", m.PodSpec.Containers},
+		}
+		for _, cs := range containers {
+			for i, container := range cs.list {
+				path := fmt.Sprintf("%v[%v].name", cs.field, i)
+				switch other, ok := names[container.Name]; {
+				case len(container.Name) == 0:
+					c.report(SeverityError, path, "containers must have a name")
+				case ok:
+					c.report(SeverityError, path, "container name %q is already used by %v", container.Name, other)
+				default:
+					names[container.Name] = path
+				}
+			}
+		}
+
+		switch m.PodSpec.RestartPolicy {
+		case "", corev1.RestartPolicyAlways, corev1.RestartPolicyOnFailure, corev1.RestartPolicyNever:
+		default:
+			c.report(SeverityError, "podSpec.restartPolicy", "invalid restart policy %q; must be one of Always, OnFailure, Never", m.PodSpec.RestartPolicy)
+		}
+		for i, vol := range m.PodSpec.Volumes {
+			if vol.Name == resultsVolume {
+				c.report(SeverityError, fmt.Sprintf("podSpec.volumes[%v].name", i), "the %q volume is added by Sonobuoy; use another name", resultsVolume)
+			}
+		}
+	}
+	for i, vol := range m.ExtraVolumes {
+		if vol.Name == resultsVolume {
+			c.report(SeverityError, fmt.Sprintf("extra-volumes[%v].name", i), "the %q volume is added by Sonobuoy; use another name", resultsVolume)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		input  string
+		expect []string
+	}{
+		{
+			desc: "Valid plugin",
+			input: `sonobuoy-config:
+  driver: Job
+  plugin-name: valid
+  result-format: junit
+  result-files: [report.xml]
+spec:
+  image: example.com/valid:v1
+  name: plugin
+  resources:
+    limits:
+      cpu: 100m
+      memory: 1
+  volumeMounts:
+  - mountPath: /tmp/sonobuoy/results
+    name: results
+`,
+			expect: []string{},
+		}, {
+			desc: "Valid query plugin",
+			input: `sonobuoy-config:
+  driver: query
+  plugin-name: snapshot
+query:
+  resources: [pods]
+`,
+			expect: []string{},
+		}, {
+			desc:   "Syntax error",
+			input:  "sonobuoy-config:\n  driver: Job\n plugin-name: x\n",
+			expect: []string{"2:0: error: yaml: line 2: did not find expected key"},
+		}, {
+			desc:   "Empty",
+			input:  "",
+			expect: []string{"1:1: error: plugin definition is empty"},
+		}, {
+			desc: "Unknown fields with suggestions",
+			input: `sonobuoy-config:
+  driver: Job
+  plugin-name: typo
+  result-type: junit
+  skip-cleanpu: true
+spec:
+  image: example.com/typo:v1
+  name: plugin
+  volumeMount:
+  - mountPath: /tmp/sonobuoy/results
+    name: results
+  volumes: []
+`,
+			expect: []string{
+				`4:3: error: sonobuoy-config.result-type: unknown field "result-type"; did you mean "result-format"?`,
+				`5:3: error: sonobuoy-config.skip-cleanpu: unknown field "skip-cleanpu"; did you mean "skip-cleanup"?`,
+				`9:3: error: spec.volumeMount: unknown field "volumeMount"; did you mean "volumeMounts"?`,
+				`12:3: error: spec.volumes: unknown field "volumes"`,
+			},
+		}, {
+			desc: "Types, enums and required fields",
+			input: `sonobuoy-config:
+  plugin-name: types
+  result-format: xunit
+  result-format: raw
+spec:
+  image: example.com/types:v1
+  name: plugin
+  command: /run.sh
+  ports:
+  - containerPort: http
+podSpec:
+  hostNetwork: "true"
+`,
+			expect: []string{
+				`2:3: error: sonobuoy-config.driver: missing required field "driver"`,
+				`3:18: error: sonobuoy-config.result-format: invalid value "xunit"; must be one of junit, e2e, gojson, raw, manual`,
+				`4:3: error: sonobuoy-config.result-format: duplicate field "result-format"`,
+				`8:12: error: spec.command: expected array but got string`,
+				`10:20: error: spec.ports[0].containerPort: expected integer but got string`,
+				`12:16: error: podSpec.hostNetwork: expected boolean but got string`,
+			},
+		}, {
+			desc: "Plugin problems",
+			input: `sonobuoy-config:
+  driver: Jobs
+  plugin-name: Problems
+  result-files: [results/report.xml]
+spec:
+  image: example.com/problems:v1
+query:
+  resources: [pods]
+`,
+			expect: []string{
+				`2:11: error: sonobuoy-config.driver: unknown driver "Jobs"; must be one of Job, DaemonSet, Query`,
+				`3:16: error: sonobuoy-config.plugin-name: invalid plugin name "Problems"; name must only include lowercase alphanumeric values '.' or '-'`,
+				`4:18: error: sonobuoy-config.result-files[0]: result files are matched by file name so "results/report.xml" will never match; remove any directories`,
+				`6:3: error: spec.name: the plugin container must have a name`,
+				`8:3: warning: query: query is ignored unless the driver is Query`,
+			},
+		}, {
+			desc: "Pod problems",
+			input: `sonobuoy-config:
+  driver: DaemonSet
+  plugin-name: pod
+spec:
+  image: example.com/pod:v1
+  name: plugin
+podSpec:
+  restartPolicy: Sometimes
+  containers:
+  - name: plugin
+    image: example.com/sidecar:v1
+    volumeMounts:
+    - mountPath: /results
+      name: results
+  - image: example.com/other:v1
+  volumes:
+  - name: results
+    emptyDir: {}
+`,
+			expect: []string{
+				`8:18: error: podSpec.restartPolicy: invalid restart policy "Sometimes"; must be one of Always, OnFailure, Never`,
+				`10:11: error: podSpec.containers[0].name: container name "plugin" is already used by spec.name`,
+				`15:5: error: podSpec.containers[1].name: containers must have a name`,
+				`17:11: error: podSpec.volumes[0].name: the "results" volume is added by Sonobuoy; use another name`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			output := []string{}
+			for _, d := range Lint([]byte(tc.input)) {
+				output = append(output, d.String())
+			}
+			if diff := pretty.Compare(tc.expect, output); diff != "" {
+				t.Errorf("Unexpected diagnostics (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestPluginSchema(t *testing.T) {
+	b, err := json.Marshal(PluginSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s["$schema"] != schemaDraft || s["additionalProperties"] != false {
+		t.Errorf("Expected root to be a strict draft 2020-12 schema but got %v, %v", s["$schema"], s["additionalProperties"])
+	}
+	defs := s["$defs"].(map[string]interface{})
+	cfg := defs["manifest.SonobuoyConfig"].(map[string]interface{})
+	if cfg["additionalProperties"] != false {
+		t.Errorf("Expected sonobuoy-config to forbid unknown fields")
+	}
+	if !strings.Contains(string(b), `"result-format":{"type":"string","enum":["junit","e2e","gojson","raw","manual"]}`) {
+		t.Errorf("Expected result-format to be an enum")
+	}
+	for _, def := range []string{"manifest.Container", "v1.Container", "manifest.PodSpec", "v1.VolumeMount"} {
+		if _, ok := defs[def]; !ok {
+			t.Errorf("Expected definition %v", def)
+		}
+	}
+	if !strings.Contains(string(b), `"limits":{"type":"object","additionalProperties":{"type":["string","number"]}}`) {
+		t.Errorf("Expected resource quantities to allow strings and numbers")
+	}
+}
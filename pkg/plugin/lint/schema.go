@@ -0,0 +1,219 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+	schemaTitle = "Sonobuoy plugin definition"
+
+	typeObject  = "object"
+	typeArray   = "array"
+	typeString  = "string"
+	typeInteger = "integer"
+	typeNumber  = "number"
+	typeBoolean = "boolean"
+)
+
+// Schema is the subset of JSON Schema used to describe plugin definitions.
+type Schema struct {
+	Schema     string             `json:"$schema,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Ref        string             `json:"$ref,omitempty"`
+	Type       typeList           `json:"type,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Defs       map[string]*Schema `json:"$defs,omitempty"`
+
+	// AdditionalProperties is the schema of properties not in Properties. Any value is allowed if
+	// it is nil; use noProperties to forbid them.
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+
+	// never is the boolean schema false, which no value matches.
+	never bool
+}
+
+// noProperties is the schema of the additional properties of objects with a fixed set of fields.
+var noProperties = &Schema{never: true}
+
+// MarshalJSON writes the schema, using the boolean form for the schema which matches nothing.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.never {
+		return []byte("false"), nil
+	}
+	type schema Schema
+	return json.Marshal((*schema)(s))
+}
+
+// typeList is the type keyword, written as a string when there is a single type.
+type typeList []string
+
+func (t typeList) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// required lists the fields which must be set, by the Go type of the object they are in. Fields
+// of the Kubernetes types are left optional; those objects are validated by the API server.
+var required = map[reflect.Type][]string{
+	reflect.TypeOf(manifest.Manifest{}):       {"sonobuoy-config"},
+	reflect.TypeOf(manifest.SonobuoyConfig{}): {"driver", "plugin-name"},
+}
+
+// enums lists the allowed values of fields, by the Go type of the object they are in.
+var enums = map[reflect.Type]map[string][]string{
+	reflect.TypeOf(manifest.SonobuoyConfig{}): {
+		"result-format": {
+			results.ResultFormatJUnit,
+			results.ResultFormatE2E,
+			results.ResultFormatGoJSON,
+			results.ResultFormatRaw,
+			results.ResultFormatManual,
+		},
+	},
+}
+
+// builtinTypes are types which marshal to JSON differently than their Go structure.
+var builtinTypes = map[reflect.Type]*Schema{
+	reflect.TypeOf(resource.Quantity{}):    {Type: typeList{typeString, typeNumber}},
+	reflect.TypeOf(intstr.IntOrString{}):   {Type: typeList{typeString, typeInteger}},
+	reflect.TypeOf(metav1.Time{}):          {Type: typeList{typeString}},
+	reflect.TypeOf(metav1.MicroTime{}):     {Type: typeList{typeString}},
+	reflect.TypeOf(metav1.Duration{}):      {Type: typeList{typeString}},
+	reflect.TypeOf([]byte{}):               {Type: typeList{typeString}},
+	reflect.TypeOf(json.RawMessage{}):      {},
+	reflect.TypeOf(map[string]string(nil)): {Type: typeList{typeObject}, AdditionalProperties: &Schema{Type: typeList{typeString}}},
+}
+
+var (
+	pluginSchema     *Schema
+	pluginSchemaOnce sync.Once
+)
+
+// PluginSchema returns the JSON Schema of plugin definitions. It is generated from the plugin
+// manifest types so that it matches what Sonobuoy reads; unknown fields are not allowed.
+func PluginSchema() *Schema {
+	pluginSchemaOnce.Do(func() {
+		g := &schemaGenerator{defs: map[string]*Schema{}}
+		root := g.structSchema(reflect.TypeOf(manifest.Manifest{}))
+		root.Schema = schemaDraft
+		root.Title = schemaTitle
+		root.Defs = g.defs
+		pluginSchema = root
+	})
+	return pluginSchema
+}
+
+// schemaGenerator builds schemas from Go types, placing each struct in the definitions.
+type schemaGenerator struct {
+	defs map[string]*Schema
+}
+
+// defName is the name of the definition of the struct type, e.g. v1.Container.
+func defName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	if s, ok := builtinTypes[t]; ok {
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return &Schema{Type: typeList{typeString}}
+	case reflect.Bool:
+		return &Schema{Type: typeList{typeBoolean}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: typeList{typeInteger}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: typeList{typeNumber}}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: typeList{typeArray}, Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: typeList{typeObject}, AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		name := defName(t)
+		if _, ok := g.defs[name]; !ok {
+			// Reserve the name first so recursive types refer to the definition being built.
+			g.defs[name] = &Schema{}
+			*g.defs[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/$defs/" + name}
+	default:
+		// Interfaces and anything else may hold any value.
+		return &Schema{}
+	}
+}
+
+// structSchema returns the schema of the struct's JSON fields, including those of embedded structs.
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 typeList{typeObject},
+		Properties:           map[string]*Schema{},
+		Required:             required[t],
+		AdditionalProperties: noProperties,
+	}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *schemaGenerator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && (len(name) == 0 || strings.Contains(tag, ",inline")) && f.Type.Kind() == reflect.Struct {
+			g.addFields(s, f.Type)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if len(name) == 0 {
+			name = f.Name
+		}
+		fs := g.schema(f.Type)
+		if values, ok := enums[t][name]; ok {
+			fs = &Schema{Type: fs.Type, Enum: values}
+		}
+		s.Properties[name] = fs
+	}
+}
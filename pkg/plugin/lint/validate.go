@@ -0,0 +1,219 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// position is the location of a value in the plugin definition.
+type position struct {
+	line, column int
+}
+
+// validator checks YAML nodes against the schema, recording the position of each value so that
+// later checks can refer to them.
+type validator struct {
+	root      *Schema
+	diags     []Diagnostic
+	positions map[string]position
+}
+
+func (v *validator) errorf(n *yaml.Node, path, format string, args ...interface{}) {
+	v.diags = append(v.diags, Diagnostic{
+		Line:     n.Line,
+		Column:   n.Column,
+		Path:     path,
+		Severity: SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) resolve(s *Schema) *Schema {
+	for s != nil && len(s.Ref) > 0 {
+		s = v.root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+	}
+	return s
+}
+
+// nodeType returns the JSON type of the node, or the empty string for null.
+func nodeType(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return typeObject
+	case yaml.SequenceNode:
+		return typeArray
+	}
+	switch n.ShortTag() {
+	case "!!null":
+		return ""
+	case "!!int":
+		return typeInteger
+	case "!!float":
+		return typeNumber
+	case "!!bool":
+		return typeBoolean
+	default:
+		return typeString
+	}
+}
+
+func typeMatches(types typeList, actual string) bool {
+	if len(types) == 0 || len(actual) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == actual || (t == typeNumber && actual == typeInteger) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) validate(n *yaml.Node, s *Schema, path string) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	s = v.resolve(s)
+	v.positions[path] = position{n.Line, n.Column}
+	if s == nil {
+		return
+	}
+
+	actual := nodeType(n)
+	if !typeMatches(s.Type, actual) {
+		v.errorf(n, path, "expected %v but got %v", strings.Join(s.Type, " or "), actual)
+		return
+	}
+	if len(s.Enum) > 0 && actual == typeString && !contains(s.Enum, n.Value) {
+		v.errorf(n, path, "invalid value %q; must be one of %v", n.Value, strings.Join(s.Enum, ", "))
+	}
+
+	switch actual {
+	case typeObject:
+		v.validateObject(n, s, path)
+	case typeArray:
+		for i, item := range n.Content {
+			v.validate(item, s.Items, fmt.Sprintf("%v[%v]", path, i))
+		}
+	}
+}
+
+func (v *validator) validateObject(n *yaml.Node, s *Schema, path string) {
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		fieldPath := joinPath(path, key.Value)
+		if seen[key.Value] {
+			v.errorf(key, fieldPath, "duplicate field %q", key.Value)
+			continue
+		}
+		seen[key.Value] = true
+
+		if fs, ok := s.Properties[key.Value]; ok {
+			v.validate(value, fs, fieldPath)
+			continue
+		}
+		if s.AdditionalProperties == nil {
+			continue
+		}
+		if s.AdditionalProperties.never {
+			msg := fmt.Sprintf("unknown field %q", key.Value)
+			if suggestion := closest(key.Value, s.Properties); len(suggestion) > 0 {
+				msg += fmt.Sprintf("; did you mean %q?", suggestion)
+			}
+			v.errorf(key, fieldPath, "%v", msg)
+			continue
+		}
+		v.validate(value, s.AdditionalProperties, fieldPath)
+	}
+
+	for _, r := range s.Required {
+		if !seen[r] {
+			v.errorf(n, joinPath(path, r), "missing required field %q", r)
+		}
+	}
+}
+
+func joinPath(path, field string) string {
+	if len(path) == 0 {
+		return field
+	}
+	return path + "." + field
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// renamedFields are fields which have been documented under another name in the past.
+var renamedFields = map[string]string{
+	"result-type": "result-format",
+}
+
+// closest returns the property most similar to the unknown field if it is likely to be a typo.
+func closest(field string, properties map[string]*Schema) string {
+	if renamed, ok := renamedFields[field]; ok && properties[renamed] != nil {
+		return renamed
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if strings.EqualFold(name, field) {
+			return name
+		}
+		if d := editDistance(field, name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between the strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin"
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/daemonset"
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/job"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/query"
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
 
 	"github.com/pkg/errors"
@@ -136,6 +137,8 @@ func loadPlugin(def manifest.Manifest, namespace, sonobuoyImage, imagePullPolicy
 		return job.NewPlugin(def, namespace, sonobuoyImage, imagePullPolicy, imagePullSecrets, customAnnotations), nil
 	case "daemonset":
 		return daemonset.NewPlugin(def, namespace, sonobuoyImage, imagePullPolicy, imagePullSecrets, customAnnotations), nil
+	case "query":
+		return query.NewPlugin(def, namespace), nil
 	default:
 		return nil, fmt.Errorf("unknown driver %q for plugin %v",
 			def.SonobuoyConfig.Driver, def.SonobuoyConfig.PluginName)
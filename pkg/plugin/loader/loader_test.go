@@ -26,6 +26,7 @@ import (
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin"
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/daemonset"
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/job"
+	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/driver/query"
 	"github.com/vmware-tanzu/sonobuoy/pkg/plugin/manifest"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -284,3 +285,29 @@ func TestLoadAllPlugins(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadQueryPlugin(t *testing.T) {
+	plugins, err := LoadAllPlugins("sonobuoy", "", "", "", nil, []string{path.Join("testdata", "query")}, nil)
+	if err != nil {
+		t.Fatalf("error loading plugins: %v", err)
+	}
+	if len(plugins) != 1 {
+		t.Fatalf("expected 1 plugin but got %v", len(plugins))
+	}
+	p, ok := plugins[0].(*query.Plugin)
+	if !ok {
+		t.Fatalf("expected a Query plugin but got %T", plugins[0])
+	}
+
+	expectedSpec := manifest.QuerySpec{
+		Resources:     []string{"pods", "configmaps"},
+		Namespaces:    "^kube-.*",
+		LabelSelector: "app=web",
+	}
+	if !reflect.DeepEqual(p.Spec(), expectedSpec) {
+		t.Errorf("expected spec %+v but got %+v", expectedSpec, p.Spec())
+	}
+	if p.GetResultFormat() != "manual" || !reflect.DeepEqual(p.GetResultFiles(), []string{query.ResultsFile}) {
+		t.Errorf("expected the query summary to be post-processed but got format %q and files %v", p.GetResultFormat(), p.GetResultFiles())
+	}
+}
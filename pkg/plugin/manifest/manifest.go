@@ -24,7 +24,7 @@ import (
 
 // SonobuoyConfig is the Sonobuoy metadata that plugins all supply
 type SonobuoyConfig struct {
-	// Driver is the way in which this plugin is run. Either 'Job' or 'Daemonset'.
+	// Driver is the way in which this plugin is run. One of 'Job', 'Daemonset' or 'Query'.
 	Driver string `json:"driver"`
 
 	// Name is the user-facing name for the plugin. It should uniquely identify
@@ -50,6 +50,10 @@ type SonobuoyConfig struct {
 	// to the plugin source would be kept.
 	SourceURL string `json:"source-url,omitempty"`
 
+	// Version is an optional version of the plugin. Plugins installed from a plugin repository
+	// record the version they were installed at so that newer versions can be found.
+	Version string `json:"version,omitempty"`
+
 	Order int `json:"order,omitempty"`
 	objectKind
 }
@@ -75,15 +79,45 @@ type Manifest struct {
 	PodSpec        *PodSpec          `json:"podSpec,omitempty"`
 	ConfigMap      map[string]string `json:"config-map,omitempty"`
 
+	// Query configures plugins using the Query driver, which snapshot the cluster from within the
+	// aggregator instead of running a pod.
+	Query *QuerySpec `json:"query,omitempty"`
+
 	objectKind
 }
 
+// QuerySpec is the set of filters used by a Query plugin. Unset values fall back to those of the
+// Sonobuoy config.
+type QuerySpec struct {
+	// Resources is the list of resources (and keywords such as podlogs) to query.
+	Resources []string `json:"resources,omitempty"`
+
+	// Namespaces is a regular expression selecting the namespaces to query.
+	Namespaces string `json:"namespaces,omitempty"`
+
+	// LabelSelector limits the objects queried to those with matching labels.
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+// DeepCopy makes a deep copy of the QuerySpec.
+func (q *QuerySpec) DeepCopy() *QuerySpec {
+	if q == nil {
+		return nil
+	}
+	q2 := *q
+	if q.Resources != nil {
+		q2.Resources = append([]string{}, q.Resources...)
+	}
+	return &q2
+}
+
 // DeepCopyObject is required by runtime.Object
 func (m *Manifest) DeepCopyObject() kuberuntime.Object {
 	m2 := &Manifest{
 		SonobuoyConfig: *m.SonobuoyConfig.DeepCopy(),
 		Spec:           *m.Spec.DeepCopy(),
 		PodSpec:        m.PodSpec.DeepCopy(),
+		Query:          m.Query.DeepCopy(),
 		objectKind:     objectKind{m.gvk},
 	}
 	if m.ConfigMap != nil {
@@ -0,0 +1,256 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// NoteFile is the filename of the note added to redacted archives, relative to the meta directory.
+	NoteFile = "redacted.json"
+
+	metaDir = "meta"
+)
+
+// Note is added to the meta directory of archives redacted after they were retrieved.
+type Note struct {
+	RedactedAt time.Time              `json:"redactedAt"`
+	Source     string                 `json:"source,omitempty"`
+	Rules      config.RedactionConfig `json:"rules"`
+}
+
+// Archive copies the results from the reader to w as a gzipped tarball, redacting the resources,
+// pod logs and plugin results along the way. The field rules apply to the JSON files under resources,
+// events and hosts. The log patterns apply to the pod logs, the plugin result files which are text
+// and the details of the items in each plugin's post-processed results; binary files such as
+// tarballs are copied unchanged. The records of what was redacted are added
+// to any already in the archive, along with a note naming the source.
+func (r *Redactor) Archive(in *results.Reader, w io.Writer, source string) error {
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+	var existing []Record
+
+	err := in.WalkFiles(func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		p = filepath.ToSlash(p)
+		if p == "." {
+			return nil
+		}
+
+		if info.IsDir() {
+			hdr, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			hdr.Name = p + "/"
+			return errors.WithStack(tw.WriteHeader(hdr))
+		}
+		if !info.Mode().IsRegular() {
+			logrus.Warningf("Skipping %v which is not a regular file", p)
+			return nil
+		}
+
+		var buf bytes.Buffer
+		if err := results.ExtractBytes(p, p, info, &buf); err != nil {
+			return errors.Wrapf(err, "reading %v", p)
+		}
+
+		if p == path.Join(metaDir, RecordsFile) {
+			// Merged with the new records once they are all known.
+			if err := json.Unmarshal(buf.Bytes(), &existing); err != nil {
+				logrus.Warningf("Ignoring invalid %v: %v", p, err)
+			}
+			return nil
+		}
+		if p == path.Join(metaDir, NoteFile) {
+			// Replaced by the note for this redaction.
+			return nil
+		}
+
+		b, err := r.redactFile(p, buf.Bytes())
+		if err != nil {
+			return err
+		}
+		return writeFile(tw, p, info, b)
+	})
+	if err != nil {
+		return errors.Wrap(err, "redacting archive")
+	}
+
+	for _, rec := range existing {
+		r.record(rec)
+	}
+	records, err := json.Marshal(r.Records())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	note, err := json.Marshal(Note{RedactedAt: time.Now().UTC(), Source: source, Rules: r.cfg})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	now := time.Now()
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{RecordsFile, records}, {NoteFile, note}} {
+		hdr := &tar.Header{Name: path.Join(metaDir, f.name), Mode: 0644, Size: int64(len(f.data)), ModTime: now, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(gzw.Close())
+}
+
+// redactFile applies the rules relevant to the file based on its location in the archive.
+func (r *Redactor) redactFile(p string, b []byte) ([]byte, error) {
+	parts := strings.Split(p, "/")
+	switch parts[0] {
+	case "resources", "events", "hosts":
+		if strings.HasSuffix(p, ".json") {
+			return r.JSON(b, p)
+		}
+		return b, nil
+	case "podlogs":
+		return r.text(b, p), nil
+	case strings.TrimSuffix(results.PluginsDir, "/"):
+		if len(parts) == 3 && parts[2] == results.PostProcessedResultsFile {
+			return r.items(b, p)
+		}
+		// The plugin definition is not an output of the plugin.
+		if len(parts) == 3 && parts[2] == "definition.json" {
+			return b, nil
+		}
+		return r.text(b, p), nil
+	}
+	return b, nil
+}
+
+// text applies the log patterns to the file if it is text. Binary files are returned unchanged
+// since replacing matches in them would corrupt them.
+func (r *Redactor) text(b []byte, p string) []byte {
+	if !utf8.Valid(b) {
+		logrus.Debugf("Not redacting %v since it is not text", p)
+		return b
+	}
+	return r.Log(b, p)
+}
+
+// items applies the log patterns to the details (e.g. failure output) of each item in the results tree.
+func (r *Redactor) items(b []byte, p string) ([]byte, error) {
+	if !r.HasLogRules() {
+		return b, nil
+	}
+	item := results.Item{}
+	if err := yaml.Unmarshal(b, &item); err != nil {
+		return nil, errors.Wrapf(err, "decoding %v", p)
+	}
+	if r.item(&item, p) == 0 {
+		return b, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	if err := enc.Encode(item); err != nil {
+		return nil, errors.Wrapf(err, "encoding %v", p)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *Redactor) item(item *results.Item, p string) int {
+	count := 0
+	for k, v := range item.Details {
+		var n int
+		item.Details[k], n = r.logValue(v, p)
+		count += n
+	}
+	for i := range item.Items {
+		count += r.item(&item.Items[i], p)
+	}
+	return count
+}
+
+// logValue applies the log patterns to each string within the value.
+func (r *Redactor) logValue(v interface{}, p string) (interface{}, int) {
+	switch t := v.(type) {
+	case string:
+		out := string(r.Log([]byte(t), p))
+		if out == t {
+			return t, 0
+		}
+		return out, 1
+	case map[string]interface{}:
+		count := 0
+		for k, child := range t {
+			var n int
+			t[k], n = r.logValue(child, p)
+			count += n
+		}
+		return t, count
+	case []interface{}:
+		count := 0
+		for i, child := range t {
+			var n int
+			t[i], n = r.logValue(child, p)
+			count += n
+		}
+		return t, count
+	}
+	return v, 0
+}
+
+func writeFile(tw *tar.Writer, p string, info os.FileInfo, b []byte) error {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	hdr.Name = p
+	hdr.Size = int64(len(b))
+	if err := tw.WriteHeader(hdr); err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = tw.Write(b)
+	return errors.WithStack(err)
+}
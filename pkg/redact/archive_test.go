@@ -0,0 +1,143 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware-tanzu/sonobuoy/pkg/client/results"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+)
+
+func TestArchive(t *testing.T) {
+	files := map[string]string{
+		"resources/ns/x/core_v1_configmaps.json": `{"apiVersion":"v1","kind":"ConfigMapList","items":[{"metadata":{"name":"c","namespace":"x"},"data":{"a":"b"}}]}`,
+		"podlogs/x/p/logs/c.txt":                 "using token=abc123\n",
+		"plugins/e2e/sonobuoy_results.yaml": `name: e2e
+status: failed
+items:
+- name: test
+  status: failed
+  details:
+    failure: request failed with token=abc123
+`,
+		"plugins/e2e/definition.json":     `{"token=abc123":""}`,
+		"plugins/e2e/results/out.txt":     "token=abc123",
+		"plugins/e2e/results/out.tar.gz":  "\x1f\x8b\x08\x00token=abc123\xff",
+		"meta/redactions.json":            `[{"file":"podlogs/x/p/logs/c.txt","rule":"token=(\\S+)","count":2}]`,
+		"meta/run.log":                    "token=abc123",
+		"resources/cluster/unrelated.txt": "token=abc123",
+	}
+	root := t.TempDir()
+	for name, data := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := New(&config.RedactionConfig{
+		Fields:      []config.FieldRedaction{{Kind: "ConfigMap", Paths: []string{".data"}}},
+		LogPatterns: []string{`token=(\S+)`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := r.Archive(results.NewReaderFromDir(root), &buf, "archive.tar.gz"); err != nil {
+		t.Fatal(err)
+	}
+	got := readTarball(t, &buf)
+
+	expect := map[string]string{
+		"resources/ns/x/core_v1_configmaps.json": `{"apiVersion":"v1","items":[{"data":{"a":"[REDACTED]"},"metadata":{"name":"c","namespace":"x"}}],"kind":"ConfigMapList"}`,
+		"podlogs/x/p/logs/c.txt":                 "using token=[REDACTED]\n",
+		"plugins/e2e/definition.json":            `{"token=abc123":""}`,
+		"plugins/e2e/results/out.txt":            "token=[REDACTED]",
+		"plugins/e2e/results/out.tar.gz":         "\x1f\x8b\x08\x00token=abc123\xff",
+		"meta/run.log":                           "token=abc123",
+		"resources/cluster/unrelated.txt":        "token=abc123",
+	}
+	for name, data := range expect {
+		if got[name] != data {
+			t.Errorf("Expected %v to be %q but got %q", name, data, got[name])
+		}
+	}
+	if !strings.Contains(got["plugins/e2e/sonobuoy_results.yaml"], "failure: request failed with token=[REDACTED]") {
+		t.Errorf("Expected item details to be redacted but got %q", got["plugins/e2e/sonobuoy_results.yaml"])
+	}
+
+	var records []Record
+	if err := json.Unmarshal([]byte(got["meta/"+RecordsFile]), &records); err != nil {
+		t.Fatal(err)
+	}
+	expectRecords := []Record{
+		{File: "plugins/e2e/results/out.txt", Rule: `token=(\S+)`, Count: 1},
+		{File: "plugins/e2e/sonobuoy_results.yaml", Rule: `token=(\S+)`, Count: 1},
+		{File: "podlogs/x/p/logs/c.txt", Rule: `token=(\S+)`, Count: 3},
+		{File: "resources/ns/x/core_v1_configmaps.json", Object: "x/c", Rule: ".data", Count: 1},
+	}
+	if !reflect.DeepEqual(records, expectRecords) {
+		t.Errorf("Expected records %+v but got %+v", expectRecords, records)
+	}
+
+	note := Note{}
+	if err := json.Unmarshal([]byte(got["meta/"+NoteFile]), &note); err != nil {
+		t.Fatal(err)
+	}
+	if note.Source != "archive.tar.gz" || len(note.Rules.LogPatterns) != 1 || note.RedactedAt.IsZero() {
+		t.Errorf("Unexpected note %+v", note)
+	}
+}
+
+func readTarball(t *testing.T, r io.Reader) map[string]string {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gzr)
+	out := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[hdr.Name] = string(b)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type segmentType int
+
+const (
+	segmentField segmentType = iota
+	segmentIndex
+	segmentWildcard
+)
+
+// segment is a single step of a path: a map key, a list index or a wildcard matching all values.
+type segment struct {
+	typ   segmentType
+	name  string
+	index int
+}
+
+// parsePath parses the subset of JSONPath used for field rules: dot separated keys, quoted keys in
+// brackets, list indexes and wildcards. The surrounding braces and leading $ are optional.
+func parsePath(p string) ([]segment, error) {
+	s := strings.TrimSpace(p)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	s = strings.TrimPrefix(s, "$")
+
+	var out []segment
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			i++
+			name := readName(s[i:])
+			if len(name) == 0 {
+				return nil, errors.Errorf("expected a key at position %v", i)
+			}
+			i += len(name)
+			out = append(out, nameSegment(name))
+		case '[':
+			end, seg, err := readBracket(s, i)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, seg)
+			i = end
+		default:
+			if i != 0 {
+				return nil, errors.Errorf("unexpected %q at position %v", s[i], i)
+			}
+			name := readName(s)
+			i += len(name)
+			out = append(out, nameSegment(name))
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("path is empty")
+	}
+	return out, nil
+}
+
+func nameSegment(name string) segment {
+	if name == "*" {
+		return segment{typ: segmentWildcard}
+	}
+	return segment{typ: segmentField, name: name}
+}
+
+func readName(s string) string {
+	if i := strings.IndexAny(s, ".["); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// readBracket parses the bracket starting at s[start], returning the position after it.
+func readBracket(s string, start int) (int, segment, error) {
+	i := start + 1
+	if i < len(s) && (s[i] == '\'' || s[i] == '"') {
+		quote := s[i]
+		end := strings.IndexByte(s[i+1:], quote)
+		if end < 0 || i+end+2 >= len(s) || s[i+end+2] != ']' {
+			return 0, segment{}, errors.Errorf("unterminated quoted key at position %v", start)
+		}
+		return i + end + 3, segment{typ: segmentField, name: s[i+1 : i+1+end]}, nil
+	}
+
+	end := strings.IndexByte(s[i:], ']')
+	if end < 0 {
+		return 0, segment{}, errors.Errorf("unterminated bracket at position %v", start)
+	}
+	content := s[i : i+end]
+	if content == "*" {
+		return i + end + 1, segment{typ: segmentWildcard}, nil
+	}
+	index, err := strconv.Atoi(content)
+	if err != nil || index < 0 {
+		return 0, segment{}, errors.Errorf("invalid index %q at position %v", content, start)
+	}
+	return i + end + 1, segment{typ: segmentIndex, index: index}, nil
+}
+
+// redactPath masks the values found at the path within the node, returning how many were masked.
+func redactPath(node interface{}, segments []segment) int {
+	if len(segments) == 0 {
+		return 0
+	}
+	seg, rest := segments[0], segments[1:]
+
+	visit := func(v interface{}, set func(interface{})) int {
+		if len(rest) > 0 {
+			return redactPath(v, rest)
+		}
+		masked, n := mask(v)
+		set(masked)
+		return n
+	}
+
+	count := 0
+	switch n := node.(type) {
+	case map[string]interface{}:
+		switch seg.typ {
+		case segmentField:
+			if v, ok := n[seg.name]; ok {
+				count += visit(v, func(m interface{}) { n[seg.name] = m })
+			}
+		case segmentWildcard:
+			for k, v := range n {
+				count += visit(v, func(m interface{}) { n[k] = m })
+			}
+		}
+	case []interface{}:
+		switch seg.typ {
+		case segmentIndex:
+			if seg.index < len(n) {
+				count += visit(n[seg.index], func(m interface{}) { n[seg.index] = m })
+			}
+		case segmentWildcard:
+			for i := range n {
+				count += visit(n[i], func(m interface{}) { n[i] = m })
+			}
+		}
+	}
+	return count
+}
+
+// mask returns the value with every non-empty leaf replaced by Mask, along with the number replaced.
+func mask(v interface{}) (interface{}, int) {
+	switch t := v.(type) {
+	case nil:
+		return nil, 0
+	case string:
+		if len(t) == 0 {
+			return t, 0
+		}
+		return Mask, 1
+	case map[string]interface{}:
+		count := 0
+		for k, child := range t {
+			var n int
+			t[k], n = mask(child)
+			count += n
+		}
+		return t, count
+	case []interface{}:
+		count := 0
+		for i, child := range t {
+			var n int
+			t[i], n = mask(child)
+			count += n
+		}
+		return t, count
+	default:
+		return Mask, 1
+	}
+}
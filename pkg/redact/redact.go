@@ -0,0 +1,319 @@
+/*
+Copyright the Sonobuoy contributors 2026
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact removes sensitive data from Kubernetes objects and logs according to configurable
+// rules, keeping a record of what was removed.
+package redact
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/sonobuoy/pkg/config"
+)
+
+const (
+	// Mask replaces the redacted values.
+	Mask = "[REDACTED]"
+
+	// RecordsFile is the filename of the redaction records, relative to the meta directory.
+	RecordsFile = "redactions.json"
+
+	// coreGroup is used in rules to match the core API group, which is otherwise the empty string.
+	coreGroup = "core"
+)
+
+// Record counts the values redacted from a file by a single rule. The values themselves are never recorded.
+type Record struct {
+	File string `json:"file"`
+
+	// Object is the namespace/name of the object the fields were redacted from. Unset for logs.
+	Object string `json:"object,omitempty"`
+
+	// Rule is the path or log pattern which matched.
+	Rule  string `json:"rule"`
+	Count int    `json:"count"`
+}
+
+type fieldRule struct {
+	group, version, kind string
+	path                 string
+	segments             []segment
+}
+
+type logRule struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// Redactor applies the redaction rules and records what was redacted. A nil Redactor redacts nothing.
+// It is safe for concurrent use.
+type Redactor struct {
+	cfg    config.RedactionConfig
+	fields []fieldRule
+	logs   []logRule
+
+	mu      sync.Mutex
+	records []Record
+	index   map[recordKey]int
+}
+
+// recordKey identifies records which are merged by adding their counts.
+type recordKey struct {
+	file, object, rule string
+}
+
+// New compiles the rules into a Redactor. If there are no rules, nil is returned.
+func New(cfg *config.RedactionConfig) (*Redactor, error) {
+	if cfg == nil || (len(cfg.Fields) == 0 && len(cfg.LogPatterns) == 0) {
+		return nil, nil
+	}
+
+	r := &Redactor{cfg: *cfg, index: map[recordKey]int{}}
+	for _, f := range cfg.Fields {
+		if len(f.Paths) == 0 {
+			return nil, errors.Errorf("field redaction for kind %q has no paths", f.Kind)
+		}
+		for _, p := range f.Paths {
+			segments, err := parsePath(p)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid redaction path %q", p)
+			}
+			r.fields = append(r.fields, fieldRule{group: f.Group, version: f.Version, kind: f.Kind, path: p, segments: segments})
+		}
+	}
+	for _, p := range cfg.LogPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid redaction log pattern %q", p)
+		}
+		r.logs = append(r.logs, logRule{pattern: p, re: re})
+	}
+	return r, nil
+}
+
+// HasFieldRules returns true if objects may be redacted.
+func (r *Redactor) HasFieldRules() bool {
+	return r != nil && len(r.fields) > 0
+}
+
+// HasLogRules returns true if logs may be redacted.
+func (r *Redactor) HasLogRules() bool {
+	return r != nil && len(r.logs) > 0
+}
+
+// Object redacts the object in place. The object is in the form produced by unmarshalling JSON; if it
+// is a list, each of its items is redacted instead. Items without their own apiVersion and kind (e.g.
+// in typed lists) are matched using those of the list. It returns the number of values redacted.
+func (r *Redactor) Object(obj map[string]interface{}, file string) int {
+	if !r.HasFieldRules() {
+		return 0
+	}
+
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	items, isList := obj["items"].([]interface{})
+	if !isList {
+		return r.redactObject(obj, apiVersion, kind, file)
+	}
+
+	total := 0
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		itemVersion, _ := m["apiVersion"].(string)
+		if len(itemVersion) == 0 {
+			itemVersion = apiVersion
+		}
+		itemKind, _ := m["kind"].(string)
+		if len(itemKind) == 0 {
+			itemKind = strings.TrimSuffix(kind, "List")
+		}
+		total += r.redactObject(m, itemVersion, itemKind, file)
+	}
+	return total
+}
+
+func (r *Redactor) redactObject(obj map[string]interface{}, apiVersion, kind, file string) int {
+	group, version := splitAPIVersion(apiVersion)
+	total := 0
+	for _, rule := range r.fields {
+		if !rule.matches(group, version, kind) {
+			continue
+		}
+		if n := redactPath(obj, rule.segments); n > 0 {
+			total += n
+			r.record(Record{File: file, Object: objectName(obj), Rule: rule.path, Count: n})
+		}
+	}
+	return total
+}
+
+// JSON applies the field rules to a serialized object or list. Values which are not JSON objects are
+// returned unchanged.
+func (r *Redactor) JSON(b []byte, file string) ([]byte, error) {
+	if !r.HasFieldRules() {
+		return b, nil
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		if _, ok := err.(*json.UnmarshalTypeError); ok {
+			return b, nil
+		}
+		return nil, errors.Wrapf(err, "decoding %v for redaction", file)
+	}
+	if r.Object(obj, file) == 0 {
+		return b, nil
+	}
+	out, err := json.Marshal(obj)
+	return out, errors.WithStack(err)
+}
+
+// Log returns the log with all the matches of the log patterns masked.
+func (r *Redactor) Log(b []byte, file string) []byte {
+	if !r.HasLogRules() {
+		return b
+	}
+	for _, rule := range r.logs {
+		var n int
+		b, n = maskMatches(rule.re, b)
+		if n > 0 {
+			r.record(Record{File: file, Rule: rule.pattern, Count: n})
+		}
+	}
+	return b
+}
+
+// maskMatches masks each match of the expression, or only its capture groups if it has any.
+func maskMatches(re *regexp.Regexp, b []byte) ([]byte, int) {
+	matches := re.FindAllSubmatchIndex(b, -1)
+	if len(matches) == 0 {
+		return b, 0
+	}
+
+	var out []byte
+	last, count := 0, 0
+	for _, m := range matches {
+		spans := [][2]int{{m[0], m[1]}}
+		if re.NumSubexp() > 0 {
+			spans = spans[:0]
+			for i := 2; i+1 < len(m); i += 2 {
+				// Skip unmatched optional groups and groups nested within the previous one.
+				if m[i] < 0 || (len(spans) > 0 && m[i] < spans[len(spans)-1][1]) {
+					continue
+				}
+				spans = append(spans, [2]int{m[i], m[i+1]})
+			}
+		}
+		for _, s := range spans {
+			if s[0] == s[1] {
+				continue
+			}
+			out = append(out, b[last:s[0]]...)
+			out = append(out, Mask...)
+			last = s[1]
+			count++
+		}
+	}
+	out = append(out, b[last:]...)
+	return out, count
+}
+
+func (r *Redactor) record(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := recordKey{file: rec.File, object: rec.Object, rule: rec.Rule}
+	if i, ok := r.index[key]; ok {
+		r.records[i].Count += rec.Count
+		return
+	}
+	r.index[key] = len(r.records)
+	r.records = append(r.records, rec)
+}
+
+// Records returns what has been redacted, sorted by file.
+func (r *Redactor) Records() []Record {
+	if r == nil {
+		return []Record{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := append([]Record{}, r.records...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].File != out[j].File {
+			return out[i].File < out[j].File
+		}
+		return out[i].Object < out[j].Object
+	})
+	return out
+}
+
+// DumpRecords writes the records to the given file. If root is set, the files in the records are made
+// relative to it.
+func (r *Redactor) DumpRecords(file string, root string) error {
+	records := r.Records()
+	if len(root) > 0 {
+		for i := range records {
+			if rel, err := filepath.Rel(root, records[i].File); err == nil {
+				records[i].File = filepath.ToSlash(rel)
+			}
+		}
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(file, data, 0644))
+}
+
+func (f fieldRule) matches(group, version, kind string) bool {
+	switch {
+	case len(f.group) > 0 && f.group != group && !(f.group == coreGroup && group == ""):
+		return false
+	case len(f.version) > 0 && f.version != version:
+		return false
+	case len(f.kind) > 0 && f.kind != kind:
+		return false
+	}
+	return true
+}
+
+func splitAPIVersion(apiVersion string) (group, version string) {
+	if i := strings.LastIndex(apiVersion, "/"); i >= 0 {
+		return apiVersion[:i], apiVersion[i+1:]
+	}
+	return "", apiVersion
+}
+
+func objectName(obj map[string]interface{}) string {
+	meta, _ := obj["metadata"].(map[string]interface{})
+	name, _ := meta["name"].(string)
+	if ns, _ := meta["namespace"].(string); len(ns) > 0 {
+		return ns + "/" + name
+	}
+	return name
+}